@@ -5,27 +5,27 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/sessions"
-	"github.com/jeffscottbrown/gogoogle/secrets"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/metrics"
 	"github.com/jeffscottbrown/satchel/model"
 	"github.com/jeffscottbrown/satchel/repository"
-	"github.com/jeffscottbrown/satchel/utils"
-	"github.com/joho/godotenv"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
 	"github.com/markbates/goth/providers/google"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
-type oauthConfig struct {
-	clientId     string
-	clientSecret string
-	callbackUrl  string
-}
+var tracer = otel.Tracer("github.com/jeffscottbrown/satchel/auth")
 
 func login(c *gin.Context) {
 	req := c.Request
@@ -40,113 +40,194 @@ func login(c *gin.Context) {
 func logout(c *gin.Context) {
 	req := c.Request
 	res := c.Writer
+	if session, ok := currentSession(req); ok {
+		if err := repository.DeleteSession(req.Context(), session.EmployeeEmail, session.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logging.FromContext(req.Context()).ErrorContext(req.Context(), "Error ending session", "error", err)
+		}
+	}
 	gothic.Logout(res, req)
-	slog.Info("User logged out")
+	logging.FromContext(req.Context()).InfoContext(req.Context(), "User logged out")
 	http.Redirect(res, req, "/", http.StatusTemporaryRedirect)
 }
 
 func authCallback(c *gin.Context) {
-	req := c.Request
-	res := c.Writer
-	user, err := gothic.CompleteUserAuth(res, req)
+	ctx, span := tracer.Start(c.Request.Context(), "auth.callback",
+		trace.WithAttributes(attribute.String("auth.provider", c.Param("provider"))))
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+	log := logging.FromContext(ctx)
+
+	user, err := gothic.CompleteUserAuth(c.Writer, c.Request)
 	if err != nil {
-		slog.Error("Error authenticating user", "error", err)
+		log.ErrorContext(ctx, "Error authenticating user", "error", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "authentication failed")
+		metrics.RecordLogin(metrics.LoginFailure)
 		c.AbortWithError(http.StatusUnauthorized, err)
 		return
 	}
 
+	completeLogin(c, user)
+}
+
+// completeLogin starts a session for user, who has just been
+// authenticated by a provider, creating their profile on their first
+// visit.
+func completeLogin(c *gin.Context, user goth.User) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	log := logging.FromContext(ctx)
+	req := c.Request
+	res := c.Writer
+
 	if !isAllowedDomain(user.Email) {
+		span.SetStatus(codes.Error, "domain not allowed")
+		metrics.RecordLogin(metrics.LoginForbidden)
+		gothic.Logout(res, req)
+		c.Redirect(http.StatusFound, "/forbidden")
+		return
+	}
+
+	// The span names the employee by ID rather than email so that traces
+	// do not carry personal data.
+	existing, err := repository.GetEmployeeByEmail(ctx, user.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.ErrorContext(ctx, "Error querying employee", "error", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "employee lookup failed")
+		metrics.RecordLogin(metrics.LoginFailure)
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err == nil {
+		span.SetAttributes(attribute.Int("enduser.id", int(existing.ID)))
+	}
+	if err == nil && !existing.IsActive() {
+		log.InfoContext(ctx, "Deactivated employee attempted to log in", "email", user.Email)
+		span.SetStatus(codes.Error, "employee deactivated")
+		metrics.RecordLogin(metrics.LoginForbidden)
 		gothic.Logout(res, req)
 		c.Redirect(http.StatusFound, "/forbidden")
 		return
 	}
-	slog.Info("User authenticated", "email", user.Email)
 
-	gothic.StoreInSession("authenticatedUser", user.Email, req, res)
+	log.InfoContext(ctx, "User authenticated", "email", user.Email)
+	metrics.RecordLogin(metrics.LoginSuccess)
 
-	_, err = repository.GetEmployeeByEmail(user.Email)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			slog.Info("Profile not found in database - new profile being created", "email", user.Email)
-			newEmployee := &model.Employee{
+	if err := startSession(c, user.Email); err != nil {
+		log.ErrorContext(ctx, "Error starting session", "error", err)
+		span.RecordError(err)
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	destination := "/"
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.InfoContext(ctx, "Profile not found in database - new profile being created", "email", user.Email)
+		newEmployee := &model.Employee{
+			Name:      user.Name,
+			Email:     user.Email,
+			ImageName: user.AvatarURL,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			IdentityProfile: model.IdentityProfile{
 				Name:      user.Name,
-				Email:     user.Email,
-				ImageName: user.AvatarURL,
 				FirstName: user.FirstName,
 				LastName:  user.LastName,
-			}
-			newEmployee.AddReflection("Temporary Thing #1", "1")
-			newEmployee.AddReflection("Temporary Thing #2", "2")
-			newEmployee.AddReflection("Temporary Thing #3", "3")
-			newEmployee.AddReflection("Temporary Thing #4", "4")
-			if err := repository.SaveEmployee(newEmployee); err != nil {
-				slog.Error("Error adding employee", "error", err)
-				c.AbortWithError(http.StatusInternalServerError, err)
-				return
-			}
-			slog.Info("New employee added", "email", user.Email)
-
-		} else {
-			slog.Error("Error querying employee", "error", err)
+			},
+		}
+		startOnboarding(ctx, newEmployee)
+		if err := repository.SaveEmployee(ctx, newEmployee); err != nil {
+			log.ErrorContext(ctx, "Error adding employee", "error", err)
+			c.AbortWithError(http.StatusInternalServerError, err)
 			return
 		}
+		span.SetAttributes(attribute.Int("enduser.id", int(newEmployee.ID)))
+		log.InfoContext(ctx, "New employee added", "email", user.Email)
+		refreshAvatar(ctx, newEmployee)
+		destination = OnboardingPath
+	} else {
+		// Most logins change nothing, and those are not saved.
+		remembered := existing.IdentityProfile
+		changed := syncProfile(existing, user)
+		if len(changed) > 0 {
+			log.InfoContext(ctx, "Profile refreshed from identity provider", "email", user.Email, "fields", changed)
+		}
+		// Profiles provisioned before the employee first logged in are
+		// onboarded now, as though login had created them.
+		if existing.OnboardingStartedAt == nil {
+			log.InfoContext(ctx, "First login to a provisioned profile - onboarding started", "email", user.Email)
+			startOnboarding(ctx, existing)
+			destination = OnboardingPath
+		}
+		if len(changed) > 0 || existing.IdentityProfile != remembered || destination == OnboardingPath {
+			saveProfile(ctx, existing)
+		}
 	}
 
-	http.Redirect(res, req, "/", http.StatusTemporaryRedirect)
+	// An employee who was reactivated may still be cached as inactive.
+	employeeStatus.forget(user.Email)
+	http.Redirect(res, req, destination, http.StatusTemporaryRedirect)
+}
+
+// IdentifyUser adds the email of the authenticated user, if there is
+// one, to the request logger so that every log line written while
+// handling the request, including the access log, names the user and,
+// during impersonation, the admin acting as them.
+func IdentifyUser(c *gin.Context) {
+	if user, err := AuthenticatedUser(c.Request); err == nil {
+		logging.With(c.Request.Context(), slog.String("user", user))
+	}
+	if impersonator := Impersonator(c.Request); impersonator != "" {
+		logging.With(c.Request.Context(), slog.String("impersonator", impersonator))
+	}
+	c.Next()
+}
+
+// AuthenticatedUser returns the email of the user making the request,
+// whether they authenticated with a session or an API token. While an
+// admin is impersonating an employee it is the employee's email; see
+// Impersonator for the admin's.
+func AuthenticatedUser(req *http.Request) (string, error) {
+	if email, ok := req.Context().Value(tokenUserKey).(string); ok {
+		return email, nil
+	}
+	session, ok := currentSession(req)
+	if !ok {
+		return "", errNotAuthenticated
+	}
+	if impersonation, ok := currentImpersonation(req); ok {
+		return impersonation.Email, nil
+	}
+	return session.EmployeeEmail, nil
 }
 
 func IsAuthenticated(req *http.Request) bool {
-	_, err := gothic.GetFromSession("authenticatedUser", req)
+	_, err := AuthenticatedUser(req)
 	return err == nil
 }
 
-func init() {
-	err := godotenv.Load()
-	if err != nil {
-		slog.Warn("Problem loading .env file", "error", err)
+// Configure sets up the session store, the allowed domains, session
+// timeouts and the authentication providers from the application
+// configuration.
+func Configure(settings config.Auth) {
+	store := sessions.NewCookieStore([]byte(settings.SessionSecret))
+	if settings.SessionAbsoluteTimeout > 0 {
+		store.MaxAge(int(settings.SessionAbsoluteTimeout / time.Second))
 	}
-
-	gothic.Store = sessions.NewCookieStore([]byte("dev-secret-don't-use-in-prod"))
+	gothic.Store = store
+	allowedDomains = settings.AllowedDomains
+	sessionIdleTimeout = settings.SessionIdleTimeout
+	sessionAbsoluteTimeout = settings.SessionAbsoluteTimeout
+	employeeStatus = newStatusCache(settings.StatusCacheTTL)
 
 	slog.Debug("Configuring authentication providers")
 
-	googleConfig := createOauthConfig("google")
-
 	goth.UseProviders(
-		google.New(googleConfig.clientId, googleConfig.clientSecret, googleConfig.callbackUrl, "profile", "email"),
+		google.New(settings.Google.ClientID, settings.Google.ClientSecret, settings.Google.CallbackURL, "profile", "email"),
 	)
 }
 
-func createOauthConfig(provider string) *oauthConfig {
-	providerUpperCase := strings.ToUpper(provider)
-	providerLowerCase := strings.ToLower(provider)
-
-	callbackUrlVarName := providerUpperCase + "_OAUTH_CALLBACK_URL"
-	idVarName := providerUpperCase + "_OAUTH_CLIENT_ID"
-	secretVarName := providerUpperCase + "_OAUTH_CLIENT_SECRET"
-
-	callbackUrl := retrieveSecretValue(callbackUrlVarName)
-	if callbackUrl == "" {
-		callbackUrl = "http://localhost:8080/auth/" + providerLowerCase + "/callback"
-	}
-
-	return &oauthConfig{
-		callbackUrl:  callbackUrl,
-		clientId:     utils.RetrieveSecretValue(idVarName),
-		clientSecret: retrieveSecretValue(secretVarName),
-	}
-}
-
-func retrieveSecretValue(secretName string) string {
-	clientSecret, err := secrets.RetrieveSecret(secretName)
-	if err != nil {
-		slog.Warn("Falling back to OS environment variable", slog.String("secretName", secretName), slog.Any("error", err))
-		clientSecret = os.Getenv(secretName)
-	}
-
-	return clientSecret
-}
-
 func ConfigureAuthorizationHandlers(router *gin.Engine) {
 	providerAwareGroup := router.Group("/auth/:provider")
 
@@ -155,6 +236,9 @@ func ConfigureAuthorizationHandlers(router *gin.Engine) {
 	providerAwareGroup.GET("/login", login)
 
 	router.GET("/auth/logout", logout)
+	router.POST(impersonationStartPath, AuthRequired, SessionRequired, AdminRequired, startImpersonation)
+	router.POST(impersonationStopPath, AuthRequired, stopImpersonation)
+	configureDevelopmentLogin(router)
 }
 
 // gothic tries a number of techniques to retrieve the provider
@@ -183,7 +267,31 @@ func AuthRequired(c *gin.Context) {
 
 }
 
-var allowedDomains = []string{"objectcomputing.com"}
+// AdminRequired rejects requests from users who are not active admins.
+// It is used after AuthRequired.
+func AdminRequired(c *gin.Context) {
+	if !IsAdmin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	c.Next()
+}
+
+// IsAdmin reports whether the authenticated user, if there is one, is an
+// active employee with admin rights.
+func IsAdmin(req *http.Request) bool {
+	email, err := AuthenticatedUser(req)
+	if err != nil {
+		return false
+	}
+	employee, err := repository.GetEmployeeByEmail(req.Context(), email)
+	if err != nil {
+		return false
+	}
+	return employee.Admin && employee.IsActive()
+}
+
+var allowedDomains = config.Default().Auth.AllowedDomains
 
 func isAllowedDomain(email string) bool {
 	parts := strings.Split(email, "@")
@@ -0,0 +1,44 @@
+//go:build !production
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/markbates/goth/gothic"
+)
+
+// AuthenticateRequestForTest starts a session for email, as if they had
+// logged in, adds its cookie to req and returns req. The session is
+// ended when the test completes. Configure must have been called so
+// that the session store can sign the cookie.
+func AuthenticateRequestForTest(t testing.TB, req *http.Request, email string) *http.Request {
+	t.Helper()
+	key, err := randomSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	session := &model.Session{KeyHash: hashToken(key), EmployeeEmail: email, UserAgent: "test", CreatedAt: now, LastSeenAt: now}
+	if err := repository.SaveSession(context.Background(), session); err != nil {
+		t.Fatalf("starting a session for %s: %v", email, err)
+	}
+	t.Cleanup(func() {
+		repository.DeleteSession(context.Background(), email, session.ID)
+	})
+
+	recorder := httptest.NewRecorder()
+	if err := gothic.StoreInSession(sessionCookieKey, key, httptest.NewRequest(http.MethodGet, "/", nil), recorder); err != nil {
+		t.Fatalf("storing the session of %s in a cookie: %v", email, err)
+	}
+	for _, cookie := range recorder.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
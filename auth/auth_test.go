@@ -21,3 +21,24 @@ func TestIsDomainAllowed(t *testing.T) {
 		})
 	}
 }
+
+func TestOnboardingExempt(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/onboarding", true},
+		{"/onboarding/name", true},
+		{"/onboardingfoo", false},
+		{"/static/app.css", true},
+		{"/staticky", false},
+		{"/metrics", true},
+		{"/metrics-export", false},
+		{"/", false},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, onboardingExempt(test.path))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !production
+
+package auth
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/markbates/goth"
+)
+
+// DevelopmentLoginEnabled reports whether the development login, which
+// lets anyone log in as any allowed email without a password, is
+// compiled in. Production builds, made with the production build tag,
+// leave it out.
+const DevelopmentLoginEnabled = true
+
+// configureDevelopmentLogin registers POST /auth/dev/login, which logs
+// in as the email posted in the "email" form value so that the app can
+// be run locally without Google OAuth credentials.
+func configureDevelopmentLogin(router *gin.Engine) {
+	slog.Warn("Development login is enabled; build with -tags production to remove it")
+	router.POST("/auth/dev/login", developmentLogin)
+}
+
+func developmentLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	email := strings.ToLower(strings.TrimSpace(c.PostForm("email")))
+	if email == "" {
+		c.String(http.StatusBadRequest, "Email cannot be empty")
+		return
+	}
+	logging.FromContext(ctx).WarnContext(ctx, "Development login used", "email", email)
+
+	user := goth.User{Provider: "dev", Email: email, UserID: email}
+	user.FirstName, user.LastName = namesFromEmail(email)
+	user.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	completeLogin(c, user)
+}
+
+// namesFromEmail guesses a name from addresses such as
+// jane.doe@example.com, so that new development profiles look real.
+func namesFromEmail(email string) (string, string) {
+	local, _, _ := strings.Cut(email, "@")
+	first, last, _ := strings.Cut(local, ".")
+	return titleCase(first), titleCase(last)
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return ""
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
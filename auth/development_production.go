@@ -0,0 +1,11 @@
+//go:build production
+
+package auth
+
+import "github.com/gin-gonic/gin"
+
+// DevelopmentLoginEnabled is false in production builds, which do not
+// include the development login.
+const DevelopmentLoginEnabled = false
+
+func configureDevelopmentLogin(router *gin.Engine) {}
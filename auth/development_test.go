@@ -0,0 +1,28 @@
+//go:build !production
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamesFromEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		first string
+		last  string
+	}{
+		{"jane.doe@objectcomputing.com", "Jane", "Doe"},
+		{"jane@objectcomputing.com", "Jane", ""},
+		{"@objectcomputing.com", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.email, func(t *testing.T) {
+			first, last := namesFromEmail(test.email)
+			assert.Equal(t, test.first, first)
+			assert.Equal(t, test.last, last)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/markbates/goth/gothic"
+	"gorm.io/gorm"
+)
+
+const (
+	impersonationStartPath = "/auth/impersonation/start"
+	impersonationStopPath  = "/auth/impersonation/stop"
+	impersonationKey       = "impersonation"
+)
+
+// impersonation is stored in the session cookie of an admin who is
+// viewing the app as another employee. The session itself still belongs
+// to the admin, so the admin's identity is never lost.
+type impersonation struct {
+	Email        string `json:"email"`
+	AllowChanges bool   `json:"allowChanges"`
+}
+
+func currentImpersonation(req *http.Request) (impersonation, bool) {
+	if ended, _ := req.Context().Value(impersonationEndedKey).(bool); ended {
+		return impersonation{}, false
+	}
+	value, err := gothic.GetFromSession(impersonationKey, req)
+	if err != nil || value == "" {
+		return impersonation{}, false
+	}
+	var current impersonation
+	if err := json.Unmarshal([]byte(value), &current); err != nil || current.Email == "" {
+		return impersonation{}, false
+	}
+	return current, true
+}
+
+// endRevokedImpersonation clears the impersonation from the session
+// cookie when the admin who started it is no longer an active admin, and
+// reports whether it did so that the request is served as the former
+// admin themselves.
+func endRevokedImpersonation(c *gin.Context, session *model.Session) (bool, error) {
+	current, ok := currentImpersonation(c.Request)
+	if !ok {
+		return false, nil
+	}
+	admin, err := employeeStatus.isAdmin(c.Request.Context(), session.EmployeeEmail)
+	if err != nil || admin {
+		return false, err
+	}
+	ctx := c.Request.Context()
+	logging.FromContext(ctx).WarnContext(ctx, "Impersonation ended because the impersonator is no longer an admin",
+		slog.String("admin", session.EmployeeEmail), slog.String("impersonated", current.Email))
+	if err := gothic.StoreInSession(impersonationKey, "", c.Request, c.Writer); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Impersonator returns the email of the admin impersonating the
+// authenticated user, or an empty string when nobody is.
+func Impersonator(req *http.Request) string {
+	if UsingAPIToken(req) {
+		return ""
+	}
+	session, ok := currentSession(req)
+	if !ok {
+		return ""
+	}
+	if _, ok := currentImpersonation(req); !ok {
+		return ""
+	}
+	return session.EmployeeEmail
+}
+
+// ImpersonationAllowsChanges reports whether the admin impersonating the
+// authenticated user chose to let their requests change anything.
+func ImpersonationAllowsChanges(req *http.Request) bool {
+	current, ok := currentImpersonation(req)
+	return ok && current.AllowChanges
+}
+
+// startImpersonation lets an admin view the app as the employee named by
+// the "email" form value. Requests which change anything are refused
+// unless the "allow-changes" form value is set.
+func startImpersonation(c *gin.Context) {
+	ctx := c.Request.Context()
+	admin, _ := AuthenticatedUser(c.Request)
+	email := strings.ToLower(strings.TrimSpace(c.PostForm("email")))
+	if email == admin {
+		c.String(http.StatusBadRequest, "You cannot impersonate yourself")
+		return
+	}
+	employee, err := repository.GetEmployeeByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "No employee with email %s", email)
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+
+	value, _ := json.Marshal(impersonation{Email: employee.Email, AllowChanges: c.PostForm("allow-changes") != ""})
+	if err := gothic.StoreInSession(impersonationKey, string(value), c.Request, c.Writer); err != nil {
+		c.String(http.StatusInternalServerError, "Error starting impersonation: %v", err)
+		return
+	}
+	logging.FromContext(ctx).WarnContext(ctx, "Impersonation started",
+		slog.String("admin", admin), slog.String("impersonated", employee.Email),
+		slog.Bool("allowChanges", c.PostForm("allow-changes") != ""))
+	c.Redirect(http.StatusSeeOther, "/employee/"+url.PathEscape(employee.Email))
+}
+
+func stopImpersonation(c *gin.Context) {
+	ctx := c.Request.Context()
+	if current, ok := currentImpersonation(c.Request); ok {
+		if err := gothic.StoreInSession(impersonationKey, "", c.Request, c.Writer); err != nil {
+			c.String(http.StatusInternalServerError, "Error stopping impersonation: %v", err)
+			return
+		}
+		logging.FromContext(ctx).WarnContext(ctx, "Impersonation stopped",
+			slog.String("admin", Impersonator(c.Request)), slog.String("impersonated", current.Email))
+	}
+	c.Redirect(http.StatusSeeOther, "/")
+}
+
+// GuardImpersonation refuses requests which could change anything while
+// an admin is impersonating an employee, unless the admin allowed
+// changes when they started. Stopping impersonation is always allowed.
+func GuardImpersonation(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+	current, ok := currentImpersonation(c.Request)
+	if !ok || current.AllowChanges || UsingAPIToken(c.Request) || c.FullPath() == impersonationStopPath {
+		c.Next()
+		return
+	}
+	ctx := c.Request.Context()
+	logging.FromContext(ctx).WarnContext(ctx, "Change blocked during impersonation",
+		slog.String("method", c.Request.Method), slog.String("path", c.Request.URL.Path))
+	c.String(http.StatusForbidden, "Changes are blocked while you are viewing Satchel as %s", current.Email)
+	c.Abort()
+}
+
+// RealUserRequired rejects requests made while impersonating, for pages
+// such as token management which must only ever act as the admin
+// themselves.
+func RealUserRequired(c *gin.Context) {
+	if Impersonator(c.Request) != "" {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+)
+
+// OnboardingPath is where new employees are guided through filling in
+// their profile.
+const OnboardingPath = "/onboarding"
+
+const onboardingDeferredKey = "onboardingDeferred"
+
+// startOnboarding gives an employee logging in for the first time prompts
+// from the bank and puts them at the first onboarding step. The caller
+// saves the employee.
+func startOnboarding(ctx context.Context, employee *model.Employee) {
+	addPrompts(ctx, employee)
+	employee.StartOnboarding()
+	now := time.Now()
+	employee.OnboardingStartedAt = &now
+}
+
+// onboardingExemptPaths are the paths, along with everything beneath
+// them, which are served while an employee is onboarding: onboarding
+// itself, logging in and out, and what its pages load.
+var onboardingExemptPaths = []string{
+	OnboardingPath,
+	"/auth",
+	"/static",
+	"/photos",
+	"/avatars",
+	"/metrics",
+	"/forbidden",
+	"/scim",
+}
+
+// GuardOnboarding sends employees who have not finished onboarding to
+// it, unless they chose to finish it later. Only GET requests, which
+// show pages, are checked, so that other requests do not pay for the
+// lookup. Requests made with an API token or while impersonating are
+// never redirected.
+func GuardOnboarding(c *gin.Context) {
+	method := c.Request.Method
+	if method != http.MethodGet && method != http.MethodHead {
+		c.Next()
+		return
+	}
+	if UsingAPIToken(c.Request) || Impersonator(c.Request) != "" || onboardingExempt(c.Request.URL.Path) {
+		c.Next()
+		return
+	}
+	email, err := AuthenticatedUser(c.Request)
+	if err != nil {
+		c.Next()
+		return
+	}
+	employee, err := repository.GetEmployeeByEmail(c.Request.Context(), email)
+	if err != nil || !employee.Onboarding() {
+		c.Next()
+		return
+	}
+	if employee.OnboardingSkipped {
+		c.Set(onboardingDeferredKey, true)
+		c.Next()
+		return
+	}
+	ctx := c.Request.Context()
+	logging.FromContext(ctx).DebugContext(ctx, "Sending employee to onboarding", "path", c.Request.URL.Path)
+	Redirect(c, OnboardingPath)
+	c.Abort()
+}
+
+// OnboardingDeferred reports whether the authenticated user chose to
+// finish onboarding later, so that pages can offer to resume it.
+func OnboardingDeferred(c *gin.Context) bool {
+	return c.GetBool(onboardingDeferredKey)
+}
+
+// Redirect sends the browser to location. HTMX requests are told to
+// load the page with the HX-Redirect header rather than having the
+// redirect followed into the element they target.
+func Redirect(c *gin.Context, location string) {
+	if c.GetHeader("HX-Request") != "" {
+		c.Header("HX-Redirect", location)
+		c.Status(http.StatusOK)
+		return
+	}
+	status := http.StatusSeeOther
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		status = http.StatusFound
+	}
+	c.Redirect(status, location)
+}
+
+func onboardingExempt(path string) bool {
+	for _, exempt := range onboardingExemptPaths {
+		if path == exempt || strings.HasPrefix(path, exempt+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+)
+
+// newProfilePrompts is how many prompts from the bank a new profile
+// starts with.
+const newProfilePrompts = 4
+
+// addPrompts gives a new employee unanswered reflections for prompts
+// from the bank which they do not already have. A profile is still
+// created without them if the bank cannot be read.
+func addPrompts(ctx context.Context, employee *model.Employee) {
+	prompts, err := repository.GetActivePrompts(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "Error retrieving prompts for new employee", "email", employee.Email, "error", err)
+		return
+	}
+	prompts = slices.DeleteFunc(prompts, employee.HasPrompt)
+	for _, prompt := range model.ChoosePrompts(prompts, newProfilePrompts, rand.Shuffle) {
+		employee.AddPrompt(prompt)
+	}
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/markbates/goth/gothic"
+	"gorm.io/gorm"
+)
+
+// sessionCookieKey names the value in the session cookie which holds the
+// key of the user's model.Session.
+const sessionCookieKey = "session"
+
+// lastSeenInterval is how stale a session's last seen time may become
+// before a request updates it.
+const lastSeenInterval = time.Minute
+
+const maxUserAgentLength = 512
+
+var errNotAuthenticated = errors.New("not authenticated")
+
+var (
+	sessionIdleTimeout     = config.Default().Auth.SessionIdleTimeout
+	sessionAbsoluteTimeout = config.Default().Auth.SessionAbsoluteTimeout
+	employeeStatus         = newStatusCache(config.Default().Auth.StatusCacheTTL)
+)
+
+// startSession records a new session for email and stores its key in
+// the session cookie.
+func startSession(c *gin.Context, email string) error {
+	key, err := randomSecret()
+	if err != nil {
+		return err
+	}
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	now := time.Now()
+	session := &model.Session{
+		KeyHash:       hashToken(key),
+		EmployeeEmail: email,
+		UserAgent:     userAgent,
+		IPAddress:     c.ClientIP(),
+		CreatedAt:     now,
+		LastSeenAt:    now,
+	}
+	if err := repository.SaveSession(c.Request.Context(), session); err != nil {
+		return err
+	}
+	return gothic.StoreInSession(sessionCookieKey, key, c.Request, c.Writer)
+}
+
+// LoadSession looks up the session named by the session cookie and
+// revalidates it, since AuthRequired, IsAuthenticated and everything
+// else which asks who the user is rely on the session it loads. A
+// session ends when it has been revoked, has timed out, belongs to an
+// email outside the allowed domains or belongs to an employee who is no
+// longer active. The cookie of an ended session is cleared and the
+// request continues unauthenticated.
+func LoadSession(c *gin.Context) {
+	key, err := gothic.GetFromSession(sessionCookieKey, c.Request)
+	if err != nil || key == "" || UsingAPIToken(c.Request) {
+		c.Next()
+		return
+	}
+	ctx := c.Request.Context()
+	log := logging.FromContext(ctx)
+
+	session, err := repository.GetSessionByKeyHash(ctx, hashToken(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.InfoContext(ctx, "Ended session presented, clearing cookie")
+		gothic.Logout(c.Writer, c.Request)
+		c.Next()
+		return
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "Error looking up session", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	reason, err := endReason(ctx, session, now)
+	if err != nil {
+		log.ErrorContext(ctx, "Error revalidating session", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if reason != "" {
+		log.InfoContext(ctx, "Session ended", "email", session.EmployeeEmail, "reason", reason)
+		if err := repository.DeleteSession(ctx, session.EmployeeEmail, session.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.ErrorContext(ctx, "Error ending session", "error", err)
+		}
+		gothic.Logout(c.Writer, c.Request)
+		c.Next()
+		return
+	}
+
+	if now.Sub(session.LastSeenAt) > lastSeenInterval {
+		session.LastSeenAt = now
+		session.IPAddress = c.ClientIP()
+		if err := repository.SaveSession(ctx, session); err != nil {
+			log.ErrorContext(ctx, "Error recording session activity", "error", err)
+		}
+	}
+
+	ctx = context.WithValue(ctx, sessionContextKey, session)
+	ended, err := endRevokedImpersonation(c, session)
+	if err != nil {
+		log.ErrorContext(ctx, "Error checking the impersonator is still an admin", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if ended {
+		ctx = context.WithValue(ctx, impersonationEndedKey, true)
+	}
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
+
+// endReason explains why session must end, or returns an empty string
+// when it may continue.
+func endReason(ctx context.Context, session *model.Session, now time.Time) (string, error) {
+	switch {
+	case sessionIdleTimeout > 0 && now.Sub(session.LastSeenAt) > sessionIdleTimeout:
+		return "idle timeout", nil
+	case sessionAbsoluteTimeout > 0 && now.Sub(session.CreatedAt) > sessionAbsoluteTimeout:
+		return "absolute timeout", nil
+	case !isAllowedDomain(session.EmployeeEmail):
+		return "domain not allowed", nil
+	}
+	active, err := employeeStatus.isActive(ctx, session.EmployeeEmail)
+	if err != nil {
+		return "", err
+	}
+	if !active {
+		return "employee not active", nil
+	}
+	return "", nil
+}
+
+func currentSession(req *http.Request) (*model.Session, bool) {
+	session, ok := req.Context().Value(sessionContextKey).(*model.Session)
+	return session, ok
+}
+
+// CurrentSessionID returns the ID of the session making the request, or
+// zero when the request has no session.
+func CurrentSessionID(req *http.Request) uint {
+	if session, ok := currentSession(req); ok {
+		return session.ID
+	}
+	return 0
+}
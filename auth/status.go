@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// statusCache remembers whether employees are active, and whether they
+// are admins, for a short time so that revalidating a session does not
+// query the database on every request.
+type statusCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]statusEntry
+}
+
+type statusEntry struct {
+	active  bool
+	admin   bool
+	expires time.Time
+}
+
+func newStatusCache(ttl time.Duration) *statusCache {
+	return &statusCache{ttl: ttl, entries: map[string]statusEntry{}}
+}
+
+// isActive reports whether the employee with email exists and has not
+// been deactivated.
+func (c *statusCache) isActive(ctx context.Context, email string) (bool, error) {
+	entry, err := c.lookup(ctx, email)
+	return entry.active, err
+}
+
+// isAdmin reports whether the employee with email is active and still
+// has admin rights.
+func (c *statusCache) isAdmin(ctx context.Context, email string) (bool, error) {
+	entry, err := c.lookup(ctx, email)
+	return entry.active && entry.admin, err
+}
+
+func (c *statusCache) lookup(ctx context.Context, email string) (statusEntry, error) {
+	now := time.Now()
+	c.mu.Lock()
+	entry, found := c.entries[email]
+	c.mu.Unlock()
+	if found && now.Before(entry.expires) {
+		return entry, nil
+	}
+
+	employee, err := repository.GetEmployeeByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return statusEntry{}, err
+	}
+	entry = statusEntry{
+		active:  err == nil && employee.IsActive(),
+		admin:   err == nil && employee.Admin,
+		expires: now.Add(c.ttl),
+	}
+
+	if c.ttl > 0 {
+		c.mu.Lock()
+		c.entries[email] = entry
+		c.mu.Unlock()
+	}
+	return entry, nil
+}
+
+// forget drops what is known about email, so that the next check reads
+// the employee's current status.
+func (c *statusCache) forget(email string) {
+	c.mu.Lock()
+	delete(c.entries, email)
+	c.mu.Unlock()
+}
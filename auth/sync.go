@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/jeffscottbrown/satchel/avatars"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/markbates/goth"
+)
+
+// syncProfile refreshes the fields the identity provider owns from the
+// user it has just authenticated and returns the fields which changed.
+// Fields the employee has overridden are left alone, as is everything
+// the employee writes themselves, such as their bio, position and
+// reflections.
+func syncProfile(employee *model.Employee, user goth.User) []string {
+	employee.IdentityProfile = model.IdentityProfile{
+		Name:      user.Name,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}
+	var changed []string
+	for _, field := range model.IdentityFields {
+		value := employee.IdentityProfile.Value(field)
+		current := employee.IdentityField(field)
+		if value == "" || employee.Overridden(field) || *current == value {
+			continue
+		}
+		*current = value
+		changed = append(changed, field)
+	}
+
+	// Profiles created by an HR import have no photo until the employee
+	// first logs in, and the identity provider's avatar URLs change over
+	// time. An image served by Satchel itself was chosen deliberately and
+	// is kept.
+	if user.AvatarURL != "" && employee.ImageName != user.AvatarURL &&
+		(employee.ImageName == "" || avatars.Proxied(employee.ImageName)) {
+		employee.ImageName = user.AvatarURL
+		changed = append(changed, "avatar")
+	}
+	return changed
+}
+
+// saveProfile saves the changes syncProfile made to the employee,
+// fetching their avatar first if it has changed. Logging in still
+// succeeds if the avatar cannot be fetched or the profile cannot be
+// saved.
+func saveProfile(ctx context.Context, employee *model.Employee) {
+	refreshAvatar(ctx, employee)
+	if err := repository.SaveEmployee(ctx, employee); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error saving profile for employee", "email", employee.Email, "error", err)
+	}
+}
+
+// refreshAvatar caches the employee's avatar unless the cached copy is
+// already of their current ImageName. Fetching waits on the identity
+// provider, so an avatar which is up to date is not fetched again.
+func refreshAvatar(ctx context.Context, employee *model.Employee) {
+	if !avatars.Stale(employee) {
+		return
+	}
+	if err := avatars.Refresh(ctx, employee); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "Error refreshing avatar for employee", "email", employee.Email, "error", err)
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/markbates/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncProfile_RefreshesFieldsTheEmployeeHasNotOverridden(t *testing.T) {
+	employee := &model.Employee{
+		Name:      "Janie Doe",
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Position:  "Engineer",
+		Bio:       "Written by Jane",
+		ImageName: "https://lh3.googleusercontent.com/a/old",
+	}
+	employee.SetOverridden(model.FieldName, true)
+	user := goth.User{
+		Name:      "Jane Smith",
+		FirstName: "Jane",
+		LastName:  "Smith",
+		AvatarURL: "https://lh3.googleusercontent.com/a/new",
+	}
+
+	changed := syncProfile(employee, user)
+
+	assert.Equal(t, []string{model.FieldLastName, "avatar"}, changed)
+	assert.Equal(t, "Janie Doe", employee.Name, "An overridden field should be kept")
+	assert.Equal(t, "Smith", employee.LastName)
+	assert.Equal(t, "https://lh3.googleusercontent.com/a/new", employee.ImageName)
+	assert.Equal(t, "Engineer", employee.Position)
+	assert.Equal(t, "Written by Jane", employee.Bio)
+	assert.Equal(t, "Jane Smith", employee.IdentityProfile.Name, "The provider's value should be remembered for the profile page")
+}
+
+func TestSyncProfile_KeepsValuesTheProviderDoesNotSend(t *testing.T) {
+	employee := &model.Employee{Name: "Jane Doe", FirstName: "Jane", LastName: "Doe", ImageName: "/static/images/jane.jpg"}
+
+	changed := syncProfile(employee, goth.User{Name: "Jane Doe", AvatarURL: "https://lh3.googleusercontent.com/a/new"})
+
+	assert.Empty(t, changed)
+	assert.Equal(t, "Doe", employee.LastName)
+	assert.Equal(t, "/static/images/jane.jpg", employee.ImageName, "An image chosen for the employee should be kept")
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// tokenPrefix starts every personal access token so that they can be
+// told apart from other bearer tokens, such as the one SCIM clients use,
+// and recognised if they are accidentally committed somewhere.
+const tokenPrefix = "satchel_"
+
+// lastUsedInterval is how stale a token's last used time may become
+// before a request updates it, so that scripts making many requests do
+// not write to the database on every one.
+const lastUsedInterval = time.Minute
+
+const maxTokenNameLength = 100
+
+type contextKey int
+
+const (
+	tokenUserKey contextKey = iota
+	sessionContextKey
+	impersonationEndedKey
+)
+
+// NewAPIToken creates a token for employee and returns the secret, which
+// is shown to the employee once and never stored.
+func NewAPIToken(ctx context.Context, employee *model.Employee, name string, scope string) (string, *model.APIToken, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", nil, errors.New("give the token a name so that you can recognise it later")
+	case len(name) > maxTokenNameLength:
+		return "", nil, fmt.Errorf("token names must be at most %d characters", maxTokenNameLength)
+	case scope != model.TokenScopeRead && scope != model.TokenScopeReadWrite:
+		return "", nil, fmt.Errorf("unknown token scope %q", scope)
+	}
+
+	random, err := randomSecret()
+	if err != nil {
+		return "", nil, err
+	}
+	secret := tokenPrefix + random
+	token := &model.APIToken{
+		EmployeeID: employee.ID,
+		Name:       name,
+		Hash:       hashToken(secret),
+		Hint:       secret[len(secret)-4:],
+		Scope:      scope,
+	}
+	if err := repository.SaveAPIToken(ctx, token); err != nil {
+		return "", nil, err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "API token created",
+		slog.String("email", employee.Email), slog.String("scope", scope), slog.Any("tokenId", token.ID))
+	return secret, token, nil
+}
+
+func randomSecret() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// hashToken returns the hash stored in place of secret, which is a token
+// or session key. Both are long and random, so a fast hash is
+// sufficient.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// BearerToken authenticates requests which carry a personal access token
+// in an "Authorization: Bearer" header. The employee the token belongs
+// to is then treated as the authenticated user for the rest of the
+// request, exactly as if they had a session. Requests without a token
+// are left to the session cookie.
+func BearerToken(c *gin.Context) {
+	secret, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !found || !strings.HasPrefix(secret, tokenPrefix) {
+		c.Next()
+		return
+	}
+	ctx := c.Request.Context()
+	log := logging.FromContext(ctx)
+
+	token, employee, err := lookupToken(ctx, secret)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Header("WWW-Authenticate", `Bearer realm="satchel"`)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "Error looking up API token", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if !token.Allows(c.Request.Method) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if now := time.Now(); token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedInterval {
+		token.LastUsedAt = &now
+		if err := repository.SaveAPIToken(ctx, token); err != nil {
+			log.ErrorContext(ctx, "Error recording API token use", "error", err)
+		}
+	}
+
+	c.Request = c.Request.WithContext(context.WithValue(ctx, tokenUserKey, employee.Email))
+	c.Next()
+}
+
+// lookupToken returns the token with the given secret and the employee
+// it belongs to. Tokens of deactivated employees, and of employees
+// whose domain is no longer allowed, are reported as not found.
+func lookupToken(ctx context.Context, secret string) (*model.APIToken, *model.Employee, error) {
+	token, err := repository.GetAPITokenByHash(ctx, hashToken(secret))
+	if err != nil {
+		return nil, nil, err
+	}
+	employee, err := repository.GetEmployeeByID(ctx, token.EmployeeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !employee.IsActive() || !isAllowedDomain(employee.Email) {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	return token, employee, nil
+}
+
+// UsingAPIToken reports whether the request was authenticated with an
+// API token rather than a session.
+func UsingAPIToken(req *http.Request) bool {
+	_, ok := req.Context().Value(tokenUserKey).(string)
+	return ok
+}
+
+// SessionRequired rejects requests authenticated with an API token. It
+// guards pages, such as token management, which a script should not be
+// able to use.
+func SessionRequired(c *gin.Context) {
+	if UsingAPIToken(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,249 @@
+// Package avatars keeps copies of the images employees have on other
+// sites, such as their Google avatar, so that browsers load them from
+// Satchel rather than from the other site. Copies are stored alongside
+// uploaded photos and are refreshed whenever the employee logs in.
+package avatars
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/photos"
+	"github.com/jeffscottbrown/satchel/repository"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// maxAvatarBytes limits how much of a response is read. Avatars are
+	// far smaller than the photos employees upload.
+	maxAvatarBytes = 5 << 20
+	// retryInterval is how long to wait before fetching an image again
+	// after failing to, so that a broken URL does not slow every page
+	// it appears on.
+	retryInterval = time.Hour
+)
+
+var (
+	// ErrNoAvatar is returned when there is no image to show, in which
+	// case an initials avatar is shown instead.
+	ErrNoAvatar = errors.New("no avatar available")
+	// ErrHostNotAllowed is returned for images on hosts which are not
+	// configured as avatar hosts.
+	ErrHostNotAllowed = errors.New("avatar host is not allowed")
+)
+
+var (
+	allowedHosts = config.Default().Photos.AvatarHosts
+	client       = newClient(config.Default().Photos.AvatarFetchTimeout)
+
+	fetches  singleflight.Group
+	failures = &failureCache{failed: map[string]time.Time{}}
+)
+
+// Configure sets the hosts avatars may be fetched from and how long to
+// wait for them.
+func Configure(settings config.Photos) {
+	allowedHosts = settings.AvatarHosts
+	client = newClient(settings.AvatarFetchTimeout)
+}
+
+func newClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			return checkURL(req.URL)
+		},
+	}
+}
+
+// Proxied reports whether imageName is on another site, and so is shown
+// through the avatar proxy.
+func Proxied(imageName string) bool {
+	u, err := url.Parse(imageName)
+	return err == nil && u.Host != ""
+}
+
+// Stale reports whether the employee's avatar is shown through the proxy
+// but has not been cached yet, or was cached from an ImageName which has
+// since changed.
+func Stale(employee *model.Employee) bool {
+	return Proxied(employee.ImageName) && (employee.Avatar == "" || employee.AvatarSource != employee.ImageName)
+}
+
+// Allowed reports whether imageName may be fetched.
+func Allowed(imageName string) bool {
+	u, err := url.Parse(imageName)
+	return err == nil && checkURL(u) == nil
+}
+
+func checkURL(u *url.URL) error {
+	if u.Scheme != "https" {
+		return fmt.Errorf("avatars must be fetched over https: %s", u.Redacted())
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+}
+
+// fetch downloads the image at imageName.
+func fetch(ctx context.Context, imageName string) ([]byte, error) {
+	u, err := url.Parse(imageName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkURL(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "image/*")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching avatar: %s", res.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxAvatarBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAvatarBytes {
+		return nil, errors.New("avatar is too large")
+	}
+	return data, nil
+}
+
+// Refresh fetches the employee's ImageName again and replaces the cached
+// copy with it. Only the avatar columns are saved, since fetching can
+// be slow and the rest of employee may be stale by the time it
+// finishes. The previous copy is kept if the image cannot be
+// fetched, so that an avatar does not disappear when its URL stops
+// working.
+func Refresh(ctx context.Context, employee *model.Employee) error {
+	if !Proxied(employee.ImageName) {
+		return nil
+	}
+	source := employee.ImageName
+	key, err := store(ctx, source)
+	if err != nil {
+		failures.record(source)
+		return err
+	}
+	failures.forget(source)
+	replaced := employee.Avatar
+	if err := repository.SaveAvatar(ctx, employee.ID, key, source); err != nil {
+		_ = photos.Remove(ctx, key)
+		return err
+	}
+	employee.Avatar = key
+	employee.AvatarSource = source
+	if replaced != "" && replaced != key {
+		if err := photos.Remove(ctx, replaced); err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "failed to remove replaced avatar",
+				slog.String("photo", replaced), slog.Any("error", err))
+		}
+	}
+	return nil
+}
+
+func store(ctx context.Context, source string) (string, error) {
+	data, err := fetch(ctx, source)
+	if err != nil {
+		return "", err
+	}
+	return photos.Save(ctx, data)
+}
+
+// Open returns the cached copy of the employee's avatar at size,
+// fetching it first if it has not been cached yet or the employee's
+// ImageName has changed since. It returns ErrNoAvatar when there is
+// nothing to show.
+func Open(ctx context.Context, employee *model.Employee, size photos.Size) (io.ReadCloser, error) {
+	if !Proxied(employee.ImageName) {
+		return nil, ErrNoAvatar
+	}
+	if Stale(employee) {
+		if err := refreshOnce(ctx, employee); err != nil && employee.Avatar == "" {
+			return nil, errors.Join(ErrNoAvatar, err)
+		}
+	}
+	reader, err := photos.Open(ctx, photos.FileName(employee.Avatar, size))
+	if errors.Is(err, photos.ErrNotFound) {
+		return nil, ErrNoAvatar
+	}
+	return reader, err
+}
+
+// refreshOnce refreshes the avatar unless it failed recently, sharing a
+// single fetch between the requests for an employee's avatar which
+// arrive together, such as every size on the same page.
+func refreshOnce(ctx context.Context, employee *model.Employee) error {
+	if failures.recent(employee.ImageName) {
+		return ErrNoAvatar
+	}
+	result, err, _ := fetches.Do(strconv.FormatUint(uint64(employee.ID), 10), func() (any, error) {
+		err := Refresh(ctx, employee)
+		return [2]string{employee.Avatar, employee.AvatarSource}, err
+	})
+	if err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "failed to fetch avatar",
+			slog.String("email", employee.Email), slog.Any("error", err))
+		return err
+	}
+	refreshed := result.([2]string)
+	employee.Avatar, employee.AvatarSource = refreshed[0], refreshed[1]
+	return nil
+}
+
+// failureCache remembers which images could not be fetched recently.
+type failureCache struct {
+	mu     sync.Mutex
+	failed map[string]time.Time
+}
+
+func (f *failureCache) record(source string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed[source] = time.Now()
+}
+
+func (f *failureCache) forget(source string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.failed, source)
+}
+
+func (f *failureCache) recent(source string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	failedAt, found := f.failed[source]
+	if found && time.Since(failedAt) > retryInterval {
+		delete(f.failed, source)
+		return false
+	}
+	return found
+}
@@ -0,0 +1,23 @@
+//go:build !production
+
+package avatars
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ConfigureForTest fetches avatars from hosts using httpClient, which is
+// typically the client of an httptest.Server, for the rest of the test.
+func ConfigureForTest(t testing.TB, hosts []string, httpClient *http.Client) {
+	originalHosts, originalClient := allowedHosts, client
+	t.Cleanup(func() {
+		allowedHosts, client = originalHosts, originalClient
+		failures = &failureCache{failed: map[string]time.Time{}}
+	})
+	allowedHosts = hosts
+	tested := *httpClient
+	tested.CheckRedirect = newClient(0).CheckRedirect
+	client = &tested
+}
@@ -0,0 +1,76 @@
+package avatars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxied(t *testing.T) {
+	assert.True(t, Proxied("https://lh3.googleusercontent.com/a/photo"))
+	assert.True(t, Proxied("//evil.example.com/photo.png"))
+	assert.False(t, Proxied("/static/images/jane.jpg"))
+	assert.False(t, Proxied(""))
+}
+
+func TestStale(t *testing.T) {
+	employee := &model.Employee{ImageName: "/static/images/jane.jpg"}
+	assert.False(t, Stale(employee), "Images served by Satchel are not cached")
+
+	employee.ImageName = "https://lh3.googleusercontent.com/a/photo"
+	assert.True(t, Stale(employee))
+
+	employee.Avatar, employee.AvatarSource = "0123456789abcdef0123456789abcdef", employee.ImageName
+	assert.False(t, Stale(employee))
+
+	employee.ImageName = "https://lh3.googleusercontent.com/a/new"
+	assert.True(t, Stale(employee), "A changed image should be fetched again")
+}
+
+func TestAllowed(t *testing.T) {
+	ConfigureForTest(t, []string{"googleusercontent.com"}, http.DefaultClient)
+
+	assert.True(t, Allowed("https://lh3.googleusercontent.com/a/photo"))
+	assert.True(t, Allowed("https://googleusercontent.com/a/photo"))
+	assert.False(t, Allowed("http://lh3.googleusercontent.com/a/photo"), "Avatars must be fetched over https")
+	assert.False(t, Allowed("https://googleusercontent.com.evil.example.com/a/photo"))
+	assert.False(t, Allowed("https://evilgoogleusercontent.com/a/photo"))
+	assert.False(t, Allowed("https://169.254.169.254/latest/meta-data"))
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/avatar.png":
+			w.Write([]byte("image bytes"))
+		case "/elsewhere":
+			http.Redirect(w, r, "https://metadata.internal/secret", http.StatusFound)
+		case "/huge":
+			w.Write([]byte(strings.Repeat("x", maxAvatarBytes+1)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ConfigureForTest(t, []string{"127.0.0.1"}, server.Client())
+
+	data, err := fetch(t.Context(), server.URL+"/avatar.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "image bytes", string(data))
+
+	_, err = fetch(t.Context(), server.URL+"/elsewhere")
+	assert.ErrorIs(t, err, ErrHostNotAllowed, "Redirects should be checked against the allowed hosts")
+
+	_, err = fetch(t.Context(), server.URL+"/huge")
+	assert.ErrorContains(t, err, "too large")
+
+	_, err = fetch(t.Context(), server.URL+"/missing")
+	assert.ErrorContains(t, err, "404")
+
+	_, err = fetch(t.Context(), "https://example.com/avatar.png")
+	assert.ErrorIs(t, err, ErrHostNotAllowed)
+}
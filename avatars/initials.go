@@ -0,0 +1,69 @@
+package avatars
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/jeffscottbrown/satchel/model"
+)
+
+// InitialsContentType is the media type of the generated avatars.
+const InitialsContentType = "image/svg+xml"
+
+// palette holds background colors dark enough for white text.
+var palette = []string{
+	"#1f6feb", "#8250df", "#bf3989", "#cf222e", "#bc4c00",
+	"#9a6700", "#1a7f37", "#0969da", "#6639ba", "#57606a",
+}
+
+// Initials draws an avatar showing the employee's initials on a
+// background color chosen from their email, so that it stays the same
+// from page to page.
+func Initials(employee *model.Employee) []byte {
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.ToLower(employee.Email)))
+	background := palette[hash.Sum32()%uint32(len(palette))]
+	return fmt.Appendf(nil, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 100" width="100" height="100">`+
+		`<rect width="100" height="100" fill="%s"/>`+
+		`<text x="50" y="50" dy="0.35em" text-anchor="middle" font-family="system-ui, sans-serif" font-size="42" fill="#fff">%s</text>`+
+		`</svg>`, background, html.EscapeString(initialsOf(employee)))
+}
+
+// initialsOf returns the first letters of the employee's first and last
+// names, falling back to their full name and then their email.
+func initialsOf(employee *model.Employee) string {
+	names := []string{employee.FirstName, employee.LastName}
+	if strings.TrimSpace(employee.FirstName+employee.LastName) == "" {
+		names = strings.Fields(employee.Name)
+		if len(names) > 2 {
+			names = []string{names[0], names[len(names)-1]}
+		}
+	}
+	var initials []rune
+	for _, name := range names {
+		if letter, found := firstLetter(name); found {
+			initials = append(initials, letter)
+		}
+	}
+	if len(initials) == 0 {
+		if letter, found := firstLetter(employee.Email); found {
+			initials = append(initials, letter)
+		}
+	}
+	if len(initials) == 0 {
+		return "?"
+	}
+	return strings.ToUpper(string(initials))
+}
+
+func firstLetter(s string) (rune, bool) {
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r, true
+		}
+	}
+	return 0, false
+}
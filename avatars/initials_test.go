@@ -0,0 +1,39 @@
+package avatars
+
+import (
+	"testing"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitialsOf(t *testing.T) {
+	tests := []struct {
+		employee model.Employee
+		expected string
+	}{
+		{model.Employee{FirstName: "jane", LastName: "doe", Name: "Someone Else"}, "JD"},
+		{model.Employee{FirstName: "Émile", LastName: "Zola"}, "ÉZ"},
+		{model.Employee{Name: "Mary Ann Smith"}, "MS"},
+		{model.Employee{Name: "Cher"}, "C"},
+		{model.Employee{Email: "pat@objectcomputing.com"}, "P"},
+		{model.Employee{}, "?"},
+	}
+	for i := range tests {
+		t.Run(tests[i].expected, func(t *testing.T) {
+			assert.Equal(t, tests[i].expected, initialsOf(&tests[i].employee))
+		})
+	}
+}
+
+func TestInitials(t *testing.T) {
+	employee := &model.Employee{FirstName: "<script>", LastName: "&", Email: "someone@objectcomputing.com"}
+
+	svg := string(Initials(employee))
+
+	assert.Contains(t, svg, `<svg xmlns="http://www.w3.org/2000/svg"`)
+	assert.NotContains(t, svg, "<script>")
+	assert.Contains(t, svg, ">S<", "Punctuation should be skipped when choosing initials")
+	assert.Equal(t, svg, string(Initials(&model.Employee{FirstName: "<script>", LastName: "&", Email: "SOMEONE@objectcomputing.com"})),
+		"The color should not depend on how the email is capitalized")
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jeffscottbrown/satchel/config"
+)
+
+func checkConfigCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("check-config", "",
+		"Loads the configuration from defaults, the configuration file, the\nenvironment and secret providers, then reports every problem found.\nExits 1 when the configuration is invalid.", stdout)
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageErrorf("unexpected arguments: %v", flags.Args())
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	fmt.Fprintf(stdout, "configuration is valid (listening on %s, database %s on %s:%s)\n",
+		cfg.HTTP.ListenAddress(), cfg.Database.Name, cfg.Database.Host, cfg.Database.Port)
+	return nil
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/repository"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error
+}
+
+// commands is populated in init because the help command refers to it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "Run the web application (the default when no command is given)", serveCommand},
+		{"migrate", "Create or update the database schema and exit", migrateCommand},
+		{"seed", "Add sample employees to the directory", seedCommand},
+		{"import", "Create or update employees from a file", importCommand},
+		{"export", "Write the employee directory to a file", exportCommand},
+		{"user", "Manage an employee's admin rights and account status", userCommand},
+		{"check-config", "Load and validate the configuration without starting anything", checkConfigCommand},
+		{"help", "Show help for satchel or one of its commands", helpCommand},
+	}
+}
+
+// usageError marks errors caused by how a command was invoked rather
+// than by a failure while running it, so that Run can exit with ExitUsage.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func usageErrorf(format string, args ...any) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// Run executes the command named by args[0] with the remaining args
+// and returns the process exit code.
+func Run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	name := args[0]
+	if name == "-h" || name == "-help" || name == "--help" {
+		name = "help"
+	}
+
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "satchel: unknown command %q\n\n", name)
+		printUsage(stderr)
+		return ExitUsage
+	}
+
+	err := cmd.run(context.Background(), args[1:], stdout, stderr)
+	var usage usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &usage):
+		fmt.Fprintf(stderr, "satchel %s: %v\n", name, err)
+		fmt.Fprintf(stderr, "Run 'satchel help %s' for usage.\n", name)
+		return ExitUsage
+	default:
+		fmt.Fprintf(stderr, "satchel %s: %v\n", name, err)
+		return ExitFailure
+	}
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: satchel <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-13s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes: 0 success, 1 failure, 2 invalid usage.")
+}
+
+func helpCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		printUsage(stdout)
+		return nil
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil || cmd.name == "help" {
+		return usageErrorf("unknown command %q", args[0])
+	}
+	return cmd.run(ctx, []string{"-h"}, stdout, stdout)
+}
+
+// commandFlags is a FlagSet which prints a command's help text when -h
+// is given. Parse errors are reported by Run rather than by the flag
+// package itself.
+type commandFlags struct {
+	*flag.FlagSet
+	synopsis    string
+	description string
+	output      io.Writer
+}
+
+func newFlagSet(name string, synopsis string, description string, output io.Writer) *commandFlags {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Usage = func() {}
+	return &commandFlags{FlagSet: flags, synopsis: synopsis, description: description, output: output}
+}
+
+// parse parses args, printing help when it is asked for and converting
+// parse failures into usage errors.
+func (f *commandFlags) parse(args []string) error {
+	err := f.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		f.printHelp()
+		return err
+	}
+	if err != nil {
+		return usageError{err}
+	}
+	return nil
+}
+
+func (f *commandFlags) printHelp() {
+	fmt.Fprintf(f.output, "Usage: satchel %s %s\n\n%s\n", f.Name(), f.synopsis, f.description)
+	hasFlags := false
+	f.VisitAll(func(*flag.Flag) {
+		hasFlags = true
+	})
+	if hasFlags {
+		fmt.Fprintln(f.output, "\nFlags:")
+		f.SetOutput(f.output)
+		f.PrintDefaults()
+	}
+}
+
+// loadConfiguration loads and validates the configuration and installs
+// the configured logger.
+func loadConfiguration() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	slog.SetDefault(logging.NewLogger(cfg.Logging))
+	return cfg, nil
+}
+
+// withDatabase connects to and migrates the configured database, runs
+// fn and closes the database again.
+func withDatabase(cfg *config.Config, fn func() error) error {
+	if err := repository.InitializeDatabase(cfg.Database); err != nil {
+		return err
+	}
+	defer func() {
+		if err := repository.CloseDatabase(); err != nil {
+			slog.Error("failed to close database", slog.Any("error", err))
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func run(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Help(t *testing.T) {
+	for _, args := range [][]string{{"help"}, {"-h"}, {"--help"}} {
+		code, stdout, _ := run(args...)
+
+		assert.Equal(t, ExitOK, code)
+		assert.Contains(t, stdout, "Usage: satchel <command>")
+		assert.Contains(t, stdout, "check-config")
+	}
+}
+
+func TestRun_CommandHelp(t *testing.T) {
+	code, stdout, _ := run("help", "seed")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "Usage: satchel seed")
+	assert.Contains(t, stdout, "-domain")
+
+	code, stdout, _ = run("user", "-h")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "grant-admin")
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	tests := map[string][]string{
+		"unknown command":   {"frobnicate"},
+		"unknown help":      {"help", "frobnicate"},
+		"unknown flag":      {"migrate", "-verbose"},
+		"missing arguments": {"user", "grant-admin"},
+		"unknown action":    {"user", "promote", "someone@objectcomputing.com"},
+		"extra arguments":   {"check-config", "now"},
+		"no import file":    {"import"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, stdout, stderr := run(args...)
+
+			assert.Equal(t, ExitUsage, code)
+			assert.Empty(t, stdout)
+			assert.NotEmpty(t, stderr)
+		})
+	}
+}
+
+func TestRun_CheckConfig(t *testing.T) {
+	t.Setenv("SATCHEL_SECRET_PROVIDERS", "env")
+	t.Setenv("SATCHEL_DB_USER", "satchel")
+	t.Setenv("SATCHEL_DB_PASSWORD", "secret")
+	t.Setenv("SATCHEL_DB_NAME", "satcheldb")
+	t.Setenv("SATCHEL_DB_HOST", "localhost")
+
+	code, _, stderr := run("check-config")
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, stderr, "SATCHEL_DB_PORT must be set")
+	assert.NotContains(t, stderr, "SATCHEL_DB_HOST must be set")
+
+	t.Setenv("SATCHEL_DB_PORT", "5432")
+	code, stdout, _ := run("check-config")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "configuration is valid")
+}
+
+// memoryEmployeeRepository is just enough of a repository for the
+// seed command.
+type memoryEmployeeRepository struct {
+	employees map[string]*model.Employee
+}
+
+func newMemoryEmployeeRepository(t *testing.T) *memoryEmployeeRepository {
+	repo := &memoryEmployeeRepository{employees: map[string]*model.Employee{}}
+	repository.ConfigureRepositoryForTest(t, repo)
+	return repo
+}
+
+func (r *memoryEmployeeRepository) GetEmployees(ctx context.Context) ([]model.Employee, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) GetAllEmployees(ctx context.Context) ([]model.Employee, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) GetEmployeesWithReflections(ctx context.Context) ([]model.Employee, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) GetEmployeeByID(ctx context.Context, id uint) (*model.Employee, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryEmployeeRepository) GetEmployeeByEmail(ctx context.Context, email string) (model.Employee, error) {
+	employee, ok := r.employees[email]
+	if !ok {
+		return model.Employee{}, gorm.ErrRecordNotFound
+	}
+	return model.Employee{
+		Email:       employee.Email,
+		Name:        employee.Name,
+		FirstName:   employee.FirstName,
+		LastName:    employee.LastName,
+		Position:    employee.Position,
+		ImageName:   employee.ImageName,
+		Bio:         employee.Bio,
+		Reflections: append([]model.Reflection(nil), employee.Reflections...),
+	}, nil
+}
+
+func (r *memoryEmployeeRepository) SaveEmployee(ctx context.Context, employee *model.Employee) error {
+	r.employees[employee.Email] = employee
+	return nil
+}
+
+func (r *memoryEmployeeRepository) SaveAvatar(ctx context.Context, employeeID uint, avatar string, source string) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) SaveRemindedAt(ctx context.Context, employeeID uint, remindedAt time.Time) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) DeleteReflection(ctx context.Context, reflectionId uint) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) DeleteEmployee(ctx context.Context, email string) error {
+	delete(r.employees, email)
+	return nil
+}
+
+func (r *memoryEmployeeRepository) CountEmployees(ctx context.Context) (int64, error) {
+	return int64(len(r.employees)), nil
+}
+
+func (r *memoryEmployeeRepository) CountEmployeesWithoutBio(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryEmployeeRepository) SaveAPIToken(ctx context.Context, token *model.APIToken) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) GetAPITokens(ctx context.Context, employeeID uint) ([]model.APIToken, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) GetAPITokenByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryEmployeeRepository) DeleteAPIToken(ctx context.Context, employeeID uint, id uint) error {
+	return gorm.ErrRecordNotFound
+}
+
+func (r *memoryEmployeeRepository) SaveSession(ctx context.Context, session *model.Session) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) GetSessionByKeyHash(ctx context.Context, keyHash string) (*model.Session, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryEmployeeRepository) GetSessions(ctx context.Context, email string) ([]model.Session, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) DeleteSession(ctx context.Context, email string, id uint) error {
+	return gorm.ErrRecordNotFound
+}
+
+func (r *memoryEmployeeRepository) DeleteSessions(ctx context.Context, email string) (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryEmployeeRepository) SaveReflection(ctx context.Context, reflection *model.Reflection) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) SavePrompt(ctx context.Context, prompt *model.Prompt) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) GetPrompts(ctx context.Context) ([]model.Prompt, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) GetActivePrompts(ctx context.Context) ([]model.Prompt, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) GetPrompt(ctx context.Context, id uint) (*model.Prompt, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryEmployeeRepository) SaveTeam(ctx context.Context, team *model.Team) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) GetTeams(ctx context.Context) ([]model.Team, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) GetTeam(ctx context.Context, id uint) (*model.Team, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryEmployeeRepository) SaveTeamMembership(ctx context.Context, membership *model.TeamMembership) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) DeleteTeamMembership(ctx context.Context, teamID uint, employeeID uint) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) SaveSkill(ctx context.Context, skill *model.Skill) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) GetSkills(ctx context.Context) ([]model.Skill, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) GetSkill(ctx context.Context, id uint) (*model.Skill, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryEmployeeRepository) GetEmployeeSkills(ctx context.Context, employeeID uint) ([]model.EmployeeSkill, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) FindEmployeeSkills(ctx context.Context, skillID uint, minProficiency int) ([]model.EmployeeSkill, error) {
+	return nil, nil
+}
+
+func (r *memoryEmployeeRepository) SaveEmployeeSkill(ctx context.Context, employeeSkill *model.EmployeeSkill) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) DeleteEmployeeSkill(ctx context.Context, employeeID uint, skillID uint) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) SaveEndorsement(ctx context.Context, endorsement *model.Endorsement) error {
+	return nil
+}
+
+func (r *memoryEmployeeRepository) DeleteEndorsement(ctx context.Context, employeeSkillID uint, endorserID uint) error {
+	return nil
+}
+
+func TestSeedEmployee_IsIdempotent(t *testing.T) {
+	repo := newMemoryEmployeeRepository(t)
+
+	created, err := seedEmployee(t.Context(), sampleEmployees[0], "objectcomputing.com")
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = seedEmployee(t.Context(), sampleEmployees[0], "objectcomputing.com")
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	employee := repo.employees["henry.thoreau@objectcomputing.com"]
+	assert.Equal(t, "/static/images/henry.jpg", employee.ImageName)
+	assert.Equal(t, "Walden Pond", employee.Reflections[0].Value)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeffscottbrown/satchel/exporter"
+	"github.com/jeffscottbrown/satchel/photos"
+)
+
+func exportCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("export", "[flags]",
+		`Writes active employees as CSV, JSON or vCard. CSV and JSON exports can be
+read back with import. vCards embed uploaded photos and cached avatars from
+the configured photo storage; other photo links are made absolute using the
+configured public URL.`, stdout)
+	output := flags.String("o", "-", "file to write, or - for standard output")
+	format := flags.String("format", "", "csv, json or vcard (default: taken from -o, otherwise json)")
+	email := flags.String("email", "", "export only the employee with this email address")
+	query := flags.String("q", "", "export employees whose name, email or position contains this text")
+	position := flags.String("position", "", "export employees with this position")
+	manager := flags.String("manager", "", "export employees who report to the manager with this email address")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageErrorf("unexpected arguments: %v", flags.Args())
+	}
+	if *format == "" {
+		*format = exportFormatFromFilename(*output)
+	}
+	if contentType, _ := exporter.ContentType(*format); contentType == "" {
+		return usageErrorf("unknown format %q", *format)
+	}
+
+	cfg, err := loadConfiguration()
+	if err != nil {
+		return err
+	}
+	if *format == exporter.FormatVCard {
+		if err := photos.Configure(ctx, cfg.Photos); err != nil {
+			return err
+		}
+	}
+	return withDatabase(cfg, func() error {
+		employees, err := exporter.Employees(ctx, exporter.Filter{
+			Email:        *email,
+			Query:        *query,
+			Position:     *position,
+			ManagerEmail: *manager,
+		})
+		if err != nil {
+			return err
+		}
+		if *email != "" && len(employees) == 0 {
+			return fmt.Errorf("no active employee with email %s", *email)
+		}
+
+		w := stdout
+		if *output != "-" {
+			file, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+		return exporter.Write(ctx, w, *format, employees, cfg.HTTP.PublicURL)
+	})
+}
+
+func exportFormatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return exporter.FormatCSV
+	case ".vcf":
+		return exporter.FormatVCard
+	}
+	return exporter.FormatJSON
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jeffscottbrown/satchel/importer"
+)
+
+func importCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("import", "[flags] <file>",
+		`Creates or updates employees from an HR export, matching existing employees
+by email address. CSV and XLSX files need a header row with an email column
+and may also have name, first name, last name, position, manager (the
+manager's email address) and start date columns. JSON files written by
+export are accepted too. Use - to read from standard input.
+
+Rows which cannot be imported, such as duplicates or invalid email addresses,
+are reported as conflicts and skipped, as are managers who would make a
+reporting cycle. Exits 1 when there are conflicts.`, stdout)
+	dryRun := flags.Bool("dry-run", false, "report what would change without saving anything")
+	format := flags.String("format", "", "csv, xlsx or json (default: taken from the file extension)")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageErrorf("expected exactly one file")
+	}
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = importer.FormatFromFilename(path)
+	}
+	if *format == "" {
+		return usageErrorf("cannot tell the format of %s, use -format", path)
+	}
+
+	records, err := readImportFile(path, *format)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfiguration()
+	if err != nil {
+		return err
+	}
+	return withDatabase(cfg, func() error {
+		report, err := importer.Apply(ctx, records, *dryRun)
+		if err != nil {
+			return err
+		}
+		printImportReport(stdout, report)
+		if conflicts := report.Count(importer.ActionConflict); conflicts > 0 {
+			return fmt.Errorf("%d records could not be imported", conflicts)
+		}
+		return nil
+	})
+}
+
+func readImportFile(path string, format string) ([]importer.Record, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+	records, err := importer.Parse(format, r)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return records, nil
+}
+
+func printImportReport(w io.Writer, report importer.Report) {
+	for _, outcome := range report.Outcomes {
+		switch outcome.Action {
+		case importer.ActionUpdate:
+			fmt.Fprintf(w, "line %d: update %s (%s)\n", outcome.Line, outcome.Email, strings.Join(outcome.Changes, ", "))
+		case importer.ActionConflict:
+			fmt.Fprintf(w, "line %d: conflict %s: %s\n", outcome.Line, outcome.Email, strings.Join(outcome.Problems, "; "))
+		default:
+			fmt.Fprintf(w, "line %d: %s %s\n", outcome.Line, outcome.Action, outcome.Email)
+		}
+	}
+	fmt.Fprintln(w, report)
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+type sampleEmployee struct {
+	firstName   string
+	lastName    string
+	position    string
+	imageName   string
+	bio         string
+	reflections [][2]string
+}
+
+var sampleEmployees = []sampleEmployee{
+	{
+		firstName: "Henry",
+		lastName:  "Thoreau",
+		position:  "Naturalist",
+		imageName: "/static/images/henry.jpg",
+		bio:       "Spent two years, two months and two days living simply in a cabin he built himself.",
+		reflections: [][2]string{
+			{"Favorite Place", "Walden Pond"},
+		},
+	},
+	{
+		firstName: "Keith",
+		lastName:  "Emerson",
+		position:  "Keyboardist",
+		imageName: "/static/images/keith.jpg",
+		bio:       "Known for playing the Hammond organ with considerably more enthusiasm than the manufacturer intended.",
+		reflections: [][2]string{
+			{"Favorite Instrument", "Moog modular synthesizer"},
+		},
+	},
+}
+
+func seedCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("seed", "[flags]",
+		"Adds sample employees for local development and demonstrations. Employees\nwhich already exist are left untouched, so seeding more than once is safe.", stdout)
+	domain := flags.String("domain", "", "email domain for the sample employees (default: the first allowed domain)")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageErrorf("unexpected arguments: %v", flags.Args())
+	}
+
+	cfg, err := loadConfiguration()
+	if err != nil {
+		return err
+	}
+	if *domain == "" && len(cfg.Auth.AllowedDomains) > 0 {
+		*domain = cfg.Auth.AllowedDomains[0]
+	}
+	if *domain == "" {
+		return usageErrorf("no -domain given and no allowed domains are configured")
+	}
+
+	return withDatabase(cfg, func() error {
+		for _, sample := range sampleEmployees {
+			created, err := seedEmployee(ctx, sample, *domain)
+			if err != nil {
+				return err
+			}
+			if created {
+				fmt.Fprintf(stdout, "created %s %s\n", sample.firstName, sample.lastName)
+			} else {
+				fmt.Fprintf(stdout, "skipped %s %s (already exists)\n", sample.firstName, sample.lastName)
+			}
+		}
+		return nil
+	})
+}
+
+func seedEmployee(ctx context.Context, sample sampleEmployee, domain string) (bool, error) {
+	email := strings.ToLower(fmt.Sprintf("%s.%s@%s", sample.firstName, sample.lastName, domain))
+	_, err := repository.GetEmployeeByEmail(ctx, email)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	employee := &model.Employee{
+		Name:      sample.firstName + " " + sample.lastName,
+		FirstName: sample.firstName,
+		LastName:  sample.lastName,
+		Position:  sample.position,
+		ImageName: sample.imageName,
+		Email:     email,
+		Bio:       sample.bio,
+	}
+	for _, reflection := range sample.reflections {
+		employee.AddReflection(reflection[0], reflection[1])
+	}
+	return true, repository.SaveEmployee(ctx, employee)
+}
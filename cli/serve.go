@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/avatars"
+	"github.com/jeffscottbrown/satchel/completeness"
+	"github.com/jeffscottbrown/satchel/mail"
+	"github.com/jeffscottbrown/satchel/metrics"
+	"github.com/jeffscottbrown/satchel/photos"
+	"github.com/jeffscottbrown/satchel/scim"
+	"github.com/jeffscottbrown/satchel/server"
+	"github.com/jeffscottbrown/satchel/tracing"
+)
+
+func serveCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("serve", "[flags]",
+		"Connects to the database, applies any pending migrations and serves the\napplication until SIGINT or SIGTERM is received.", stdout)
+	address := flags.String("address", "", "interface to listen on, overriding the configured HTTP address")
+	port := flags.String("port", "", "port to listen on, overriding the configured port")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageErrorf("unexpected arguments: %v", flags.Args())
+	}
+
+	cfg, err := loadConfiguration()
+	if err != nil {
+		return err
+	}
+	if *address != "" {
+		cfg.HTTP.Address = *address
+	}
+	if *port != "" {
+		cfg.HTTP.Port = *port
+	}
+	if cfg.GinMode != "" {
+		gin.SetMode(cfg.GinMode)
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush traces", slog.Any("error", err))
+		}
+	}()
+
+	auth.Configure(cfg.Auth)
+	scim.Configure(cfg.SCIM)
+	metrics.Configure(cfg.Metrics)
+	if err := photos.Configure(ctx, cfg.Photos); err != nil {
+		return err
+	}
+	avatars.Configure(cfg.Photos)
+	if err := completeness.Configure(cfg.Completeness); err != nil {
+		return err
+	}
+	mail.Configure(cfg.Mail)
+	return withDatabase(cfg, func() error {
+		if err := server.Run(cfg.HTTP); err != nil {
+			return errors.Join(errors.New("HTTP server failed"), err)
+		}
+		return nil
+	})
+}
+
+func migrateCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("migrate", "",
+		"Creates or updates the database schema and exits. The same migrations run\nwhenever the server starts, so this is only needed to migrate ahead of a\ndeployment.", stdout)
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageErrorf("unexpected arguments: %v", flags.Args())
+	}
+
+	cfg, err := loadConfiguration()
+	if err != nil {
+		return err
+	}
+	return withDatabase(cfg, func() error {
+		_, err := io.WriteString(stdout, "database schema is up to date\n")
+		return err
+	})
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jeffscottbrown/satchel/photos"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+var userActions = map[string]func(ctx context.Context, email string) error{
+	"grant-admin": func(ctx context.Context, email string) error {
+		return repository.SetAdmin(ctx, email, true)
+	},
+	"revoke-admin": func(ctx context.Context, email string) error {
+		return repository.SetAdmin(ctx, email, false)
+	},
+	"logout": func(ctx context.Context, email string) error {
+		_, err := repository.DeleteSessions(ctx, email)
+		return err
+	},
+	"deactivate": repository.DeactivateEmployee,
+	"reactivate": repository.ReactivateEmployee,
+	"delete":     repository.DeleteEmployee,
+}
+
+func userCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("user", "<action> <email>",
+		`Changes a single employee's account. Actions:
+
+  grant-admin    give the employee admin rights
+  revoke-admin   remove the employee's admin rights
+  logout         end every session the employee has, logging them out everywhere
+  deactivate     hide the employee from the directory, log them out and block their login
+  reactivate     undo deactivate
+  delete         permanently remove the employee, their reflections and photos
+
+Exits 1 when no employee has the given email address.`, stdout)
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usageErrorf("expected an action and an email address")
+	}
+	actionName, email := flags.Arg(0), flags.Arg(1)
+	action, ok := userActions[actionName]
+	if !ok {
+		return usageErrorf("unknown action %q", actionName)
+	}
+
+	cfg, err := loadConfiguration()
+	if err != nil {
+		return err
+	}
+	if actionName == "delete" {
+		if err := photos.Configure(ctx, cfg.Photos); err != nil {
+			return err
+		}
+	}
+	return withDatabase(cfg, func() error {
+		// Checked up front so that every action reports a missing
+		// employee the same way.
+		if _, err := repository.GetEmployeeByEmail(ctx, email); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("no employee with email %s", email)
+			}
+			return err
+		}
+		if err := action(ctx, email); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s: %s\n", actionName, email)
+		return nil
+	})
+}
@@ -0,0 +1,122 @@
+// Package completeness scores how complete employees' profiles are
+// against the configured rules and reminds employees whose profiles are
+// incomplete to finish them.
+package completeness
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/mail"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+)
+
+var (
+	rules, _         = model.ParseCompletenessRules(config.Default().Completeness.Rules)
+	threshold        = config.Default().Completeness.Threshold
+	reminderInterval = config.Default().Completeness.ReminderInterval
+)
+
+// Configure sets the rules profiles are scored by, the score below which
+// they are incomplete and how often an employee may be reminded.
+func Configure(settings config.Completeness) error {
+	configured, err := model.ParseCompletenessRules(settings.Rules)
+	if err != nil {
+		return err
+	}
+	rules = configured
+	threshold = settings.Threshold
+	reminderInterval = settings.ReminderInterval
+	return nil
+}
+
+// Score scores employee's profile against the configured rules.
+func Score(employee *model.Employee) model.Completeness {
+	return employee.Completeness(rules)
+}
+
+// Threshold is the score below which a profile is incomplete.
+func Threshold() int {
+	return threshold
+}
+
+// Report is an incomplete profile and whether its employee may be
+// reminded about it yet.
+type Report struct {
+	Employee     *model.Employee
+	Completeness model.Completeness
+	CanRemind    bool
+}
+
+// Incomplete reports on the active employees whose profiles score below
+// the threshold, least complete first.
+func Incomplete(ctx context.Context, now time.Time) ([]Report, error) {
+	employees, err := repository.GetEmployeesWithReflections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var reports []Report
+	for i := range employees {
+		employee := &employees[i]
+		completeness := Score(employee)
+		if completeness.Score >= threshold {
+			continue
+		}
+		reports = append(reports, Report{Employee: employee, Completeness: completeness, CanRemind: canRemind(employee, now)})
+	}
+	slices.SortStableFunc(reports, func(a, b Report) int {
+		return a.Completeness.Score - b.Completeness.Score
+	})
+	return reports, nil
+}
+
+// Remind emails employee the suggestions for completing their profile,
+// linking to it at profileURL, and records when they were reminded. It
+// reports false without sending anything if the profile is complete
+// enough or the employee was reminded too recently.
+func Remind(ctx context.Context, employee *model.Employee, profileURL string, now time.Time) (bool, error) {
+	completeness := Score(employee)
+	if completeness.Score >= threshold || !canRemind(employee, now) {
+		return false, nil
+	}
+	if err := mail.Send(ctx, reminder(employee, completeness, profileURL)); err != nil {
+		return false, err
+	}
+	if err := repository.SaveRemindedAt(ctx, employee.ID, now); err != nil {
+		return true, err
+	}
+	employee.RemindedAt = &now
+	logging.FromContext(ctx).InfoContext(ctx, "Reminded employee to complete their profile",
+		slog.String("email", employee.Email), slog.Int("score", completeness.Score))
+	return true, nil
+}
+
+func canRemind(employee *model.Employee, now time.Time) bool {
+	return employee.RemindedAt == nil || now.Sub(*employee.RemindedAt) >= reminderInterval
+}
+
+func reminder(employee *model.Employee, completeness model.Completeness, profileURL string) mail.Message {
+	name := employee.FirstName
+	if name == "" {
+		name = employee.Name
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi %s,\n\n", name)
+	fmt.Fprintf(&body, "Your Satchel profile is %d%% complete. A complete profile helps the team get to know you. You could:\n\n", completeness.Score)
+	for _, suggestion := range completeness.Suggestions {
+		fmt.Fprintf(&body, "  - %s\n", suggestion)
+	}
+	fmt.Fprintf(&body, "\nFinish your profile at %s\n", profileURL)
+	return mail.Message{
+		To:      employee.Email,
+		Subject: fmt.Sprintf("Your Satchel profile is %d%% complete", completeness.Score),
+		Body:    body.String(),
+	}
+}
@@ -0,0 +1,91 @@
+package completeness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/mail"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func saveEmployee(t *testing.T, employee *model.Employee) *model.Employee {
+	t.Cleanup(func() {
+		repository.DeleteEmployee(context.Background(), employee.Email)
+	})
+	assert.NoError(t, repository.SaveEmployee(t.Context(), employee))
+	saved, err := repository.GetEmployeeByEmail(t.Context(), employee.Email)
+	assert.NoError(t, err)
+	return saved
+}
+
+func TestIncomplete(t *testing.T) {
+	now := time.Now()
+	saveEmployee(t, &model.Employee{Email: "empty@objectcomputing.com"})
+	saveEmployee(t, &model.Employee{Email: "halfway@objectcomputing.com", Name: "Halfway", Position: "Engineer", Bio: "Something"})
+	complete := &model.Employee{Email: "complete@objectcomputing.com", Name: "Complete", Position: "Engineer", Bio: "Something", Photo: "0123456789abcdef0123456789abcdef"}
+	complete.AddReflection("Home", "St. Louis")
+	complete.AddReflection("Pets", "A cat")
+	complete.AddReflection("Band", "Phish")
+	saveEmployee(t, complete)
+
+	reports, err := Incomplete(t.Context(), now)
+
+	assert.NoError(t, err)
+	var emails []string
+	for _, report := range reports {
+		emails = append(emails, report.Employee.Email)
+		assert.Less(t, report.Completeness.Score, Threshold())
+		assert.True(t, report.CanRemind)
+	}
+	assert.Equal(t, []string{"empty@objectcomputing.com", "halfway@objectcomputing.com"}, emails)
+}
+
+func TestRemind(t *testing.T) {
+	outbox := mail.ConfigureForTest(t)
+	now := time.Now()
+	employee := saveEmployee(t, &model.Employee{Email: "forgetful@objectcomputing.com", FirstName: "Forgetful"})
+	edited, _ := repository.GetEmployeeByEmail(t.Context(), "forgetful@objectcomputing.com")
+	edited.Position = "Engineer"
+	assert.NoError(t, repository.SaveEmployee(t.Context(), edited))
+
+	reminded, err := Remind(t.Context(), employee, "https://satchel.example.com/employee/forgetful@objectcomputing.com", now)
+
+	assert.NoError(t, err)
+	assert.True(t, reminded)
+	messages := outbox.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "forgetful@objectcomputing.com", messages[0].To)
+	assert.Equal(t, "Your Satchel profile is 0% complete", messages[0].Subject)
+	assert.Contains(t, messages[0].Body, "Hi Forgetful,")
+	assert.Contains(t, messages[0].Body, "  - Write a short bio.\n")
+	assert.Contains(t, messages[0].Body, "https://satchel.example.com/employee/forgetful@objectcomputing.com")
+
+	employee, _ = repository.GetEmployeeByEmail(t.Context(), "forgetful@objectcomputing.com")
+	assert.NotNil(t, employee.RemindedAt)
+	assert.Equal(t, "Engineer", employee.Position, "Reminding should not overwrite changes saved since the employee was loaded")
+	reminded, err = Remind(t.Context(), employee, "", now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, reminded, "Employees should not be reminded again so soon")
+	reminded, _ = Remind(t.Context(), employee, "", now.Add(8*24*time.Hour))
+	assert.True(t, reminded)
+	assert.Len(t, outbox.Messages(), 2)
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, Configure(config.Default().Completeness))
+	})
+
+	assert.Error(t, Configure(config.Completeness{Rules: []string{"pets=10"}, Threshold: 50}))
+	assert.NoError(t, Configure(config.Completeness{Rules: []string{"bio=1"}, Threshold: 50}))
+	assert.Equal(t, 50, Threshold())
+	assert.Equal(t, 100, Score(&model.Employee{Bio: "Something"}).Score)
+}
+
+func TestMain(m *testing.M) {
+	repository.RunTestsWithTestContainer(m)
+}
@@ -0,0 +1,7 @@
+//go:build !production
+
+package config
+
+// productionBuild is false unless the production build tag is set, so
+// the development session secret is accepted outside of release mode.
+const productionBuild = false
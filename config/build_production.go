@@ -0,0 +1,7 @@
+//go:build production
+
+package config
+
+// productionBuild is true in production builds, which never accept the
+// development session secret.
+const productionBuild = true
@@ -0,0 +1,354 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/model"
+)
+
+// Config is the complete, typed configuration of the application.
+//
+// Each leaf field is addressed by the path of its yaml tags in a
+// configuration file (for example database.host) and by its env tag
+// in the environment. Fields tagged with secret are additionally
+// looked up in the secret manager under their environment variable name.
+type Config struct {
+	GinMode      string       `yaml:"ginMode" env:"GIN_MODE"`
+	HTTP         HTTP         `yaml:"http"`
+	Database     Database     `yaml:"database"`
+	Auth         Auth         `yaml:"auth"`
+	SCIM         SCIM         `yaml:"scim"`
+	Metrics      Metrics      `yaml:"metrics"`
+	Photos       Photos       `yaml:"photos"`
+	Completeness Completeness `yaml:"completeness"`
+	Mail         Mail         `yaml:"mail"`
+	Tracing      Tracing      `yaml:"tracing"`
+	Logging      Logging      `yaml:"logging"`
+}
+
+// HTTP holds the values used to build the http.Server that serves
+// the application.
+type HTTP struct {
+	Address           string        `yaml:"address" env:"SATCHEL_HTTP_ADDRESS"`
+	Port              string        `yaml:"port" env:"PORT"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"SATCHEL_HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"SATCHEL_HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" env:"SATCHEL_HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout" env:"SATCHEL_HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout" env:"SATCHEL_HTTP_SHUTDOWN_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"maxHeaderBytes" env:"SATCHEL_HTTP_MAX_HEADER_BYTES"`
+	TLSCertFile       string        `yaml:"tlsCertFile" env:"SATCHEL_TLS_CERT_FILE"`
+	TLSKeyFile        string        `yaml:"tlsKeyFile" env:"SATCHEL_TLS_KEY_FILE"`
+	// PublicURL is the address users reach the application at, such as
+	// https://satchel.example.com. It is needed to build links outside of
+	// a request, for example in exports made from the command line.
+	PublicURL string `yaml:"publicURL" env:"SATCHEL_PUBLIC_URL"`
+}
+
+// ListenAddress returns Address when it has been configured and
+// otherwise listens on Port for compatibility with gin and Cloud Run.
+func (h HTTP) ListenAddress() string {
+	if h.Address != "" {
+		return h.Address
+	}
+	return ":" + h.Port
+}
+
+// TLSEnabled reports whether both a certificate and key were configured.
+func (h HTTP) TLSEnabled() bool {
+	return h.TLSCertFile != "" && h.TLSKeyFile != ""
+}
+
+type Database struct {
+	User     string `yaml:"user" env:"SATCHEL_DB_USER" secret:"true"`
+	Password string `yaml:"password" env:"SATCHEL_DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"SATCHEL_DB_NAME" secret:"true"`
+	Host     string `yaml:"host" env:"SATCHEL_DB_HOST" secret:"true"`
+	Port     string `yaml:"port" env:"SATCHEL_DB_PORT" secret:"true"`
+}
+
+// ConnectionString returns the postgres DSN for the database.
+func (d Database) ConnectionString() string {
+	return fmt.Sprintf("user=%s password=%s dbname=%s host=%s port=%s sslmode=disable", d.User, d.Password, d.Name, d.Host, d.Port)
+}
+
+// Auth configures logins and sessions. Sessions end after
+// SessionIdleTimeout without a request, and SessionAbsoluteTimeout after
+// logging in however active they are; zero disables either timeout. An
+// employee's account status is rechecked on each request at most once
+// every StatusCacheTTL.
+type Auth struct {
+	SessionSecret          string        `yaml:"sessionSecret" env:"SATCHEL_SESSION_SECRET" secret:"true"`
+	AllowedDomains         []string      `yaml:"allowedDomains" env:"SATCHEL_ALLOWED_DOMAINS"`
+	SessionIdleTimeout     time.Duration `yaml:"sessionIdleTimeout" env:"SATCHEL_SESSION_IDLE_TIMEOUT"`
+	SessionAbsoluteTimeout time.Duration `yaml:"sessionAbsoluteTimeout" env:"SATCHEL_SESSION_ABSOLUTE_TIMEOUT"`
+	StatusCacheTTL         time.Duration `yaml:"statusCacheTTL" env:"SATCHEL_AUTH_STATUS_CACHE_TTL"`
+	Google                 OAuth         `yaml:"google"`
+}
+
+// SCIM configures provisioning by an identity provider. The endpoints
+// are disabled until Token, which the identity provider sends as a
+// bearer token, is set.
+type SCIM struct {
+	Token string `yaml:"token" env:"SATCHEL_SCIM_TOKEN" secret:"true"`
+}
+
+// Metrics holds the bearer token Prometheus must present to scrape
+// /metrics. The endpoint refuses every request while no token is set.
+type Metrics struct {
+	Token string `yaml:"token" env:"SATCHEL_METRICS_TOKEN" secret:"true"`
+}
+
+// Photos configures where uploaded profile photos, and the cached copies
+// of avatars from other sites, are kept. Storage is "local", which keeps
+// them beneath Directory, or "s3", which keeps them in an S3-compatible
+// bucket such as MinIO. Uploads larger than MaxUploadBytes are refused.
+//
+// Avatars are only fetched over HTTPS from AvatarHosts and their
+// subdomains, waiting at most AvatarFetchTimeout.
+type Photos struct {
+	Storage            string        `yaml:"storage" env:"SATCHEL_PHOTO_STORAGE"`
+	Directory          string        `yaml:"directory" env:"SATCHEL_PHOTO_DIRECTORY"`
+	MaxUploadBytes     int           `yaml:"maxUploadBytes" env:"SATCHEL_PHOTO_MAX_UPLOAD_BYTES"`
+	AvatarHosts        []string      `yaml:"avatarHosts" env:"SATCHEL_AVATAR_HOSTS"`
+	AvatarFetchTimeout time.Duration `yaml:"avatarFetchTimeout" env:"SATCHEL_AVATAR_FETCH_TIMEOUT"`
+	S3                 S3            `yaml:"s3"`
+}
+
+// S3 locates the bucket photos are stored in when Photos.Storage is "s3".
+type S3 struct {
+	Endpoint        string `yaml:"endpoint" env:"SATCHEL_S3_ENDPOINT"`
+	Region          string `yaml:"region" env:"SATCHEL_S3_REGION"`
+	Bucket          string `yaml:"bucket" env:"SATCHEL_S3_BUCKET"`
+	AccessKeyID     string `yaml:"accessKeyId" env:"SATCHEL_S3_ACCESS_KEY_ID" secret:"true"`
+	SecretAccessKey string `yaml:"secretAccessKey" env:"SATCHEL_S3_SECRET_ACCESS_KEY" secret:"true"`
+	Insecure        bool   `yaml:"insecure" env:"SATCHEL_S3_INSECURE"`
+}
+
+// Completeness configures how complete employees' profiles are scored.
+// Each of Rules names a rule (name, position, photo, bio or reflections)
+// and its weight, such as bio=30. Profiles scoring less than Threshold
+// percent are reported as incomplete, and an employee is reminded about
+// theirs at most once every ReminderInterval.
+type Completeness struct {
+	Rules            []string      `yaml:"rules" env:"SATCHEL_COMPLETENESS_RULES"`
+	Threshold        int           `yaml:"threshold" env:"SATCHEL_COMPLETENESS_THRESHOLD"`
+	ReminderInterval time.Duration `yaml:"reminderInterval" env:"SATCHEL_COMPLETENESS_REMINDER_INTERVAL"`
+}
+
+// Mail configures the SMTP server emails, such as reminders to complete
+// a profile, are sent through. Emails are logged instead of sent while
+// Host is empty.
+type Mail struct {
+	Host     string `yaml:"host" env:"SATCHEL_SMTP_HOST"`
+	Port     int    `yaml:"port" env:"SATCHEL_SMTP_PORT"`
+	Username string `yaml:"username" env:"SATCHEL_SMTP_USERNAME" secret:"true"`
+	Password string `yaml:"password" env:"SATCHEL_SMTP_PASSWORD" secret:"true"`
+	From     string `yaml:"from" env:"SATCHEL_MAIL_FROM"`
+}
+
+type OAuth struct {
+	ClientID     string `yaml:"clientId" env:"GOOGLE_OAUTH_CLIENT_ID" secret:"true"`
+	ClientSecret string `yaml:"clientSecret" env:"GOOGLE_OAUTH_CLIENT_SECRET" secret:"true"`
+	CallbackURL  string `yaml:"callbackUrl" env:"GOOGLE_OAUTH_CALLBACK_URL" secret:"true"`
+}
+
+// Tracing selects where OpenTelemetry spans are exported. Exporter is
+// one of "none", "otlp" or "stdout". The stdout exporter writes to File
+// instead of standard output when File is set.
+type Tracing struct {
+	Exporter     string  `yaml:"exporter" env:"SATCHEL_TRACING_EXPORTER"`
+	ServiceName  string  `yaml:"serviceName" env:"OTEL_SERVICE_NAME"`
+	SampleRatio  float64 `yaml:"sampleRatio" env:"SATCHEL_TRACING_SAMPLE_RATIO"`
+	OTLPEndpoint string  `yaml:"otlpEndpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OTLPProtocol string  `yaml:"otlpProtocol" env:"OTEL_EXPORTER_OTLP_PROTOCOL"`
+	OTLPInsecure bool    `yaml:"otlpInsecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	File         string  `yaml:"file" env:"SATCHEL_TRACING_FILE"`
+}
+
+// Logging selects the log output. Format is "text" or "json" and Level
+// is one of debug, info, warn or error.
+type Logging struct {
+	Format string `yaml:"format" env:"SATCHEL_LOG_FORMAT"`
+	Level  string `yaml:"level" env:"SATCHEL_LOG_LEVEL"`
+}
+
+// developmentSessionSecret is the default session secret. It lets the
+// application run locally without any configuration but is rejected by
+// Validate in release mode and in production builds.
+const developmentSessionSecret = "dev-secret-don't-use-in-prod"
+
+// Default returns the configuration used before any file, environment
+// variable or secret has been applied.
+func Default() *Config {
+	return &Config{
+		HTTP: HTTP{
+			Port:              "8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+		},
+		Auth: Auth{
+			SessionSecret:          developmentSessionSecret,
+			AllowedDomains:         []string{"objectcomputing.com"},
+			SessionIdleTimeout:     24 * time.Hour,
+			SessionAbsoluteTimeout: 7 * 24 * time.Hour,
+			StatusCacheTTL:         30 * time.Second,
+			Google: OAuth{
+				CallbackURL: "http://localhost:8080/auth/google/callback",
+			},
+		},
+		Photos: Photos{
+			Storage:            "local",
+			Directory:          "data/photos",
+			MaxUploadBytes:     10 << 20,
+			AvatarHosts:        []string{"googleusercontent.com"},
+			AvatarFetchTimeout: 5 * time.Second,
+		},
+		Completeness: Completeness{
+			Rules:            []string{"name=10", "position=15", "photo=20", "bio=30", "reflections=25"},
+			Threshold:        80,
+			ReminderInterval: 7 * 24 * time.Hour,
+		},
+		Mail: Mail{
+			Port: 587,
+		},
+		Tracing: Tracing{
+			Exporter:     "none",
+			ServiceName:  "satchel",
+			SampleRatio:  1,
+			OTLPProtocol: "grpc",
+		},
+		Logging: Logging{
+			Format: "text",
+			Level:  "info",
+		},
+	}
+}
+
+const minimumTokenLength = 32
+
+// Validate reports every missing or inconsistent value at once so that
+// a misconfigured deployment can be fixed in a single pass.
+func (c *Config) Validate() error {
+	var problems []error
+	required := func(value string, name string) {
+		if value == "" {
+			problems = append(problems, fmt.Errorf("%s must be set", name))
+		}
+	}
+
+	required(c.Database.User, "SATCHEL_DB_USER")
+	required(c.Database.Password, "SATCHEL_DB_PASSWORD")
+	required(c.Database.Name, "SATCHEL_DB_NAME")
+	required(c.Database.Host, "SATCHEL_DB_HOST")
+	required(c.Database.Port, "SATCHEL_DB_PORT")
+	required(c.Auth.SessionSecret, "SATCHEL_SESSION_SECRET")
+	if c.Auth.SessionSecret == developmentSessionSecret && (c.GinMode == "release" || productionBuild) {
+		problems = append(problems, errors.New("SATCHEL_SESSION_SECRET must be changed from the development default"))
+	}
+
+	if c.HTTP.ListenAddress() == ":" {
+		problems = append(problems, errors.New("SATCHEL_HTTP_ADDRESS or PORT must be set"))
+	}
+	if (c.HTTP.TLSCertFile == "") != (c.HTTP.TLSKeyFile == "") {
+		problems = append(problems, errors.New("SATCHEL_TLS_CERT_FILE and SATCHEL_TLS_KEY_FILE must be set together"))
+	}
+	if c.HTTP.PublicURL != "" {
+		if u, err := url.Parse(c.HTTP.PublicURL); err != nil || !u.IsAbs() || u.Host == "" {
+			problems = append(problems, fmt.Errorf("SATCHEL_PUBLIC_URL must be an absolute URL but was %q", c.HTTP.PublicURL))
+		}
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		problems = append(problems, errors.New("SATCHEL_HTTP_SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if c.SCIM.Token != "" && len(c.SCIM.Token) < minimumTokenLength {
+		problems = append(problems, fmt.Errorf("SATCHEL_SCIM_TOKEN must be at least %d characters", minimumTokenLength))
+	}
+	if c.Metrics.Token != "" && len(c.Metrics.Token) < minimumTokenLength {
+		problems = append(problems, fmt.Errorf("SATCHEL_METRICS_TOKEN must be at least %d characters", minimumTokenLength))
+	}
+	if len(c.Auth.AllowedDomains) == 0 {
+		problems = append(problems, errors.New("SATCHEL_ALLOWED_DOMAINS must list at least one domain"))
+	}
+	if c.Auth.SessionIdleTimeout < 0 || c.Auth.SessionAbsoluteTimeout < 0 || c.Auth.StatusCacheTTL < 0 {
+		problems = append(problems, errors.New("SATCHEL_SESSION_IDLE_TIMEOUT, SATCHEL_SESSION_ABSOLUTE_TIMEOUT and SATCHEL_AUTH_STATUS_CACHE_TTL cannot be negative"))
+	}
+	if c.Auth.SessionIdleTimeout > 0 && c.Auth.SessionAbsoluteTimeout > 0 && c.Auth.SessionIdleTimeout > c.Auth.SessionAbsoluteTimeout {
+		problems = append(problems, errors.New("SATCHEL_SESSION_IDLE_TIMEOUT cannot be longer than SATCHEL_SESSION_ABSOLUTE_TIMEOUT"))
+	}
+
+	switch c.Photos.Storage {
+	case "local":
+		required(c.Photos.Directory, "SATCHEL_PHOTO_DIRECTORY")
+	case "s3":
+		required(c.Photos.S3.Endpoint, "SATCHEL_S3_ENDPOINT")
+		required(c.Photos.S3.Bucket, "SATCHEL_S3_BUCKET")
+	default:
+		problems = append(problems, fmt.Errorf("SATCHEL_PHOTO_STORAGE must be local or s3 but was %q", c.Photos.Storage))
+	}
+	if c.Photos.MaxUploadBytes <= 0 {
+		problems = append(problems, errors.New("SATCHEL_PHOTO_MAX_UPLOAD_BYTES must be positive"))
+	}
+	if c.Photos.AvatarFetchTimeout <= 0 {
+		problems = append(problems, errors.New("SATCHEL_AVATAR_FETCH_TIMEOUT must be positive"))
+	}
+
+	if len(c.Completeness.Rules) == 0 {
+		problems = append(problems, errors.New("SATCHEL_COMPLETENESS_RULES must list at least one rule"))
+	}
+	for _, rule := range c.Completeness.Rules {
+		if _, err := model.ParseCompletenessRule(rule); err != nil {
+			problems = append(problems, fmt.Errorf("SATCHEL_COMPLETENESS_RULES must be rules such as bio=30 but had %q: %w", rule, err))
+		}
+	}
+	if c.Completeness.Threshold < 1 || c.Completeness.Threshold > 100 {
+		problems = append(problems, errors.New("SATCHEL_COMPLETENESS_THRESHOLD must be between 1 and 100"))
+	}
+	if c.Completeness.ReminderInterval < 0 {
+		problems = append(problems, errors.New("SATCHEL_COMPLETENESS_REMINDER_INTERVAL cannot be negative"))
+	}
+	if c.Mail.Host != "" {
+		if _, err := mail.ParseAddress(c.Mail.From); err != nil {
+			problems = append(problems, fmt.Errorf("SATCHEL_MAIL_FROM must be an email address when SATCHEL_SMTP_HOST is set but was %q", c.Mail.From))
+		}
+		if c.Mail.Port <= 0 || c.Mail.Port > 65535 {
+			problems = append(problems, errors.New("SATCHEL_SMTP_PORT must be a port number"))
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	default:
+		problems = append(problems, fmt.Errorf("SATCHEL_TRACING_EXPORTER must be none, otlp or stdout but was %q", c.Tracing.Exporter))
+	}
+	switch c.Tracing.OTLPProtocol {
+	case "grpc", "http/protobuf":
+	default:
+		problems = append(problems, fmt.Errorf("OTEL_EXPORTER_OTLP_PROTOCOL must be grpc or http/protobuf but was %q", c.Tracing.OTLPProtocol))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, errors.New("SATCHEL_TRACING_SAMPLE_RATIO must be between 0 and 1"))
+	}
+
+	switch strings.ToLower(c.Logging.Format) {
+	case "text", "json":
+	default:
+		problems = append(problems, fmt.Errorf("SATCHEL_LOG_FORMAT must be text or json but was %q", c.Logging.Format))
+	}
+	switch strings.ToLower(c.Logging.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Errorf("SATCHEL_LOG_LEVEL must be debug, info, warn or error but was %q", c.Logging.Level))
+	}
+
+	return errors.Join(problems...)
+}
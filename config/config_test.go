@@ -0,0 +1,266 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func environment(values map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, found := values[name]
+		return value, found
+	}
+}
+
+func noSecrets(string) string {
+	return ""
+}
+
+func writeFile(t *testing.T, name string, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+var completeDatabaseEnvironment = map[string]string{
+	"SATCHEL_DB_USER":     "satchel",
+	"SATCHEL_DB_PASSWORD": "secret",
+	"SATCHEL_DB_NAME":     "satcheldb",
+	"SATCHEL_DB_HOST":     "localhost",
+	"SATCHEL_DB_PORT":     "5432",
+}
+
+func TestLoad_ReportsEveryMissingValue(t *testing.T) {
+	cfg, err := load("", environment(nil), noSecrets)
+
+	assert.NotNil(t, cfg)
+	assert.Error(t, err)
+	for _, name := range []string{"SATCHEL_DB_USER", "SATCHEL_DB_PASSWORD", "SATCHEL_DB_NAME", "SATCHEL_DB_HOST", "SATCHEL_DB_PORT"} {
+		assert.Contains(t, err.Error(), name+" must be set")
+	}
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load("", environment(completeDatabaseEnvironment), noSecrets)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.HTTP.ListenAddress())
+	assert.Equal(t, 20*time.Second, cfg.HTTP.ShutdownTimeout)
+	assert.Equal(t, []string{"objectcomputing.com"}, cfg.Auth.AllowedDomains)
+	assert.Equal(t, 24*time.Hour, cfg.Auth.SessionIdleTimeout)
+	assert.Equal(t, 7*24*time.Hour, cfg.Auth.SessionAbsoluteTimeout)
+	assert.False(t, cfg.HTTP.TLSEnabled())
+	assert.Equal(t, "user=satchel password=secret dbname=satcheldb host=localhost port=5432 sslmode=disable", cfg.Database.ConnectionString())
+}
+
+func TestLoad_DevelopmentSessionSecretInReleaseMode(t *testing.T) {
+	env := map[string]string{"GIN_MODE": "release"}
+	for name, value := range completeDatabaseEnvironment {
+		env[name] = value
+	}
+
+	_, err := load("", environment(env), noSecrets)
+	assert.ErrorContains(t, err, "SATCHEL_SESSION_SECRET must be changed from the development default")
+
+	env["SATCHEL_SESSION_SECRET"] = "a-real-secret"
+	_, err = load("", environment(env), noSecrets)
+	assert.NoError(t, err)
+}
+
+func TestLoad_YAMLFile(t *testing.T) {
+	path := writeFile(t, "satchel.yaml", `
+http:
+  address: 127.0.0.1:9000
+  readTimeout: 3s
+  maxHeaderBytes: 4096
+database:
+  user: fileuser
+  password: filepass
+  name: filedb
+  host: db.internal
+  port: 6543
+auth:
+  allowedDomains:
+    - example.com
+    - example.org
+`)
+
+	cfg, err := load(path, environment(nil), noSecrets)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:9000", cfg.HTTP.ListenAddress())
+	assert.Equal(t, 3*time.Second, cfg.HTTP.ReadTimeout)
+	assert.Equal(t, 4096, cfg.HTTP.MaxHeaderBytes)
+	assert.Equal(t, "6543", cfg.Database.Port)
+	assert.Equal(t, []string{"example.com", "example.org"}, cfg.Auth.AllowedDomains)
+}
+
+func TestLoad_TOMLFile(t *testing.T) {
+	path := writeFile(t, "satchel.toml", `
+[http]
+port = "9999"
+idleTimeout = "1m"
+
+[database]
+user = "fileuser"
+password = "filepass"
+name = "filedb"
+host = "db.internal"
+port = 6543
+`)
+
+	cfg, err := load(path, environment(nil), noSecrets)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ":9999", cfg.HTTP.ListenAddress())
+	assert.Equal(t, time.Minute, cfg.HTTP.IdleTimeout)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "satchel.yaml", `
+http:
+  port: "7000"
+database:
+  user: fileuser
+  host: filehost
+  name: filedb
+`)
+	env := map[string]string{
+		"PORT":                "7100",
+		"SATCHEL_DB_USER":     "envuser",
+		"SATCHEL_DB_HOST":     "envhost",
+		"SATCHEL_DB_PASSWORD": "envpass",
+		"SATCHEL_DB_PORT":     "5432",
+	}
+	secrets := func(name string) string {
+		if name == "SATCHEL_DB_USER" {
+			return "secretuser"
+		}
+		return ""
+	}
+
+	cfg, err := load(path, environment(env), secrets)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ":7100", cfg.HTTP.ListenAddress(), "Environment should override the file")
+	assert.Equal(t, "filedb", cfg.Database.Name, "File should override the defaults")
+	assert.Equal(t, "envhost", cfg.Database.Host, "Environment should override the file")
+	assert.Equal(t, "secretuser", cfg.Database.User, "Secret manager should override the environment")
+}
+
+func TestLoad_InvalidValuesAreReportedTogether(t *testing.T) {
+	env := map[string]string{
+		"SATCHEL_HTTP_READ_TIMEOUT":     "soon",
+		"SATCHEL_HTTP_MAX_HEADER_BYTES": "lots",
+		"SATCHEL_TLS_CERT_FILE":         "/certs/tls.crt",
+		"SATCHEL_PUBLIC_URL":            "satchel.example.com",
+		"SATCHEL_SCIM_TOKEN":            "short",
+		"SATCHEL_SESSION_IDLE_TIMEOUT":  "720h",
+	}
+
+	_, err := load("", environment(env), noSecrets)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "SATCHEL_HTTP_READ_TIMEOUT")
+	assert.Contains(t, err.Error(), "SATCHEL_HTTP_MAX_HEADER_BYTES")
+	assert.Contains(t, err.Error(), "SATCHEL_TLS_CERT_FILE and SATCHEL_TLS_KEY_FILE must be set together")
+	assert.Contains(t, err.Error(), `SATCHEL_PUBLIC_URL must be an absolute URL but was "satchel.example.com"`)
+	assert.Contains(t, err.Error(), "SATCHEL_SCIM_TOKEN must be at least 32 characters")
+	assert.Contains(t, err.Error(), "SATCHEL_SESSION_IDLE_TIMEOUT cannot be longer than SATCHEL_SESSION_ABSOLUTE_TIMEOUT")
+	assert.Contains(t, err.Error(), "SATCHEL_DB_USER must be set")
+}
+
+func TestLoad_Tracing(t *testing.T) {
+	env := map[string]string{
+		"SATCHEL_TRACING_EXPORTER":     "otlp",
+		"SATCHEL_TRACING_SAMPLE_RATIO": "0.25",
+		"OTEL_EXPORTER_OTLP_PROTOCOL":  "http/protobuf",
+		"OTEL_EXPORTER_OTLP_INSECURE":  "true",
+	}
+	for name, value := range completeDatabaseEnvironment {
+		env[name] = value
+	}
+
+	cfg, err := load("", environment(env), noSecrets)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "otlp", cfg.Tracing.Exporter)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+	assert.Equal(t, "http/protobuf", cfg.Tracing.OTLPProtocol)
+	assert.True(t, cfg.Tracing.OTLPInsecure)
+
+	env["SATCHEL_TRACING_EXPORTER"] = "zipkin"
+	_, err = load("", environment(env), noSecrets)
+	assert.ErrorContains(t, err, "SATCHEL_TRACING_EXPORTER must be none, otlp or stdout")
+}
+
+func TestLoad_Photos(t *testing.T) {
+	env := map[string]string{
+		"SATCHEL_PHOTO_STORAGE":        "s3",
+		"SATCHEL_S3_ENDPOINT":          "localhost:9000",
+		"SATCHEL_S3_INSECURE":          "true",
+		"SATCHEL_S3_ACCESS_KEY_ID":     "minioadmin",
+		"SATCHEL_S3_SECRET_ACCESS_KEY": "minioadmin",
+	}
+	for name, value := range completeDatabaseEnvironment {
+		env[name] = value
+	}
+
+	_, err := load("", environment(env), noSecrets)
+	assert.ErrorContains(t, err, "SATCHEL_S3_BUCKET must be set")
+
+	env["SATCHEL_S3_BUCKET"] = "photos"
+	cfg, err := load("", environment(env), noSecrets)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3", cfg.Photos.Storage)
+	assert.True(t, cfg.Photos.S3.Insecure)
+	assert.Equal(t, 10<<20, cfg.Photos.MaxUploadBytes)
+	assert.Equal(t, []string{"googleusercontent.com"}, cfg.Photos.AvatarHosts)
+
+	env["SATCHEL_PHOTO_STORAGE"] = "ftp"
+	_, err = load("", environment(env), noSecrets)
+	assert.ErrorContains(t, err, "SATCHEL_PHOTO_STORAGE must be local or s3")
+}
+
+func TestLoad_CompletenessAndMail(t *testing.T) {
+	env := map[string]string{
+		"SATCHEL_COMPLETENESS_RULES":     "bio=50, photo=50",
+		"SATCHEL_COMPLETENESS_THRESHOLD": "60",
+		"SATCHEL_SMTP_HOST":              "smtp.example.com",
+	}
+	for name, value := range completeDatabaseEnvironment {
+		env[name] = value
+	}
+
+	_, err := load("", environment(env), noSecrets)
+	assert.ErrorContains(t, err, "SATCHEL_MAIL_FROM must be an email address")
+
+	env["SATCHEL_MAIL_FROM"] = "Satchel <satchel@example.com>"
+	cfg, err := load("", environment(env), noSecrets)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bio=50", "photo=50"}, cfg.Completeness.Rules)
+	assert.Equal(t, 60, cfg.Completeness.Threshold)
+	assert.Equal(t, 7*24*time.Hour, cfg.Completeness.ReminderInterval)
+	assert.Equal(t, 587, cfg.Mail.Port)
+
+	env["SATCHEL_COMPLETENESS_RULES"] = "bio=lots,photo,pets=10"
+	env["SATCHEL_COMPLETENESS_THRESHOLD"] = "0"
+	_, err = load("", environment(env), noSecrets)
+	assert.ErrorContains(t, err, `but had "bio=lots": completeness rule "bio" needs a positive weight`)
+	assert.ErrorContains(t, err, `but had "photo": completeness rule "photo" needs a positive weight`)
+	assert.ErrorContains(t, err, `but had "pets=10": unknown completeness rule "pets"`)
+	assert.ErrorContains(t, err, "SATCHEL_COMPLETENESS_THRESHOLD must be between 1 and 100")
+}
+
+func TestLoad_UnsupportedFileType(t *testing.T) {
+	path := writeFile(t, "satchel.ini", "port=1")
+
+	_, err := load(path, environment(nil), noSecrets)
+
+	assert.ErrorContains(t, err, "unsupported configuration file type")
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/utils"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFiles are tried in order when SATCHEL_CONFIG_FILE is not set.
+var defaultFiles = []string{"satchel.yaml", "satchel.yml", "satchel.toml"}
+
+// Load builds the configuration from, in increasing order of
+// precedence, Default, the configuration file named by
+// SATCHEL_CONFIG_FILE (or the first of defaultFiles which exists), the
+// environment and the secret manager. A .env file is loaded into the
+// environment first when one is present.
+//
+// The returned Config is populated even when an error is returned so
+// that callers may report on what was resolved. The error joins every
+// problem found rather than stopping at the first.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil {
+		slog.Info("Could not load .env file, proceeding without it", slog.Any("error", err))
+	}
+	return load(configFilePath(), os.LookupEnv, utils.RetrieveSecretValue)
+}
+
+func configFilePath() string {
+	if path := os.Getenv("SATCHEL_CONFIG_FILE"); path != "" {
+		return path
+	}
+	for _, candidate := range defaultFiles {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func load(path string, lookupEnv func(string) (string, bool), lookupSecret func(string) string) (*Config, error) {
+	cfg := Default()
+	var problems []error
+
+	if path != "" {
+		if err := applyFile(cfg, path); err != nil {
+			return cfg, fmt.Errorf("loading %s: %w", path, err)
+		}
+		slog.Info("Loaded configuration file", slog.String("path", path))
+	}
+	problems = append(problems, applyEnvironment(cfg, lookupEnv)...)
+	applySecrets(cfg, lookupSecret)
+
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, err)
+	}
+	return cfg, errors.Join(problems...)
+}
+
+func applyFile(cfg *Config, path string) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(contents, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(contents, &values)
+	default:
+		err = fmt.Errorf("unsupported configuration file type %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return err
+	}
+
+	var problems []error
+	visitFields(reflect.ValueOf(cfg).Elem(), "", func(field reflect.StructField, value reflect.Value, path string) {
+		raw, found := lookupPath(values, path)
+		if !found {
+			return
+		}
+		if err := setFromFile(value, raw); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", path, err))
+		}
+	})
+	return errors.Join(problems...)
+}
+
+func applyEnvironment(cfg *Config, lookupEnv func(string) (string, bool)) []error {
+	var problems []error
+	visitFields(reflect.ValueOf(cfg).Elem(), "", func(field reflect.StructField, value reflect.Value, path string) {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return
+		}
+		raw, found := lookupEnv(name)
+		if !found || raw == "" {
+			return
+		}
+		if err := setFromString(value, raw); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+	return problems
+}
+
+func applySecrets(cfg *Config, lookupSecret func(string) string) {
+	visitFields(reflect.ValueOf(cfg).Elem(), "", func(field reflect.StructField, value reflect.Value, path string) {
+		name := field.Tag.Get("env")
+		if field.Tag.Get("secret") != "true" || name == "" {
+			return
+		}
+		if secret := lookupSecret(name); secret != "" {
+			value.SetString(secret)
+		}
+	})
+}
+
+// visitFields calls visit for every leaf field of the struct v,
+// passing the dotted path of yaml tag names which addresses it.
+func visitFields(v reflect.Value, prefix string, visit func(reflect.StructField, reflect.Value, string)) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		path := field.Tag.Get("yaml")
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if field.Type.Kind() == reflect.Struct {
+			visitFields(v.Field(i), path, visit)
+			continue
+		}
+		visit(field, v.Field(i), path)
+	}
+}
+
+func lookupPath(values map[string]any, path string) (any, bool) {
+	var current any = values
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func setFromFile(value reflect.Value, raw any) error {
+	if list, ok := raw.([]any); ok && value.Kind() == reflect.Slice {
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			items = append(items, fmt.Sprint(item))
+		}
+		value.Set(reflect.ValueOf(items))
+		return nil
+	}
+	return setFromString(value, fmt.Sprint(raw))
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setFromString(value reflect.Value, raw string) error {
+	switch {
+	case value.Type() == durationType:
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+	case value.Kind() == reflect.String:
+		value.SetString(raw)
+	case value.Kind() == reflect.Int:
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(number))
+	case value.Kind() == reflect.Float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(number)
+	case value.Kind() == reflect.Bool:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(flag)
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported configuration type %s", value.Type())
+	}
+	return nil
+}
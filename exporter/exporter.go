@@ -0,0 +1,178 @@
+// Package exporter writes the employee directory out as CSV, JSON or
+// vCard. CSV and JSON exports can be read back by the importer package.
+package exporter
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jeffscottbrown/satchel/importer"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+)
+
+// Formats understood by Write.
+const (
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+	FormatVCard = "vcard"
+)
+
+// ContentType returns the media type and file extension for format.
+func ContentType(format string) (string, string) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", ".csv"
+	case FormatJSON:
+		return "application/json; charset=utf-8", ".json"
+	case FormatVCard:
+		return "text/vcard; charset=utf-8", ".vcf"
+	}
+	return "", ""
+}
+
+// Filter selects the employees to export. Blank fields match everyone.
+type Filter struct {
+	// Email selects a single employee.
+	Email string
+	// Query matches, ignoring case, part of an employee's name, email
+	// address, position or bio.
+	Query string
+	// Position matches an employee's position exactly, ignoring case.
+	Position string
+	// ManagerEmail matches the employees who report to a manager.
+	ManagerEmail string
+}
+
+func (f Filter) matches(employee *model.Employee) bool {
+	if f.Email != "" && !strings.EqualFold(f.Email, employee.Email) {
+		return false
+	}
+	if f.Position != "" && !strings.EqualFold(f.Position, employee.Position) {
+		return false
+	}
+	if f.ManagerEmail != "" && !strings.EqualFold(f.ManagerEmail, employee.ManagerEmail) {
+		return false
+	}
+	if f.Query == "" {
+		return true
+	}
+	query := strings.ToLower(f.Query)
+	for _, field := range []string{employee.Name, employee.Email, employee.Position, employee.BioText} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// Employees returns the active employees selected by filter, with their
+// reflections loaded.
+func Employees(ctx context.Context, filter Filter) ([]*model.Employee, error) {
+	all, err := repository.GetEmployeesWithReflections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var employees []*model.Employee
+	for i := range all {
+		if filter.matches(&all[i]) {
+			employees = append(employees, &all[i])
+		}
+	}
+	return employees, nil
+}
+
+// Write writes employees to w in the given format. baseURL is used to
+// make relative photo URLs absolute in vCards, which embed uploaded
+// photos and cached avatars read through ctx.
+func Write(ctx context.Context, w io.Writer, format string, employees []*model.Employee, baseURL string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, employees)
+	case FormatJSON:
+		return WriteJSON(w, employees)
+	case FormatVCard:
+		for _, employee := range employees {
+			if err := WriteVCard(ctx, w, employee, baseURL); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported export format %q", format)
+}
+
+// csvHeader uses column names which the importer recognises.
+var csvHeader = []string{"Email", "Name", "First Name", "Last Name", "Position", "Manager", "Start Date"}
+
+// WriteCSV writes one row per employee after a header row.
+func WriteCSV(w io.Writer, employees []*model.Employee) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, employee := range employees {
+		startDate := ""
+		if employee.StartDate != nil {
+			startDate = employee.StartDate.UTC().Format("2006-01-02")
+		}
+		row := []string{
+			csvCell(employee.Email),
+			csvCell(employee.Name),
+			csvCell(employee.FirstName),
+			csvCell(employee.LastName),
+			csvCell(employee.Position),
+			csvCell(employee.ManagerEmail),
+			startDate,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvCell quotes value with an apostrophe when it starts with a
+// character which would make a spreadsheet treat it as a formula, since
+// employees write their own names and positions.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// WriteJSON writes employees, including their bios and reflections, as
+// an indented JSON array. The bio is written both as Markdown, which the
+// importer reads back, and as plain text.
+func WriteJSON(w io.Writer, employees []*model.Employee) error {
+	records := make([]importer.JSONRecord, 0, len(employees))
+	for _, employee := range employees {
+		record := importer.JSONRecord{
+			Email:        employee.Email,
+			Name:         employee.Name,
+			FirstName:    employee.FirstName,
+			LastName:     employee.LastName,
+			Position:     employee.Position,
+			ManagerEmail: employee.ManagerEmail,
+			ImageName:    employee.ImageName,
+			Bio:          employee.Bio,
+			BioText:      employee.BioText,
+		}
+		if employee.StartDate != nil {
+			record.StartDate = employee.StartDate.UTC().Format("2006-01-02")
+		}
+		for _, reflection := range employee.Reflections {
+			record.Reflections = append(record.Reflections, importer.Reflection{Key: reflection.Key, Value: reflection.Value})
+		}
+		records = append(records, record)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
@@ -0,0 +1,198 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/importer"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/photos"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEmployees() []*model.Employee {
+	startDate := time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC)
+	jane := &model.Employee{
+		Email:        "jane.doe@objectcomputing.com",
+		Name:         "Jane Doe",
+		FirstName:    "Jane",
+		LastName:     "Doe",
+		Position:     "Engineer, Platform",
+		ManagerEmail: "john.roe@objectcomputing.com",
+		StartDate:    &startDate,
+		ImageName:    "/static/images/jane.jpg",
+		Bio:          "Likes *\"quotes\"*",
+		BioText:      "Likes \"quotes\"",
+	}
+	jane.AddReflection("Home", "St. Louis")
+	john := &model.Employee{Email: "john.roe@objectcomputing.com", Name: "John Roe", Position: "Director"}
+	return []*model.Employee{jane, john}
+}
+
+func TestWriteCSV_CanBeImported(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteCSV(&buffer, testEmployees()))
+
+	assert.True(t, strings.HasPrefix(buffer.String(), "Email,Name,First Name,Last Name,Position,Manager,Start Date\n"))
+
+	records, err := importer.ParseCSV(&buffer)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "jane.doe@objectcomputing.com", records[0].Email)
+	assert.Equal(t, "Engineer, Platform", records[0].Position)
+	assert.Equal(t, "john.roe@objectcomputing.com", records[0].ManagerEmail)
+	assert.Equal(t, "2024-03-18", records[0].StartDate.Format("2006-01-02"))
+	assert.Nil(t, records[1].StartDate)
+}
+
+func TestWriteCSV_QuotesFormulas(t *testing.T) {
+	employee := &model.Employee{
+		Email:     "mallory@objectcomputing.com",
+		Name:      `=HYPERLINK("https://evil.example.com","Click me")`,
+		FirstName: "+1",
+		LastName:  "-Smith",
+		Position:  "@SUM(A1)",
+	}
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteCSV(&buffer, []*model.Employee{employee}))
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mallory@objectcomputing.com", `'=HYPERLINK("https://evil.example.com","Click me")`, "'+1", "'-Smith", "'@SUM(A1)", "", ""}, rows[1])
+	assert.Equal(t, "'\tTabbed", csvCell("\tTabbed"))
+	assert.Equal(t, "'\rReturned", csvCell("\rReturned"))
+	assert.Equal(t, "Jane", csvCell("Jane"))
+}
+
+func TestWriteJSON_CanBeImported(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteJSON(&buffer, testEmployees()))
+
+	assert.Contains(t, buffer.String(), `"bioText": "Likes \"quotes\""`)
+	records, err := importer.ParseJSON(&buffer)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "Likes *\"quotes\"*", records[0].Bio)
+	assert.Equal(t, "/static/images/jane.jpg", records[0].ImageName)
+	assert.Equal(t, []importer.Reflection{{Key: "Home", Value: "St. Louis"}}, records[0].Reflections)
+}
+
+func TestWriteJSON_Empty(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteJSON(&buffer, nil))
+	assert.Equal(t, "[]\n", buffer.String())
+}
+
+func TestWriteVCard(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteVCard(t.Context(), &buffer, testEmployees()[0], "https://satchel.example.com"))
+
+	assert.Equal(t, "BEGIN:VCARD\r\n"+
+		"VERSION:3.0\r\n"+
+		"N:Doe;Jane;;;\r\n"+
+		"FN:Jane Doe\r\n"+
+		"EMAIL;TYPE=INTERNET,WORK:jane.doe@objectcomputing.com\r\n"+
+		"TITLE:Engineer\\, Platform\r\n"+
+		"NOTE:Likes \"quotes\"\r\n"+
+		"PHOTO;VALUE=URI:https://satchel.example.com/static/images/jane.jpg\r\n"+
+		"END:VCARD\r\n", buffer.String())
+}
+
+func TestWriteVCard_Photos(t *testing.T) {
+	photos.ConfigureStorageForTest(t)
+	employee := &model.Employee{Email: "someone@objectcomputing.com", ImageName: "/static/images/someone.jpg"}
+
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteVCard(t.Context(), &buffer, employee, ""))
+	assert.NotContains(t, buffer.String(), "PHOTO", "Relative photos cannot be used without a base URL")
+	assert.Contains(t, buffer.String(), "FN:someone@objectcomputing.com")
+
+	employee.ImageName = "https://lh3.googleusercontent.com/a/photo"
+	buffer.Reset()
+	assert.NoError(t, WriteVCard(t.Context(), &buffer, employee, "https://satchel.example.com"))
+	assert.NotContains(t, buffer.String(), "PHOTO", "Avatars should not be linked on the identity provider's site")
+
+	employee.Avatar = savePhoto(t, color.RGBA{B: 255, A: 255})
+	buffer.Reset()
+	assert.NoError(t, WriteVCard(t.Context(), &buffer, employee, "https://satchel.example.com"))
+	assert.Equal(t, storedPhoto(t, employee.Avatar), embeddedPhoto(t, buffer.String()), "A cached avatar should be embedded")
+
+	employee.Photo = savePhoto(t, color.RGBA{R: 255, A: 255})
+	buffer.Reset()
+	assert.NoError(t, WriteVCard(t.Context(), &buffer, employee, "https://satchel.example.com"))
+	assert.Equal(t, storedPhoto(t, employee.Photo), embeddedPhoto(t, buffer.String()), "An uploaded photo should be preferred to the avatar")
+
+	employee.Photo = "0123456789abcdef0123456789abcdef"
+	buffer.Reset()
+	assert.NoError(t, WriteVCard(t.Context(), &buffer, employee, "https://satchel.example.com"))
+	assert.NotContains(t, buffer.String(), "PHOTO", "A photo which is missing from storage should be left out")
+}
+
+// savePhoto stores a small photo of a single colour and returns its key.
+func savePhoto(t *testing.T, colour color.Color) string {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	draw.Draw(img, img.Bounds(), image.NewUniform(colour), image.Point{}, draw.Src)
+	var encoded bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&encoded, img, nil))
+	key, err := photos.Save(t.Context(), encoded.Bytes())
+	assert.NoError(t, err)
+	return key
+}
+
+func storedPhoto(t *testing.T, key string) []byte {
+	reader, err := photos.Open(t.Context(), photos.FileName(key, photos.Full))
+	assert.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return data
+}
+
+// embeddedPhoto decodes the photo embedded in card.
+func embeddedPhoto(t *testing.T, card string) []byte {
+	unfolded := strings.ReplaceAll(card, "\r\n ", "")
+	_, rest, found := strings.Cut(unfolded, "PHOTO;ENCODING=b;TYPE=JPEG:")
+	assert.True(t, found, "The photo should be embedded")
+	encoded, _, _ := strings.Cut(rest, "\r\n")
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestEscapeVCard(t *testing.T) {
+	assert.Equal(t, `one\, two\; three\\four\nfive\nsix\nseven`, escapeVCard("one, two; three\\four\r\nfive\rsix\nseven"))
+}
+
+func TestFoldVCardLine(t *testing.T) {
+	line := "NOTE:" + strings.Repeat("é", 50)
+
+	folded := foldVCardLine(line)
+
+	for _, part := range strings.Split(folded, "\r\n") {
+		assert.LessOrEqual(t, len(part), 75)
+	}
+	assert.Equal(t, line, strings.ReplaceAll(folded, "\r\n ", ""))
+}
+
+func TestFilter(t *testing.T) {
+	jane, john := testEmployees()[0], testEmployees()[1]
+
+	assert.True(t, Filter{}.matches(jane))
+	assert.True(t, Filter{Query: "platform"}.matches(jane))
+	assert.False(t, Filter{Query: "platform"}.matches(john))
+	assert.True(t, Filter{Position: "director"}.matches(john))
+	assert.True(t, Filter{Query: "QUOTES"}.matches(jane), "Bios should be searched as plain text")
+	assert.True(t, Filter{ManagerEmail: "John.Roe@objectcomputing.com"}.matches(jane))
+	assert.False(t, Filter{ManagerEmail: "john.roe@objectcomputing.com"}.matches(john))
+	assert.True(t, Filter{Email: "john.roe@objectcomputing.com"}.matches(john))
+	assert.False(t, Filter{Email: "john.roe@objectcomputing.com"}.matches(jane))
+}
@@ -0,0 +1,138 @@
+package exporter
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/jeffscottbrown/satchel/avatars"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/photos"
+)
+
+// WriteVCard writes employee as a vCard 3.0 contact. Contact apps fetch
+// photo links without a session, so an uploaded photo or cached avatar
+// is embedded in the card rather than linked. Any other image is linked,
+// with a relative URL resolved against baseURL and left out when baseURL
+// is empty.
+func WriteVCard(ctx context.Context, w io.Writer, employee *model.Employee, baseURL string) error {
+	name := employee.Name
+	if name == "" {
+		name = strings.TrimSpace(employee.FirstName + " " + employee.LastName)
+	}
+	if name == "" {
+		name = employee.Email
+	}
+
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"N:" + escapeVCard(employee.LastName) + ";" + escapeVCard(employee.FirstName) + ";;;",
+		"FN:" + escapeVCard(name),
+		"EMAIL;TYPE=INTERNET,WORK:" + escapeVCard(employee.Email),
+	}
+	if employee.Position != "" {
+		lines = append(lines, "TITLE:"+escapeVCard(employee.Position))
+	}
+	if employee.BioText != "" {
+		lines = append(lines, "NOTE:"+escapeVCard(employee.BioText))
+	}
+	photo, err := photoLine(ctx, employee, baseURL)
+	if err != nil {
+		return err
+	}
+	if photo != "" {
+		lines = append(lines, photo)
+	}
+	lines = append(lines, "END:VCARD")
+
+	var card strings.Builder
+	for _, line := range lines {
+		card.WriteString(foldVCardLine(line))
+		card.WriteString("\r\n")
+	}
+	_, err = io.WriteString(w, card.String())
+	return err
+}
+
+// photoLine returns the PHOTO property for employee, or an empty string
+// when they have no photo a contact app could show. An avatar which has
+// not been cached yet is left out rather than linked, so that contact
+// apps are not sent to the identity provider.
+func photoLine(ctx context.Context, employee *model.Employee, baseURL string) (string, error) {
+	key := employee.Photo
+	if key == "" && avatars.Proxied(employee.ImageName) {
+		key = employee.Avatar
+	}
+	if key != "" {
+		reader, err := photos.Open(ctx, photos.FileName(key, photos.Full))
+		if errors.Is(err, photos.ErrNotFound) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return "", err
+		}
+		return "PHOTO;ENCODING=b;TYPE=JPEG:" + base64.StdEncoding.EncodeToString(data), nil
+	}
+	if avatars.Proxied(employee.ImageName) {
+		return "", nil
+	}
+	if photo := photoURL(employee.ImageName, baseURL); photo != "" {
+		return "PHOTO;VALUE=URI:" + photo, nil
+	}
+	return "", nil
+}
+
+func photoURL(imageName string, baseURL string) string {
+	if imageName == "" {
+		return ""
+	}
+	image, err := url.Parse(imageName)
+	if err != nil {
+		return ""
+	}
+	if image.IsAbs() {
+		return image.String()
+	}
+	if baseURL == "" {
+		return ""
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil || !base.IsAbs() {
+		return ""
+	}
+	return base.ResolveReference(image).String()
+}
+
+var vCardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\r", `\n`, "\n", `\n`)
+
+func escapeVCard(value string) string {
+	return vCardEscaper.Replace(value)
+}
+
+// foldVCardLine splits line into lines of at most 75 octets, as vCard
+// requires, without breaking a UTF-8 sequence. Continuation lines start
+// with a space.
+func foldVCardLine(line string) string {
+	const limit = 75
+	var folded strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > limit {
+			folded.WriteString("\r\n ")
+			length = 1
+		}
+		folded.WriteRune(r)
+		length += size
+	}
+	return folded.String()
+}
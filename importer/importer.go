@@ -0,0 +1,227 @@
+// Package importer pre-provisions the employee directory from HR
+// exports, so that new hires have a profile before their first login.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// Action is what an import did, or in a dry run would do, with a record.
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+	ActionConflict  Action = "conflict"
+)
+
+// Outcome describes the result of importing a single record. Changes
+// names the fields an update modifies and Problems explains a conflict.
+type Outcome struct {
+	Line     int
+	Email    string
+	Action   Action
+	Changes  []string
+	Problems []string
+}
+
+// Report lists the outcome of every record in an import.
+type Report struct {
+	DryRun   bool
+	Outcomes []Outcome
+}
+
+// Count returns how many records had the given outcome.
+func (r Report) Count(action Action) int {
+	count := 0
+	for _, outcome := range r.Outcomes {
+		if outcome.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+func (r Report) String() string {
+	verb := "imported"
+	if r.DryRun {
+		verb = "would import"
+	}
+	return fmt.Sprintf("%s %d records: %d created, %d updated, %d unchanged, %d conflicts",
+		verb, len(r.Outcomes), r.Count(ActionCreate), r.Count(ActionUpdate), r.Count(ActionUnchanged), r.Count(ActionConflict))
+}
+
+// Apply creates or updates an employee for each record, matching
+// existing employees by email address, so importing the same file twice
+// changes nothing the second time. Only the fields present in a record
+// are written; blank values never clear what is already in the
+// directory. Records with problems are reported as conflicts and
+// skipped. When dryRun is true nothing is saved.
+func Apply(ctx context.Context, records []Record, dryRun bool) (Report, error) {
+	report := Report{DryRun: dryRun}
+	firstLine := map[string]int{}
+	// managers tracks the reporting lines as the import changes them, so
+	// that a record which would make a cycle is refused.
+	existing, err := repository.GetAllEmployees(ctx)
+	if err != nil {
+		return report, err
+	}
+	managers := model.NewManagers(existing)
+
+	for _, record := range records {
+		outcome := Outcome{Line: record.Line, Email: record.Email}
+		problems := validate(record)
+		if line, seen := firstLine[record.Email]; seen && record.Email != "" {
+			problems = append(problems, fmt.Sprintf("email also appears on line %d", line))
+		} else {
+			firstLine[record.Email] = record.Line
+		}
+
+		var employee *model.Employee
+		if len(problems) == 0 {
+			var err error
+			employee, err = repository.GetEmployeeByEmail(ctx, record.Email)
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				employee = nil
+				if record.Name == "" && record.FirstName == "" && record.LastName == "" {
+					problems = append(problems, "a new employee needs a name")
+				}
+			case err != nil:
+				return report, fmt.Errorf("line %d: %w", record.Line, err)
+			}
+		}
+
+		if len(problems) == 0 && record.ManagerEmail != "" && managers.WouldCycle(record.Email, record.ManagerEmail) {
+			problems = append(problems, fmt.Sprintf("manager %q reports to this employee", record.ManagerEmail))
+		}
+
+		if len(problems) > 0 {
+			outcome.Action = ActionConflict
+			outcome.Problems = problems
+			report.Outcomes = append(report.Outcomes, outcome)
+			continue
+		}
+
+		if employee == nil {
+			outcome.Action = ActionCreate
+			employee = &model.Employee{Email: record.Email}
+			merge(employee, record)
+		} else {
+			outcome.Changes = merge(employee, record)
+			outcome.Action = ActionUpdate
+			if len(outcome.Changes) == 0 {
+				outcome.Action = ActionUnchanged
+			}
+		}
+		report.Outcomes = append(report.Outcomes, outcome)
+		managers.Set(employee.Email, employee.ManagerEmail)
+
+		if dryRun || outcome.Action == ActionUnchanged {
+			continue
+		}
+		if err := repository.SaveEmployee(ctx, employee); err != nil {
+			return report, fmt.Errorf("line %d: %w", record.Line, err)
+		}
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "employee import finished",
+		slog.Bool("dry_run", dryRun),
+		slog.Int("created", report.Count(ActionCreate)),
+		slog.Int("updated", report.Count(ActionUpdate)),
+		slog.Int("unchanged", report.Count(ActionUnchanged)),
+		slog.Int("conflicts", report.Count(ActionConflict)))
+	return report, nil
+}
+
+func validate(record Record) []string {
+	problems := append([]string(nil), record.Problems...)
+	if record.Email == "" {
+		return append(problems, "email is missing")
+	}
+	if _, err := mail.ParseAddress(record.Email); err != nil {
+		problems = append(problems, fmt.Sprintf("email %q is not valid", record.Email))
+	}
+	if record.ManagerEmail != "" {
+		if _, err := mail.ParseAddress(record.ManagerEmail); err != nil {
+			problems = append(problems, fmt.Sprintf("manager %q is not an email address", record.ManagerEmail))
+		} else if record.ManagerEmail == record.Email {
+			problems = append(problems, "employee cannot be their own manager")
+		}
+	}
+	return problems
+}
+
+// merge copies the non-blank fields of record into employee and
+// returns the names of the fields which changed.
+func merge(employee *model.Employee, record Record) []string {
+	var changes []string
+	set := func(field string, target *string, value string) {
+		if value != "" && *target != value {
+			*target = value
+			changes = append(changes, field)
+		}
+	}
+
+	name := record.Name
+	if name == "" && (record.FirstName != "" || record.LastName != "") && employee.Name == "" {
+		name = joinName(record.FirstName, record.LastName)
+	}
+	set("name", &employee.Name, name)
+	set("first name", &employee.FirstName, record.FirstName)
+	set("last name", &employee.LastName, record.LastName)
+	set("position", &employee.Position, record.Position)
+	set("manager", &employee.ManagerEmail, record.ManagerEmail)
+	set("image", &employee.ImageName, record.ImageName)
+	set("bio", &employee.Bio, record.Bio)
+
+	if record.StartDate != nil && (employee.StartDate == nil || !sameDay(*employee.StartDate, *record.StartDate)) {
+		employee.StartDate = record.StartDate
+		changes = append(changes, "start date")
+	}
+
+	added := false
+	for _, reflection := range record.Reflections {
+		if !hasReflection(employee, reflection.Key) {
+			employee.AddReflection(reflection.Key, reflection.Value)
+			added = true
+		}
+	}
+	if added {
+		changes = append(changes, "reflections")
+	}
+	return changes
+}
+
+func joinName(first string, last string) string {
+	if first == "" || last == "" {
+		return first + last
+	}
+	return first + " " + last
+}
+
+func sameDay(a time.Time, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func hasReflection(employee *model.Employee, key string) bool {
+	for _, reflection := range employee.Reflections {
+		if reflection.Key == key {
+			return true
+		}
+	}
+	return false
+}
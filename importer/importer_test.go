@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func deleteEmployees(t *testing.T, emails ...string) {
+	t.Cleanup(func() {
+		for _, email := range emails {
+			repository.DeleteEmployee(context.Background(), email)
+		}
+	})
+}
+
+func TestApply_IsIdempotent(t *testing.T) {
+	deleteEmployees(t, "jane.doe@objectcomputing.com", "john.roe@objectcomputing.com")
+	records, err := ParseCSV(strings.NewReader(`Email,First Name,Last Name,Position,Manager,Start Date
+jane.doe@objectcomputing.com,Jane,Doe,Engineer,john.roe@objectcomputing.com,2024-03-18
+john.roe@objectcomputing.com,John,Roe,Director,,2020-01-06
+`))
+	assert.NoError(t, err)
+
+	report, err := Apply(t.Context(), records, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Count(ActionCreate))
+
+	jane, err := repository.GetEmployeeByEmail(t.Context(), "jane.doe@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane Doe", jane.Name)
+	assert.Equal(t, "Engineer", jane.Position)
+	assert.Equal(t, "john.roe@objectcomputing.com", jane.ManagerEmail)
+	assert.Equal(t, "2024-03-18", jane.StartDate.UTC().Format("2006-01-02"))
+
+	report, err = Apply(t.Context(), records, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Count(ActionUnchanged), "Importing the same file twice should change nothing")
+}
+
+func TestApply_UpdatesOnlyGivenFields(t *testing.T) {
+	deleteEmployees(t, "jane.doe@objectcomputing.com")
+	_, err := Apply(t.Context(), []Record{{Line: 2, Email: "jane.doe@objectcomputing.com", Name: "Jane Doe", Position: "Engineer"}}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, repository.SaveBio(t.Context(), "jane.doe@objectcomputing.com", "Written by Jane"))
+
+	report, err := Apply(t.Context(), []Record{{Line: 2, Email: "jane.doe@objectcomputing.com", Position: "Principal Engineer"}}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ActionUpdate, report.Outcomes[0].Action)
+	assert.Equal(t, []string{"position"}, report.Outcomes[0].Changes)
+	jane, err := repository.GetEmployeeByEmail(t.Context(), "jane.doe@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane Doe", jane.Name, "Blank values should not clear existing ones")
+	assert.Equal(t, "Principal Engineer", jane.Position)
+	assert.Equal(t, "Written by Jane", jane.Bio)
+}
+
+func TestApply_DryRunSavesNothing(t *testing.T) {
+	deleteEmployees(t, "dry.run@objectcomputing.com")
+
+	report, err := Apply(t.Context(), []Record{{Line: 2, Email: "dry.run@objectcomputing.com", Name: "Dry Run"}}, true)
+
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, ActionCreate, report.Outcomes[0].Action)
+	assert.Equal(t, "would import 1 records: 1 created, 0 updated, 0 unchanged, 0 conflicts", report.String())
+	_, err = repository.GetEmployeeByEmail(t.Context(), "dry.run@objectcomputing.com")
+	assert.Error(t, err)
+}
+
+func TestApply_Conflicts(t *testing.T) {
+	deleteEmployees(t, "first@objectcomputing.com")
+
+	report, err := Apply(t.Context(), []Record{
+		{Line: 2, Email: "first@objectcomputing.com", Name: "First"},
+		{Line: 3, Email: "first@objectcomputing.com", Name: "Second"},
+		{Line: 4, Name: "No Email"},
+		{Line: 5, Email: "not an email", Name: "Bad Email"},
+		{Line: 6, Email: "nameless@objectcomputing.com"},
+		{Line: 7, Email: "self@objectcomputing.com", Name: "Self", ManagerEmail: "self@objectcomputing.com"},
+		{Line: 8, Email: "late@objectcomputing.com", Name: "Late", Problems: []string{"bad date"}},
+	}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ActionCreate, report.Outcomes[0].Action)
+	assert.Equal(t, 6, report.Count(ActionConflict))
+	assert.Equal(t, []string{"email also appears on line 2"}, report.Outcomes[1].Problems)
+	assert.Equal(t, []string{"email is missing"}, report.Outcomes[2].Problems)
+	assert.Equal(t, []string{`email "not an email" is not valid`}, report.Outcomes[3].Problems)
+	assert.Equal(t, []string{"a new employee needs a name"}, report.Outcomes[4].Problems)
+	assert.Equal(t, []string{"employee cannot be their own manager"}, report.Outcomes[5].Problems)
+	assert.Equal(t, []string{"bad date"}, report.Outcomes[6].Problems)
+
+	first, err := repository.GetEmployeeByEmail(t.Context(), "first@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "First", first.Name)
+}
+
+func TestApply_ManagerCycles(t *testing.T) {
+	deleteEmployees(t, "boss@objectcomputing.com", "worker@objectcomputing.com", "lead@objectcomputing.com")
+
+	records := []Record{
+		{Line: 2, Email: "boss@objectcomputing.com", Name: "Boss", ManagerEmail: "lead@objectcomputing.com"},
+		{Line: 3, Email: "worker@objectcomputing.com", Name: "Worker", ManagerEmail: "boss@objectcomputing.com"},
+		{Line: 4, Email: "lead@objectcomputing.com", Name: "Lead", ManagerEmail: "worker@objectcomputing.com"},
+	}
+	report, err := Apply(t.Context(), records, true)
+	assert.NoError(t, err)
+	assert.Equal(t, ActionConflict, report.Outcomes[2].Action)
+	assert.Contains(t, report.Outcomes[2].Problems, `manager "worker@objectcomputing.com" reports to this employee`, "Cycles within a file should be found in a dry run")
+
+	_, err = Apply(t.Context(), records[:2], false)
+	assert.NoError(t, err)
+	report, err = Apply(t.Context(), []Record{{Line: 2, Email: "boss@objectcomputing.com", ManagerEmail: "worker@objectcomputing.com"}}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, ActionConflict, report.Outcomes[0].Action, "Cycles with the directory should be found")
+}
+
+func TestMain(m *testing.M) {
+	repository.RunTestsWithTestContainer(m)
+}
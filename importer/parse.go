@@ -0,0 +1,311 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Record is one employee read from an import file. Line is the line, or
+// spreadsheet row, the record came from so that problems can be traced
+// back to the file. Problems lists values which could not be parsed; a
+// record with problems is reported as a conflict rather than imported.
+type Record struct {
+	Line         int
+	Name         string
+	FirstName    string
+	LastName     string
+	Email        string
+	Position     string
+	ManagerEmail string
+	StartDate    *time.Time
+	ImageName    string
+	Bio          string
+	Reflections  []Reflection
+	Problems     []string
+}
+
+type Reflection struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Formats understood by Parse.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+)
+
+// FormatFromFilename returns the format implied by the extension of
+// filename, or an empty string when there is none.
+func FormatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".xlsx":
+		return FormatXLSX
+	case ".json":
+		return FormatJSON
+	}
+	return ""
+}
+
+// Parse reads the records in r, which holds a file in the given format.
+func Parse(format string, r io.Reader) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatXLSX:
+		return ParseXLSX(r)
+	case FormatJSON:
+		return ParseJSON(r)
+	}
+	return nil, fmt.Errorf("unsupported import format %q", format)
+}
+
+// ParseCSV reads an HR export in CSV format. The first row must name
+// the columns; see parseRows for the names which are recognised.
+func ParseCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// The reader skips blank lines, so line numbers are tracked here
+	// rather than derived from row positions.
+	var rows [][]string
+	var lines []int
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+	return parseRows(rows, lines)
+}
+
+// Workbooks are zip archives, so a small upload can unzip to far more
+// than it weighs. An HR export of a few thousand employees unzips to a
+// few megabytes; anything beyond maxUnzippedWorkbookSize is refused, and
+// sheets beyond maxInMemorySheetSize are unzipped to disk, not memory.
+const (
+	maxUnzippedWorkbookSize = 64 << 20
+	maxInMemorySheetSize    = 8 << 20
+)
+
+// ParseXLSX reads an HR export from the first sheet of an Excel workbook.
+func ParseXLSX(r io.Reader) ([]Record, error) {
+	workbook, err := excelize.OpenReader(r, excelize.Options{
+		UnzipSizeLimit:    maxUnzippedWorkbookSize,
+		UnzipXMLSizeLimit: maxInMemorySheetSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer workbook.Close()
+
+	sheets := workbook.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("workbook has no sheets")
+	}
+	// Raw values keep dates as serial numbers rather than in whatever
+	// display format the sheet happens to use.
+	rows, err := workbook.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]int, len(rows))
+	for i := range rows {
+		lines[i] = i + 1
+	}
+	return parseRows(rows, lines)
+}
+
+// JSONRecord is an employee in the JSON format written by the exporter
+// and read back by ParseJSON. StartDate is formatted as 2006-01-02.
+type JSONRecord struct {
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	FirstName    string `json:"firstName"`
+	LastName     string `json:"lastName"`
+	Position     string `json:"position,omitempty"`
+	ManagerEmail string `json:"manager,omitempty"`
+	StartDate    string `json:"startDate,omitempty"`
+	ImageName    string `json:"imageName,omitempty"`
+	Bio          string `json:"bio,omitempty"`
+	// BioText is Bio as plain text, for whoever reads the export. It is
+	// ignored on import, where it is derived from Bio instead.
+	BioText     string       `json:"bioText,omitempty"`
+	Reflections []Reflection `json:"reflections,omitempty"`
+}
+
+// ParseJSON reads a JSON array of employees, as written by export.
+// Line is the position of the employee in the array.
+func ParseJSON(r io.Reader) ([]Record, error) {
+	var decoded []JSONRecord
+	if err := json.NewDecoder(r).Decode(&decoded); err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(decoded))
+	for i, d := range decoded {
+		record := Record{
+			Line:         i + 1,
+			Name:         strings.TrimSpace(d.Name),
+			FirstName:    strings.TrimSpace(d.FirstName),
+			LastName:     strings.TrimSpace(d.LastName),
+			Email:        normalizeEmail(d.Email),
+			Position:     strings.TrimSpace(d.Position),
+			ManagerEmail: normalizeEmail(d.ManagerEmail),
+			ImageName:    strings.TrimSpace(d.ImageName),
+			Bio:          d.Bio,
+			Reflections:  d.Reflections,
+		}
+		record.setStartDate(d.StartDate)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+type column int
+
+const (
+	columnName column = iota
+	columnFirstName
+	columnLastName
+	columnEmail
+	columnPosition
+	columnManager
+	columnStartDate
+)
+
+// headers maps the normalized column headings found in HR exports to
+// the column they hold.
+var headers = map[string]column{
+	"name":          columnName,
+	"full name":     columnName,
+	"display name":  columnName,
+	"first name":    columnFirstName,
+	"firstname":     columnFirstName,
+	"given name":    columnFirstName,
+	"last name":     columnLastName,
+	"lastname":      columnLastName,
+	"surname":       columnLastName,
+	"family name":   columnLastName,
+	"email":         columnEmail,
+	"email address": columnEmail,
+	"work email":    columnEmail,
+	"position":      columnPosition,
+	"title":         columnPosition,
+	"job title":     columnPosition,
+	"manager":       columnManager,
+	"manager email": columnManager,
+	"reports to":    columnManager,
+	"start date":    columnStartDate,
+	"hire date":     columnStartDate,
+}
+
+func normalizeHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(header))
+	header = strings.NewReplacer("_", " ", "-", " ").Replace(header)
+	return strings.Join(strings.Fields(header), " ")
+}
+
+// parseRows turns the rows of a CSV file or spreadsheet, which start on
+// the given lines, into records. Columns with unrecognised headings are
+// ignored and blank rows are skipped.
+func parseRows(rows [][]string, lines []int) ([]Record, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	columns := map[column]int{}
+	for i, header := range rows[0] {
+		if c, ok := headers[normalizeHeader(header)]; ok {
+			if _, duplicate := columns[c]; !duplicate {
+				columns[c] = i
+			}
+		}
+	}
+	if _, ok := columns[columnEmail]; !ok {
+		return nil, errors.New("no email column found in the header row")
+	}
+
+	var records []Record
+	for i := 1; i < len(rows); i++ {
+		row := rows[i]
+		value := func(c column) string {
+			index, ok := columns[c]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		record := Record{
+			Line:         lines[i],
+			Name:         value(columnName),
+			FirstName:    value(columnFirstName),
+			LastName:     value(columnLastName),
+			Email:        normalizeEmail(value(columnEmail)),
+			Position:     value(columnPosition),
+			ManagerEmail: normalizeEmail(value(columnManager)),
+		}
+		record.setStartDate(value(columnStartDate))
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+var dateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"1/2/2006",
+	"2006/01/02",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+func (r *Record) setStartDate(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			date := time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC)
+			r.StartDate = &date
+			return
+		}
+	}
+	// Spreadsheets store dates as the number of days since 1900.
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		if parsed, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			date := time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC)
+			r.StartDate = &date
+			return
+		}
+	}
+	r.Problems = append(r.Problems, fmt.Sprintf("start date %q is not a recognised date", value))
+}
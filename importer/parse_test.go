@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestParseCSV(t *testing.T) {
+	csv := `Full Name,First_Name,Last Name,Work Email,Job Title,Reports To,Hire Date,Cost Centre
+Jane Doe,Jane,Doe, Jane.Doe@ObjectComputing.com ,Engineer,boss@objectcomputing.com,2024-03-18,42
+,,,,,,,
+
+John Roe,John,Roe,john.roe@objectcomputing.com,,,3/4/2024,
+Max Poe,Max,Poe,max.poe@objectcomputing.com,,,someday,
+`
+	records, err := ParseCSV(strings.NewReader(csv))
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 3, "Blank rows should be skipped")
+
+	jane := records[0]
+	assert.Equal(t, 2, jane.Line)
+	assert.Equal(t, "Jane Doe", jane.Name)
+	assert.Equal(t, "Jane", jane.FirstName)
+	assert.Equal(t, "Doe", jane.LastName)
+	assert.Equal(t, "jane.doe@objectcomputing.com", jane.Email)
+	assert.Equal(t, "Engineer", jane.Position)
+	assert.Equal(t, "boss@objectcomputing.com", jane.ManagerEmail)
+	assert.Equal(t, time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC), *jane.StartDate)
+	assert.Empty(t, jane.Problems)
+
+	assert.Equal(t, 5, records[1].Line)
+	assert.Equal(t, time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC), *records[1].StartDate)
+
+	assert.Nil(t, records[2].StartDate)
+	assert.Equal(t, []string{`start date "someday" is not a recognised date`}, records[2].Problems)
+}
+
+func TestParseCSV_RequiresEmailColumn(t *testing.T) {
+	_, err := ParseCSV(strings.NewReader("Name,Title\nJane Doe,Engineer\n"))
+	assert.EqualError(t, err, "no email column found in the header row")
+
+	_, err = ParseCSV(strings.NewReader(""))
+	assert.EqualError(t, err, "file is empty")
+}
+
+func TestParseXLSX(t *testing.T) {
+	workbook := excelize.NewFile()
+	sheet := workbook.GetSheetName(0)
+	assert.NoError(t, workbook.SetSheetRow(sheet, "A1", &[]any{"Email", "Name", "Start Date"}))
+	assert.NoError(t, workbook.SetSheetRow(sheet, "A2", &[]any{"jane.doe@objectcomputing.com", "Jane Doe", time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC)}))
+	var buffer bytes.Buffer
+	assert.NoError(t, workbook.Write(&buffer))
+
+	records, err := ParseXLSX(&buffer)
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "Jane Doe", records[0].Name)
+	assert.Equal(t, time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC), *records[0].StartDate)
+}
+
+func TestParseXLSX_RejectsWorkbooksWhichUnzipTooLarge(t *testing.T) {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	assert.NoError(t, err)
+	_, err = io.CopyN(sheet, zeros{}, maxUnzippedWorkbookSize+1)
+	assert.NoError(t, err)
+	assert.NoError(t, archive.Close())
+	assert.Less(t, buffer.Len(), 1<<20, "The workbook should be small enough to upload")
+
+	_, err = ParseXLSX(&buffer)
+
+	assert.ErrorContains(t, err, "unzip size exceeds")
+}
+
+// zeros reads as an endless run of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestParseJSON(t *testing.T) {
+	records, err := ParseJSON(strings.NewReader(`[
+		{"email": "jane.doe@objectcomputing.com", "name": "Jane Doe", "manager": "boss@objectcomputing.com",
+		 "startDate": "2024-03-18", "reflections": [{"key": "Home", "value": "St. Louis"}]}
+	]`))
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, 1, records[0].Line)
+	assert.Equal(t, "boss@objectcomputing.com", records[0].ManagerEmail)
+	assert.Equal(t, []Reflection{{Key: "Home", Value: "St. Louis"}}, records[0].Reflections)
+}
+
+func TestFormatFromFilename(t *testing.T) {
+	assert.Equal(t, FormatCSV, FormatFromFilename("people.CSV"))
+	assert.Equal(t, FormatXLSX, FormatFromFilename("/tmp/people.xlsx"))
+	assert.Equal(t, FormatJSON, FormatFromFilename("people.json"))
+	assert.Equal(t, "", FormatFromFilename("people.xls"))
+}
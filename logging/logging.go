@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/tracing"
+)
+
+// NewLogger builds the application logger described by settings. JSON
+// output is intended for log shipping and text output for local use.
+// Either way trace and span IDs are added to records logged with a
+// context which carries a span.
+func NewLogger(settings config.Logging) *slog.Logger {
+	return newLogger(os.Stderr, settings)
+}
+
+func newLogger(w io.Writer, settings config.Logging) *slog.Logger {
+	options := &slog.HandlerOptions{Level: parseLevel(settings.Level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(settings.Format, "json") {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+	return slog.New(tracing.NewLogHandler(handler))
+}
+
+func parseLevel(level string) slog.Level {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return parsed
+}
+
+type contextKey struct{}
+
+// requestLogger is stored in the request context by pointer so that
+// middleware which runs after the logger was created, such as
+// authentication, can add attributes which the access log then sees.
+type requestLogger struct {
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
+// NewContext returns a copy of ctx which carries logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestLogger{logger: logger})
+}
+
+// FromContext returns the logger carried by ctx, or slog.Default when
+// there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if holder, ok := ctx.Value(contextKey{}).(*requestLogger); ok {
+		holder.mu.Lock()
+		defer holder.mu.Unlock()
+		return holder.logger
+	}
+	return slog.Default()
+}
+
+// With adds args to the logger carried by ctx for the remainder of the
+// request. It does nothing when ctx carries no logger.
+func With(ctx context.Context, args ...any) {
+	if holder, ok := ctx.Value(contextKey{}).(*requestLogger); ok {
+		holder.mu.Lock()
+		defer holder.mu.Unlock()
+		holder.logger = holder.logger.With(args...)
+	}
+}
@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureDefaultLogger routes slog.Default to a JSON buffer for the
+// duration of the test.
+func captureDefaultLogger(t *testing.T) *bytes.Buffer {
+	var output bytes.Buffer
+	original := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(original)
+	})
+	slog.SetDefault(newLogger(&output, config.Logging{Format: "json", Level: "debug"}))
+	return &output
+}
+
+func logLines(t *testing.T, output *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		lines = append(lines, record)
+	}
+	return lines
+}
+
+func testRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware)
+	router.GET("/employee/:employeeEmail", handler)
+	return router
+}
+
+func TestMiddleware_GeneratesRequestID(t *testing.T) {
+	output := captureDefaultLogger(t)
+	router := testRouter(func(c *gin.Context) {
+		FromContext(c.Request.Context()).InfoContext(c.Request.Context(), "handling")
+		c.Status(http.StatusNoContent)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/employee/someone@objectcomputing.com", nil))
+
+	requestID := recorder.Header().Get(RequestIDHeader)
+	assert.Len(t, requestID, 36, "A UUID should be generated")
+
+	lines := logLines(t, output)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "handling", lines[0]["msg"])
+	assert.Equal(t, requestID, lines[0]["request_id"])
+	assert.Equal(t, "request completed", lines[1]["msg"])
+	assert.Equal(t, requestID, lines[1]["request_id"])
+	assert.Equal(t, "/employee/:employeeEmail", lines[1]["route"])
+	assert.Equal(t, float64(http.StatusNoContent), lines[1]["status"])
+}
+
+func TestMiddleware_PropagatesRequestID(t *testing.T) {
+	output := captureDefaultLogger(t)
+	router := testRouter(func(c *gin.Context) {
+		With(c.Request.Context(), slog.String("user", "someone@objectcomputing.com"))
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/employee/someone@objectcomputing.com", nil)
+	req.Header.Set(RequestIDHeader, "upstream-id-123")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, "upstream-id-123", recorder.Header().Get(RequestIDHeader))
+	lines := logLines(t, output)
+	assert.Equal(t, "upstream-id-123", lines[0]["request_id"])
+	assert.Equal(t, "someone@objectcomputing.com", lines[0]["user"], "Attributes added during the request should reach the access log")
+}
+
+func TestMiddleware_ReplacesInvalidRequestID(t *testing.T) {
+	captureDefaultLogger(t)
+	router := testRouter(func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, invalid := range []string{"has spaces", strings.Repeat("x", maxRequestIDLength+1), "café"} {
+		req := httptest.NewRequest(http.MethodGet, "/employee/x", nil)
+		req.Header.Set(RequestIDHeader, invalid)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.NotEqual(t, invalid, recorder.Header().Get(RequestIDHeader))
+		assert.Len(t, recorder.Header().Get(RequestIDHeader), 36)
+	}
+}
+
+func TestFromContext_WithoutLogger(t *testing.T) {
+	assert.Equal(t, slog.Default(), FromContext(context.Background()))
+	With(context.Background(), "ignored", true)
+}
+
+func TestNewLogger_TextAndLevel(t *testing.T) {
+	var output bytes.Buffer
+	logger := newLogger(&output, config.Logging{Format: "text", Level: "warn"})
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	assert.NotContains(t, output.String(), "hidden")
+	assert.Contains(t, output.String(), "level=WARN msg=shown")
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is read from incoming requests, so that an ID assigned
+// by a load balancer or calling service is kept, and is always set on
+// the response.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// Middleware assigns each request an ID, stores a logger tagged with it
+// in the request context and writes an access log line once the
+// request has been handled.
+func Middleware(c *gin.Context) {
+	start := time.Now()
+
+	requestID := c.GetHeader(RequestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+	c.Header(RequestIDHeader, requestID)
+
+	ctx := c.Request.Context()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", requestID))
+	ctx = NewContext(ctx, slog.Default().With(slog.String("request_id", requestID)))
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+
+	level := slog.LevelInfo
+	status := c.Writer.Status()
+	if status >= 500 {
+		level = slog.LevelError
+	}
+	FromContext(ctx).LogAttrs(ctx, level, "request completed",
+		slog.String("method", c.Request.Method),
+		slog.String("route", c.FullPath()),
+		slog.String("path", c.Request.URL.Path),
+		slog.Int("status", status),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("bytes", c.Writer.Size()),
+		slog.String("client_ip", c.ClientIP()),
+		slog.String("user_agent", c.Request.UserAgent()),
+	)
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+// Package mail sends email from Satchel, such as reminders to complete
+// a profile, through the configured SMTP server.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+)
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// ErrInvalidMessage is returned for messages whose recipient or subject
+// could not be written into a header safely.
+var ErrInvalidMessage = errors.New("invalid email message")
+
+var sender Sender = logSender{}
+
+// Configure sends email through the SMTP server in settings. Email is
+// logged rather than sent while no SMTP host is configured.
+func Configure(settings config.Mail) {
+	if settings.Host == "" {
+		sender = logSender{}
+		return
+	}
+	sender = &smtpSender{settings: settings}
+}
+
+// Enabled reports whether email is sent rather than only logged.
+func Enabled() bool {
+	_, logged := sender.(logSender)
+	return !logged
+}
+
+// Send delivers message with the configured sender.
+func Send(ctx context.Context, message Message) error {
+	if _, err := netmail.ParseAddress(message.To); err != nil || strings.ContainsAny(message.Subject, "\r\n") {
+		return ErrInvalidMessage
+	}
+	return sender.Send(ctx, message)
+}
+
+// timeout bounds connecting to the SMTP server and then the whole
+// conversation with it, so that a server which stops responding cannot
+// hold up whoever is sending.
+const timeout = 30 * time.Second
+
+// smtpSender sends email through an SMTP server, authenticating when a
+// username is configured.
+type smtpSender struct {
+	settings config.Mail
+}
+
+func (s *smtpSender) Send(ctx context.Context, message Message) error {
+	from, err := netmail.ParseAddress(s.settings.From)
+	if err != nil {
+		return err
+	}
+	if err := s.send(ctx, from.Address, message); err != nil {
+		return fmt.Errorf("sending email to %s: %w", message.To, err)
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "Email sent", slog.String("to", message.To), slog.String("subject", message.Subject))
+	return nil
+}
+
+// send delivers message as smtp.SendMail would, upgrading to TLS when
+// the server offers it, but gives up once ctx is done or timeout passes.
+func (s *smtpSender) send(ctx context.Context, from string, message Message) (err error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.settings.Host, strconv.Itoa(s.settings.Port)))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+	}()
+	deadline := time.Now().Add(timeout)
+	if contextDeadline, ok := ctx.Deadline(); ok && contextDeadline.Before(deadline) {
+		deadline = contextDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, s.settings.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.settings.Host}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok && s.settings.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.settings.Username, s.settings.Password, s.settings.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return err
+	}
+	body, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := body.Write(format(s.settings.From, message, time.Now())); err != nil {
+		return err
+	}
+	if err := body.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// logSender logs messages instead of sending them, so that features
+// which send email can be used without an SMTP server in development.
+type logSender struct{}
+
+func (logSender) Send(ctx context.Context, message Message) error {
+	logging.FromContext(ctx).WarnContext(ctx, "SMTP is not configured; email logged instead of sent",
+		slog.String("to", message.To), slog.String("subject", message.Subject), slog.String("body", message.Body))
+	return nil
+}
+
+// format writes message as an RFC 5322 email from from, sent at date.
+func format(from string, message Message, date time.Time) []byte {
+	var buffer bytes.Buffer
+	header := func(name string, value string) {
+		buffer.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", from)
+	header("To", message.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "8bit")
+	buffer.WriteString("\r\n")
+	body := strings.ReplaceAll(message.Body, "\r\n", "\n")
+	buffer.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return buffer.Bytes()
+}
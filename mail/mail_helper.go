@@ -0,0 +1,42 @@
+//go:build !production
+
+package mail
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// Outbox records the messages sent while it is configured.
+type Outbox struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Send implements Sender.
+func (o *Outbox) Send(ctx context.Context, message Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, message)
+	return nil
+}
+
+// Messages returns the messages sent so far.
+func (o *Outbox) Messages() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Message(nil), o.messages...)
+}
+
+// ConfigureForTest records the messages sent for the rest of the test in
+// the returned Outbox instead of sending them.
+func ConfigureForTest(t testing.TB) *Outbox {
+	originalSender := sender
+	t.Cleanup(func() {
+		sender = originalSender
+	})
+	outbox := &Outbox{}
+	sender = outbox
+	return outbox
+}
@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2026, time.March, 2, 9, 30, 0, 0, time.UTC)
+
+	formatted := string(format("Satchel <satchel@example.com>", Message{
+		To:      "jane.doe@example.com",
+		Subject: "Your profile is 60% complete – finish it?",
+		Body:    "Hello\nThere",
+	}, date))
+
+	headers, body, found := strings.Cut(formatted, "\r\n\r\n")
+	assert.True(t, found)
+	assert.Contains(t, headers, "From: Satchel <satchel@example.com>\r\n")
+	assert.Contains(t, headers, "To: jane.doe@example.com\r\n")
+	assert.Contains(t, headers, "Subject: =?utf-8?q?")
+	assert.Contains(t, headers, "Date: Mon, 02 Mar 2026 09:30:00 +0000\r\n")
+	assert.Equal(t, "Hello\r\nThere", body)
+}
+
+func TestSend_RejectsHeaderInjection(t *testing.T) {
+	outbox := ConfigureForTest(t)
+
+	assert.ErrorIs(t, Send(t.Context(), Message{To: "jane.doe@example.com\r\nBcc: everyone@example.com", Subject: "Hi"}), ErrInvalidMessage)
+	assert.ErrorIs(t, Send(t.Context(), Message{To: "jane.doe@example.com", Subject: "Hi\r\nBcc: everyone@example.com"}), ErrInvalidMessage)
+	assert.NoError(t, Send(t.Context(), Message{To: "jane.doe@example.com", Subject: "Hi"}))
+	assert.Len(t, outbox.Messages(), 1)
+}
+
+func TestConfigure(t *testing.T) {
+	original := sender
+	t.Cleanup(func() {
+		sender = original
+	})
+
+	Configure(config.Mail{})
+	assert.False(t, Enabled(), "Email should only be logged without an SMTP host")
+
+	Configure(config.Mail{Host: "smtp.example.com", Port: 587, From: "satchel@example.com"})
+	assert.True(t, Enabled())
+}
+
+func TestSend_GivesUpWhenContextIsDone(t *testing.T) {
+	// A server which accepts connections but never greets the client.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+	smtp := &smtpSender{settings: config.Mail{Host: "127.0.0.1", Port: port, From: "satchel@example.com"}}
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	err = smtp.Send(ctx, Message{To: "jane.doe@example.com", Subject: "Hi"})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -1,19 +1,11 @@
 package main
 
 import (
-	"log/slog"
+	"os"
 
-	"github.com/jeffscottbrown/satchel/repository"
-	"github.com/jeffscottbrown/satchel/server"
-	"github.com/joho/godotenv"
+	"github.com/jeffscottbrown/satchel/cli"
 )
 
 func main() {
-
-	err := godotenv.Load()
-	if err != nil {
-		slog.Info("Could not load .env file, proceeding without it", slog.Any("error", err))
-	}
-	repository.InitializeDatabase()
-	server.Run()
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
 }
@@ -0,0 +1,137 @@
+// Package markdown renders the restricted Markdown dialect employees
+// write their bios in: paragraphs, emphasis, lists and links. Anything
+// else, such as headings or HTML, is shown as the text it was written
+// as, and the HTML produced is passed through an allow-list sanitizer
+// before it reaches a page.
+package markdown
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	converter = goldmark.New(
+		goldmark.WithParser(parser.NewParser(
+			parser.WithBlockParsers(
+				util.Prioritized(parser.NewListParser(), 300),
+				util.Prioritized(parser.NewListItemParser(), 400),
+				util.Prioritized(parser.NewParagraphParser(), 1000),
+			),
+			parser.WithInlineParsers(
+				util.Prioritized(parser.NewLinkParser(), 200),
+				util.Prioritized(parser.NewAutoLinkParser(), 300),
+				util.Prioritized(parser.NewEmphasisParser(), 500),
+				util.Prioritized(extension.NewLinkifyParser(), 999),
+			),
+		)),
+		goldmark.WithRendererOptions(html.WithHardWraps()),
+	)
+	policy = newPolicy()
+
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// newPolicy allows only the elements the dialect produces, and links
+// only to web pages and email addresses.
+func newPolicy() *bluemonday.Policy {
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements("p", "br", "em", "strong", "ul", "ol", "li")
+	policy.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	policy.AllowAttrs("href").OnElements("a")
+	policy.AllowURLSchemes("http", "https", "mailto")
+	policy.RequireParseableURLs(true)
+	policy.RequireNoFollowOnLinks(true)
+	policy.RequireNoReferrerOnFullyQualifiedLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	return policy
+}
+
+// Render renders source as sanitized HTML. Line breaks within a
+// paragraph are kept.
+func Render(source string) template.HTML {
+	var buffer bytes.Buffer
+	if err := converter.Convert([]byte(source), &buffer); err != nil {
+		return template.HTML(template.HTMLEscapeString(source))
+	}
+	return template.HTML(policy.SanitizeBytes(buffer.Bytes()))
+}
+
+// PlainText returns the text of source without its Markdown, for
+// searching and for exports which cannot show formatting. List items
+// keep a leading dash and links are followed by where they lead.
+func PlainText(source string) string {
+	src := []byte(source)
+	document := converter.Parser().Parse(text.NewReader(src))
+	var builder strings.Builder
+	ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch n := node.(type) {
+		case *ast.Paragraph:
+			if !entering {
+				builder.WriteString("\n\n")
+			}
+		case *ast.TextBlock:
+			if !entering {
+				builder.WriteString("\n")
+			}
+		case *ast.List:
+			if !entering {
+				builder.WriteString("\n")
+			}
+		case *ast.ListItem:
+			if entering {
+				builder.WriteString("- ")
+			}
+		case *ast.Text:
+			if entering {
+				builder.Write(n.Segment.Value(src))
+				if n.SoftLineBreak() || n.HardLineBreak() {
+					builder.WriteString("\n")
+				}
+			}
+		case *ast.String:
+			if entering {
+				builder.Write(n.Value)
+			}
+		case *ast.AutoLink:
+			if entering {
+				builder.Write(n.URL(src))
+			}
+		case *ast.Link:
+			if !entering && string(n.Destination) != linkText(n, src) {
+				builder.WriteString(" (" + string(n.Destination) + ")")
+			}
+		case *ast.Image:
+			if entering {
+				builder.WriteString(linkText(n, src))
+				return ast.WalkSkipChildren, nil
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(blankLines.ReplaceAllString(builder.String(), "\n\n"))
+}
+
+// linkText returns the text a link or image is written with.
+func linkText(node ast.Node, src []byte) string {
+	var builder strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if t, ok := child.(*ast.Text); ok {
+			builder.Write(t.Segment.Value(src))
+			continue
+		}
+		builder.WriteString(linkText(child, src))
+	}
+	return builder.String()
+}
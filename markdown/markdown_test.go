@@ -0,0 +1,38 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	rendered := string(Render("I like *music* and **hiking**.\nAsk me about:\n\n- [Phish](https://phish.com)\n- board games"))
+
+	assert.Equal(t, "<p>I like <em>music</em> and <strong>hiking</strong>.<br>\nAsk me about:</p>\n"+
+		"<ul>\n<li><a href=\"https://phish.com\" rel=\"nofollow noreferrer noopener\" target=\"_blank\">Phish</a></li>\n<li>board games</li>\n</ul>\n", rendered)
+}
+
+func TestRender_OnlyTheDialectIsAllowed(t *testing.T) {
+	for source, unwanted := range map[string]string{
+		"<script>alert(1)</script>":             "<script",
+		"<b onclick=\"alert(1)\">hi</b>":        "<b",
+		"[click](javascript:alert(1))":          "javascript:",
+		"![tracker](https://example.com/t.png)": "<img",
+		"# Heading":                             "<h1",
+		"    code":                              "<pre",
+		"[relative](/admin/import)":             "href",
+	} {
+		assert.NotContains(t, string(Render(source)), unwanted, source)
+	}
+	assert.Contains(t, string(Render("# Heading")), "# Heading", "Unsupported Markdown should be shown as written")
+	assert.Contains(t, string(Render("See https://example.com")), `<a href="https://example.com"`, "Bare URLs should become links")
+}
+
+func TestPlainText(t *testing.T) {
+	plain := PlainText("I like *music*.\nAsk me about:\n\n- [Phish](https://phish.com)\n- board games\n\n\n\nMail me@example.com or see https://example.com")
+
+	assert.Equal(t, "I like music.\nAsk me about:\n\n- Phish (https://phish.com)\n- board games\n\nMail me@example.com or see https://example.com", plain)
+	assert.Equal(t, "Just text", PlainText("Just text"))
+	assert.Empty(t, PlainText("   "))
+}
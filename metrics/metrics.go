@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const namespace = "satchel"
+
+// Registry holds every Satchel collector along with the standard Go
+// runtime and process collectors. It is served by Handler.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests labeled by gin route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	repositoryOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_operation_duration_seconds",
+		Help:      "Duration of repository operations.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	repositoryOperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "repository_operation_errors_total",
+		Help:      "Number of repository operations which returned an error.",
+	}, []string{"operation"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Number of completed login attempts labeled by result.",
+	}, []string{"result"})
+)
+
+// Login results recorded by RecordLogin.
+const (
+	LoginSuccess   = "success"
+	LoginFailure   = "failure"
+	LoginForbidden = "forbidden"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		repositoryOperationDuration,
+		repositoryOperationErrors,
+		logins,
+	)
+}
+
+var bearerToken string
+
+// Configure sets the bearer token scrapers must present to Authenticate.
+// Metrics are not served while no token is configured.
+func Configure(settings config.Metrics) {
+	bearerToken = settings.Token
+	if bearerToken == "" {
+		slog.Debug("Metrics are not served because no token is configured")
+	}
+}
+
+// Authenticate refuses requests which do not carry the configured
+// bearer token.
+func Authenticate(c *gin.Context) {
+	presented, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if bearerToken == "" || !found || subtle.ConstantTimeCompare([]byte(presented), []byte(bearerToken)) != 1 {
+		c.Header("WWW-Authenticate", `Bearer realm="satchel-metrics"`)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	c.Next()
+}
+
+// Handler serves the contents of Registry in the Prometheus exposition
+// format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Middleware records the duration of every request. Requests are
+// labeled by their route template (for example /employee/:employeeEmail)
+// rather than the raw path so that label cardinality stays bounded and
+// email addresses do not leak into metrics.
+func Middleware(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	httpRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Observe(time.Since(start).Seconds())
+}
+
+// ObserveRepositoryOperation records how long operation took since start
+// and counts it as an error when err is not nil. Not finding a record is
+// how logins, SCIM and uniqueness checks learn that something is new,
+// so gorm.ErrRecordNotFound is not counted.
+func ObserveRepositoryOperation(operation string, start time.Time, err error) {
+	repositoryOperationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		repositoryOperationErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// RecordLogin counts a completed login attempt with the given result.
+func RecordLogin(result string) {
+	logins.WithLabelValues(result).Inc()
+}
+
+var databaseCollector prometheus.Collector
+
+// RegisterDatabase exposes the connection pool statistics of db,
+// replacing any database registered previously.
+func RegisterDatabase(db *sql.DB) {
+	if databaseCollector != nil {
+		Registry.Unregister(databaseCollector)
+	}
+	databaseCollector = collectors.NewDBStatsCollector(db, namespace)
+	if err := Registry.Register(databaseCollector); err != nil {
+		slog.Error("failed to register database metrics", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func scrape(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return recorder.Body.String()
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics", Authenticate, gin.WrapH(Handler()))
+	scrapeWith := func(authorization string) int {
+		request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	Configure(config.Metrics{})
+	assert.Equal(t, http.StatusUnauthorized, scrapeWith("Bearer "), "Metrics should not be served without a configured token")
+
+	Configure(config.Metrics{Token: "scrape-token"})
+	t.Cleanup(func() { Configure(config.Metrics{}) })
+	assert.Equal(t, http.StatusUnauthorized, scrapeWith(""))
+	assert.Equal(t, http.StatusUnauthorized, scrapeWith("Bearer wrong"))
+	assert.Equal(t, http.StatusOK, scrapeWith("Bearer scrape-token"))
+}
+
+func TestMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware)
+	router.GET("/employee/:employeeEmail", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/employee/someone@objectcomputing.com", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no/such/route", nil))
+
+	body := scrape(t)
+	assert.Contains(t, body, `satchel_http_request_duration_seconds_count{method="GET",route="/employee/:employeeEmail",status="200"} 1`)
+	assert.Contains(t, body, `satchel_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, "someone@objectcomputing.com", "Raw paths must not be used as labels")
+}
+
+func TestObserveRepositoryOperation(t *testing.T) {
+	ObserveRepositoryOperation("TestOperation", time.Now(), nil)
+	ObserveRepositoryOperation("TestOperation", time.Now(), errors.New("boom"))
+	ObserveRepositoryOperation("TestOperation", time.Now(), fmt.Errorf("looking up: %w", gorm.ErrRecordNotFound))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(repositoryOperationErrors.WithLabelValues("TestOperation")), "Records which are not found should not count as errors")
+	assert.Contains(t, scrape(t), `satchel_repository_operation_duration_seconds_count{operation="TestOperation"} 3`)
+}
+
+func TestRecordLogin(t *testing.T) {
+	before := testutil.ToFloat64(logins.WithLabelValues(LoginForbidden))
+
+	RecordLogin(LoginForbidden)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(logins.WithLabelValues(LoginForbidden)))
+}
+
+func TestRegisterProfileStatistics(t *testing.T) {
+	RegisterProfileStatistics(func() (ProfileStatistics, error) {
+		return ProfileStatistics{Employees: 12, EmptyBios: 5}, nil
+	})
+
+	body := scrape(t)
+	assert.Contains(t, body, "satchel_employees 12")
+	assert.Contains(t, body, "satchel_profiles_empty_bio 5")
+
+	RegisterProfileStatistics(func() (ProfileStatistics, error) {
+		return ProfileStatistics{}, errors.New("database unavailable")
+	})
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.False(t, strings.Contains(recorder.Body.String(), "satchel_employees 12"), "Stale statistics should not be reported")
+}
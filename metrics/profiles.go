@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ProfileStatistics are business measures of the employee directory
+// which are computed when metrics are scraped.
+type ProfileStatistics struct {
+	Employees int64
+	EmptyBios int64
+}
+
+var (
+	employeesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "employees"),
+		"Number of employee profiles.",
+		nil, nil)
+	emptyBiosDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "profiles_empty_bio"),
+		"Number of employee profiles without a bio.",
+		nil, nil)
+)
+
+type profileCollector struct {
+	statistics func() (ProfileStatistics, error)
+}
+
+func (c *profileCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- employeesDesc
+	ch <- emptyBiosDesc
+}
+
+func (c *profileCollector) Collect(ch chan<- prometheus.Metric) {
+	statistics, err := c.statistics()
+	if err != nil {
+		slog.Error("failed to collect profile statistics", slog.Any("error", err))
+		ch <- prometheus.NewInvalidMetric(employeesDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(employeesDesc, prometheus.GaugeValue, float64(statistics.Employees))
+	ch <- prometheus.MustNewConstMetric(emptyBiosDesc, prometheus.GaugeValue, float64(statistics.EmptyBios))
+}
+
+var registeredProfileCollector prometheus.Collector
+
+// RegisterProfileStatistics exposes the statistics returned by source as
+// gauges, calling source each time metrics are scraped.
+func RegisterProfileStatistics(source func() (ProfileStatistics, error)) {
+	if registeredProfileCollector != nil {
+		Registry.Unregister(registeredProfileCollector)
+	}
+	registeredProfileCollector = &profileCollector{statistics: source}
+	if err := Registry.Register(registeredProfileCollector); err != nil {
+		slog.Error("failed to register profile metrics", slog.Any("error", err))
+	}
+}
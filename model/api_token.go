@@ -0,0 +1,36 @@
+package model
+
+import (
+	"net/http"
+	"time"
+)
+
+// Scopes an APIToken can be granted.
+const (
+	TokenScopeRead      = "read"
+	TokenScopeReadWrite = "read-write"
+)
+
+// APIToken is a personal access token an employee uses to script
+// requests. Only a hash of the secret is stored; Hint holds its last few
+// characters so that the employee can tell their tokens apart.
+type APIToken struct {
+	ID         uint   `gorm:"primaryKey"`
+	EmployeeID uint   `gorm:"index;not null"`
+	Name       string `gorm:"not null"`
+	Hash       string `gorm:"uniqueIndex;not null"`
+	Hint       string
+	Scope      string `gorm:"not null"`
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// Allows reports whether the token's scope permits a request with the
+// given HTTP method. Read-only tokens may only make safe requests.
+func (t *APIToken) Allows(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return t.Scope == TokenScopeReadWrite
+}
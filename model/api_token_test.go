@@ -0,0 +1,20 @@
+package model
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIToken_Allows(t *testing.T) {
+	read := &APIToken{Scope: TokenScopeRead}
+	readWrite := &APIToken{Scope: TokenScopeReadWrite}
+
+	assert.True(t, read.Allows(http.MethodGet))
+	assert.False(t, read.Allows(http.MethodPost), "Read-only tokens should not be able to make changes")
+	assert.False(t, read.Allows(http.MethodDelete))
+	assert.True(t, readWrite.Allows(http.MethodGet))
+	assert.True(t, readWrite.Allows(http.MethodPost))
+	assert.True(t, readWrite.Allows(http.MethodDelete))
+}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The rules a profile's completeness is scored by.
+const (
+	CompletenessName        = "name"
+	CompletenessPosition    = "position"
+	CompletenessPhoto       = "photo"
+	CompletenessBio         = "bio"
+	CompletenessReflections = "reflections"
+)
+
+// completeReflections is how many answered reflections a profile needs
+// to earn the whole weight of the reflections rule.
+const completeReflections = 3
+
+// completenessRules measures how far a profile meets each rule, from 0
+// to 1, and suggests how to meet the rules it falls short of.
+var completenessRules = map[string]struct {
+	measure    func(e *Employee) float64
+	suggestion string
+}{
+	CompletenessName: {
+		measure:    func(e *Employee) float64 { return met(strings.TrimSpace(e.Name) != "") },
+		suggestion: "Add the name you would like to be called.",
+	},
+	CompletenessPosition: {
+		measure:    func(e *Employee) float64 { return met(strings.TrimSpace(e.Position) != "") },
+		suggestion: "Add your position so the team knows what you work on.",
+	},
+	CompletenessPhoto: {
+		measure:    func(e *Employee) float64 { return met(e.Photo != "") },
+		suggestion: "Upload a photo of yourself.",
+	},
+	CompletenessBio: {
+		measure:    func(e *Employee) float64 { return met(strings.TrimSpace(e.Bio) != "") },
+		suggestion: "Write a short bio.",
+	},
+	CompletenessReflections: {
+		measure: func(e *Employee) float64 {
+			return math.Min(float64(e.AnsweredReflections())/completeReflections, 1)
+		},
+		suggestion: fmt.Sprintf("Answer at least %d reflections.", completeReflections),
+	},
+}
+
+// CompletenessRule weighs one of the completeness rules against the
+// others.
+type CompletenessRule struct {
+	Name   string
+	Weight int
+}
+
+// Completeness is how complete a profile is, as a percentage, and what
+// the employee could do to complete it, most valuable first.
+type Completeness struct {
+	Score       int
+	Suggestions []string
+}
+
+// Complete reports whether the profile meets every rule.
+func (c Completeness) Complete() bool {
+	return c.Score == 100
+}
+
+// ParseCompletenessRules parses rules written as name=weight, such as
+// bio=30.
+func ParseCompletenessRules(specs []string) ([]CompletenessRule, error) {
+	rules := make([]CompletenessRule, 0, len(specs))
+	for _, spec := range specs {
+		rule, err := ParseCompletenessRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ParseCompletenessRule parses a rule written as name=weight, such as
+// bio=30. The name must be one of the completeness rules and the weight
+// must be positive.
+func ParseCompletenessRule(spec string) (CompletenessRule, error) {
+	name, weight, _ := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if _, known := completenessRules[name]; !known {
+		return CompletenessRule{}, fmt.Errorf("unknown completeness rule %q", name)
+	}
+	number, err := strconv.Atoi(strings.TrimSpace(weight))
+	if err != nil || number <= 0 {
+		return CompletenessRule{}, fmt.Errorf("completeness rule %q needs a positive weight", name)
+	}
+	return CompletenessRule{Name: name, Weight: number}, nil
+}
+
+// Completeness scores the profile against rules. A rule which is only
+// partly met, such as having answered some reflections, earns part of
+// its weight.
+func (e *Employee) Completeness(rules []CompletenessRule) Completeness {
+	total, earned := 0, 0.0
+	type shortfall struct {
+		suggestion string
+		missing    float64
+	}
+	var shortfalls []shortfall
+	for _, rule := range rules {
+		definition := completenessRules[rule.Name]
+		if definition.measure == nil {
+			continue
+		}
+		total += rule.Weight
+		measured := definition.measure(e)
+		earned += measured * float64(rule.Weight)
+		if measured < 1 {
+			shortfalls = append(shortfalls, shortfall{definition.suggestion, (1 - measured) * float64(rule.Weight)})
+		}
+	}
+	if total == 0 {
+		return Completeness{Score: 100}
+	}
+
+	completeness := Completeness{Score: int(math.Floor(100 * earned / float64(total)))}
+	slices.SortStableFunc(shortfalls, func(a, b shortfall) int {
+		return cmp.Compare(b.missing, a.missing)
+	})
+	for _, s := range shortfalls {
+		completeness.Suggestions = append(completeness.Suggestions, s.suggestion)
+	}
+	return completeness
+}
+
+// AnsweredReflections counts the reflections the employee has given a
+// value, leaving out the placeholders early profiles were created with.
+func (e *Employee) AnsweredReflections() int {
+	answered := 0
+	for i := range e.Reflections {
+		reflection := &e.Reflections[i]
+		if reflection.Value != "" && reflection.Key != "Temporary Thing #"+reflection.Value {
+			answered++
+		}
+	}
+	return answered
+}
+
+func met(condition bool) float64 {
+	if condition {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmployee_Completeness(t *testing.T) {
+	rules, err := ParseCompletenessRules([]string{"name=10", "position=15", "photo=20", "bio=30", "reflections=25"})
+	assert.NoError(t, err)
+	e := &Employee{Name: "Jane Doe", Position: "Engineer", Bio: "  "}
+	e.AddReflection("Temporary Thing #1", "1")
+	e.AddReflection("Favorite Band", "Grateful Dead")
+
+	completeness := e.Completeness(rules)
+
+	assert.Equal(t, 33, completeness.Score, "One of three reflections should earn a third of their weight")
+	assert.Equal(t, []string{"Write a short bio.", "Upload a photo of yourself.", "Answer at least 3 reflections."}, completeness.Suggestions,
+		"Suggestions should be ordered by how much they would add")
+	assert.False(t, completeness.Complete())
+
+	e.Bio = "Likes music"
+	e.Photo = "0123456789abcdef0123456789abcdef"
+	e.AddReflection("Home", "St. Louis")
+	e.AddReflection("Pets", "A cat")
+	completeness = e.Completeness(rules)
+	assert.Equal(t, 100, completeness.Score)
+	assert.Empty(t, completeness.Suggestions)
+	assert.True(t, completeness.Complete())
+}
+
+func TestParseCompletenessRules(t *testing.T) {
+	rules, err := ParseCompletenessRules([]string{"bio = 3", "photo=1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []CompletenessRule{{Name: "bio", Weight: 3}, {Name: "photo", Weight: 1}}, rules)
+	assert.Equal(t, 75, (&Employee{Bio: "Something"}).Completeness(rules).Score)
+
+	_, err = ParseCompletenessRules([]string{"pets=10"})
+	assert.EqualError(t, err, `unknown completeness rule "pets"`)
+	_, err = ParseCompletenessRules([]string{"bio=0"})
+	assert.Error(t, err)
+}
@@ -1,9 +1,22 @@
 package model
 
 import (
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
+// MaxBioLength is the most characters a bio may have.
+const MaxBioLength = 750
+
+// BioTooLong reports whether bio has more than MaxBioLength characters,
+// counting each line break as one character as the bio editor does.
+func BioTooLong(bio string) bool {
+	return utf8.RuneCountInString(strings.ReplaceAll(bio, "\r\n", "\n")) > MaxBioLength
+}
+
 type Employee struct {
 	ID          uint `gorm:"primaryKey"`
 	Name        string
@@ -12,9 +25,48 @@ type Employee struct {
 	Position    string
 	Reflections []Reflection `gorm:"constraint:OnDelete:CASCADE;foreignKey:EmployeeID"`
 	ImageName   string
-	Email       string `gorm:"uniqueIndex;not null"`
-	Bio         string
-	mu          sync.Mutex `gorm:"-"`
+	// Photo is the key of the photo the employee uploaded, which is
+	// shown instead of ImageName.
+	Photo string
+	// Avatar is the key of the cached copy of the image at AvatarSource,
+	// which was the employee's ImageName when it was fetched.
+	Avatar       string
+	AvatarSource string
+	Email        string `gorm:"uniqueIndex;not null"`
+	// Bio is written in the restricted Markdown the markdown package
+	// renders. BioText is the same bio as plain text, for searching and
+	// exporting, and is kept up to date when the employee is saved.
+	Bio     string
+	BioText string
+	// ManagerEmail is the email of the employee's manager, who may not be
+	// in the directory. Saving a manager whose email changed moves their
+	// reports to the new email.
+	ManagerEmail string
+	StartDate    *time.Time
+	// ExternalID is the identity provider's identifier for the employee,
+	// recorded when the profile is provisioned over SCIM.
+	ExternalID    string
+	Admin         bool
+	DeactivatedAt *time.Time
+	// OverriddenFields lists, separated by commas, the IdentityFields the
+	// employee has edited. IdentityProfile holds what the identity
+	// provider last said those fields should be.
+	OverriddenFields string
+	IdentityProfile  IdentityProfile `gorm:"serializer:json"`
+	// OnboardingStep is the onboarding step the employee has reached. It
+	// is empty once they finish and for employees who were never
+	// onboarded. OnboardingSkipped records that they chose to finish
+	// later, so they are no longer sent there.
+	OnboardingStep    string
+	OnboardingSkipped bool
+	// OnboardingStartedAt is when the employee was first sent through
+	// onboarding. Profiles provisioned by an import or over SCIM have
+	// none until the employee first logs in.
+	OnboardingStartedAt *time.Time
+	// RemindedAt is when the employee was last emailed a reminder to
+	// complete their profile.
+	RemindedAt *time.Time
+	mu         sync.Mutex `gorm:"-"`
 }
 
 type Reflection struct {
@@ -22,6 +74,30 @@ type Reflection struct {
 	Key        string
 	Value      string
 	EmployeeID uint
+	// PromptID is the prompt the reflection answers, if it was added
+	// from the prompt bank rather than written by the employee. Its Value
+	// is empty until the employee answers.
+	PromptID *uint
+}
+
+// IsActive reports whether the employee has not been deactivated.
+func (e *Employee) IsActive() bool {
+	return e.DeactivatedAt == nil
+}
+
+// PhotoURL returns the address of the employee's photo at the named
+// size. An uploaded photo is preferred, then an image served by Satchel
+// itself, such as one beneath /static. Images on other sites are served
+// through the avatar proxy so that browsers never request them directly.
+func (e *Employee) PhotoURL(size string) string {
+	switch {
+	case e.Photo != "":
+		return "/photos/" + e.Photo + "-" + size + ".jpg"
+	case strings.HasPrefix(e.ImageName, "/") && !strings.HasPrefix(e.ImageName, "//"):
+		return e.ImageName
+	default:
+		return "/avatars/" + strconv.FormatUint(uint64(e.ID), 10) + "/" + size
+	}
 }
 
 func (e *Employee) AddReflection(scoreName string, value string) {
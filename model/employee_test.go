@@ -23,3 +23,18 @@ func TestEmployee_AddReflection(t *testing.T) {
 	assert.Equal(t, "performance", e.Reflections[2].Key, "Third score key should be 'performance'")
 	assert.Equal(t, "100", e.Reflections[2].Value, "Third score value should be '100'")
 }
+
+func TestEmployee_PhotoURL(t *testing.T) {
+	e := &Employee{ID: 7, ImageName: "https://lh3.googleusercontent.com/a/photo"}
+
+	assert.Equal(t, "/avatars/7/thumb", e.PhotoURL("thumb"), "Images on other sites should be proxied")
+
+	e.ImageName = "//evil.example.com/photo.png"
+	assert.Equal(t, "/avatars/7/thumb", e.PhotoURL("thumb"), "Protocol-relative URLs are on other sites")
+
+	e.ImageName = "/static/images/jane.jpg"
+	assert.Equal(t, "/static/images/jane.jpg", e.PhotoURL("thumb"))
+
+	e.Photo = "0123456789abcdef0123456789abcdef"
+	assert.Equal(t, "/photos/0123456789abcdef0123456789abcdef-thumb.jpg", e.PhotoURL("thumb"))
+}
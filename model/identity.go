@@ -0,0 +1,79 @@
+package model
+
+import (
+	"slices"
+	"strings"
+)
+
+// The profile fields copied from the identity provider each time an
+// employee logs in, unless the employee has overridden them by editing
+// the field themselves.
+const (
+	FieldName      = "name"
+	FieldFirstName = "firstName"
+	FieldLastName  = "lastName"
+)
+
+// IdentityFields lists the fields owned by the identity provider in the
+// order they are shown.
+var IdentityFields = []string{FieldName, FieldFirstName, FieldLastName}
+
+// IdentityProfile holds the values the identity provider gave for an
+// employee when they last logged in.
+type IdentityProfile struct {
+	Name      string `json:"name,omitempty"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+}
+
+// Value returns the identity provider's value for field.
+func (p IdentityProfile) Value(field string) string {
+	switch field {
+	case FieldName:
+		return p.Name
+	case FieldFirstName:
+		return p.FirstName
+	case FieldLastName:
+		return p.LastName
+	default:
+		return ""
+	}
+}
+
+// IdentityField returns a pointer to the employee's value for one of
+// IdentityFields, or nil for any other field.
+func (e *Employee) IdentityField(field string) *string {
+	switch field {
+	case FieldName:
+		return &e.Name
+	case FieldFirstName:
+		return &e.FirstName
+	case FieldLastName:
+		return &e.LastName
+	default:
+		return nil
+	}
+}
+
+// Overridden reports whether the employee has edited field, so that it
+// is no longer refreshed from the identity provider.
+func (e *Employee) Overridden(field string) bool {
+	return slices.Contains(e.overrides(), field)
+}
+
+// SetOverridden records whether the employee has edited field.
+func (e *Employee) SetOverridden(field string, overridden bool) {
+	overrides := slices.DeleteFunc(e.overrides(), func(f string) bool { return f == field })
+	if overridden {
+		overrides = append(overrides, field)
+	}
+	slices.Sort(overrides)
+	e.OverriddenFields = strings.Join(overrides, ",")
+}
+
+func (e *Employee) overrides() []string {
+	if e.OverriddenFields == "" {
+		return nil
+	}
+	return strings.Split(e.OverriddenFields, ",")
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmployee_SetOverridden(t *testing.T) {
+	e := &Employee{}
+	assert.False(t, e.Overridden(FieldName))
+
+	e.SetOverridden(FieldName, true)
+	e.SetOverridden(FieldLastName, true)
+	e.SetOverridden(FieldName, true)
+
+	assert.True(t, e.Overridden(FieldName))
+	assert.True(t, e.Overridden(FieldLastName))
+	assert.False(t, e.Overridden(FieldFirstName))
+	assert.Equal(t, "lastName,name", e.OverriddenFields)
+
+	e.SetOverridden(FieldName, false)
+	e.SetOverridden(FieldLastName, false)
+	assert.False(t, e.Overridden(FieldName))
+	assert.Empty(t, e.OverriddenFields)
+}
+
+func TestEmployee_IdentityField(t *testing.T) {
+	e := &Employee{Name: "Jane Doe", FirstName: "Jane", LastName: "Doe"}
+	profile := IdentityProfile{Name: "Janet Doe", FirstName: "Janet", LastName: "Doe"}
+
+	for _, field := range IdentityFields {
+		assert.NotNil(t, e.IdentityField(field), field)
+		assert.NotEmpty(t, profile.Value(field), field)
+	}
+	*e.IdentityField(FieldFirstName) = "Janet"
+	assert.Equal(t, "Janet", e.FirstName)
+	assert.Nil(t, e.IdentityField("bio"), "Only identity provider fields can be looked up")
+}
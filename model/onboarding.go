@@ -0,0 +1,46 @@
+package model
+
+import "slices"
+
+// The steps of the onboarding a new employee is guided through after
+// their profile is created.
+const (
+	OnboardingName     = "name"
+	OnboardingPosition = "position"
+	OnboardingPhoto    = "photo"
+	OnboardingBio      = "bio"
+	OnboardingPrompts  = "prompts"
+)
+
+// OnboardingSteps lists the onboarding steps in the order they are taken.
+var OnboardingSteps = []string{OnboardingName, OnboardingPosition, OnboardingPhoto, OnboardingBio, OnboardingPrompts}
+
+// StartOnboarding puts the employee at the first onboarding step.
+func (e *Employee) StartOnboarding() {
+	e.OnboardingStep = OnboardingSteps[0]
+	e.OnboardingSkipped = false
+}
+
+// Onboarding reports whether the employee has onboarding steps left,
+// whether or not they chose to finish them later.
+func (e *Employee) Onboarding() bool {
+	return e.OnboardingStep != ""
+}
+
+// NeedsOnboarding reports whether the employee should be sent to
+// onboarding: they have steps left and did not choose to finish later.
+func (e *Employee) NeedsOnboarding() bool {
+	return e.Onboarding() && !e.OnboardingSkipped
+}
+
+// AdvanceOnboarding moves the employee to the step after the one they
+// are on. Leaving the last step finishes onboarding.
+func (e *Employee) AdvanceOnboarding() {
+	i := slices.Index(OnboardingSteps, e.OnboardingStep)
+	if i < 0 || i == len(OnboardingSteps)-1 {
+		e.OnboardingStep = ""
+		e.OnboardingSkipped = false
+		return
+	}
+	e.OnboardingStep = OnboardingSteps[i+1]
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmployee_Onboarding(t *testing.T) {
+	e := &Employee{}
+	assert.False(t, e.NeedsOnboarding(), "Employees who never started onboarding should not need it")
+
+	e.StartOnboarding()
+	assert.Equal(t, OnboardingName, e.OnboardingStep)
+	assert.True(t, e.NeedsOnboarding())
+
+	e.AdvanceOnboarding()
+	assert.Equal(t, OnboardingPosition, e.OnboardingStep)
+	e.OnboardingSkipped = true
+	assert.True(t, e.Onboarding())
+	assert.False(t, e.NeedsOnboarding(), "Employees who chose to finish later should not be sent back")
+
+	for e.Onboarding() {
+		e.AdvanceOnboarding()
+	}
+	assert.Empty(t, e.OnboardingStep)
+	assert.False(t, e.OnboardingSkipped)
+}
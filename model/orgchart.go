@@ -0,0 +1,150 @@
+package model
+
+import (
+	"slices"
+	"strings"
+)
+
+// Managers maps employees' email addresses, in lower case, to their
+// managers'.
+type Managers map[string]string
+
+// NewManagers records the manager of each of employees.
+func NewManagers(employees []Employee) Managers {
+	managers := Managers{}
+	for i := range employees {
+		managers.Set(employees[i].Email, employees[i].ManagerEmail)
+	}
+	return managers
+}
+
+// Set records manager as the manager of email.
+func (m Managers) Set(email string, manager string) {
+	m[strings.ToLower(email)] = strings.ToLower(manager)
+}
+
+// WouldCycle reports whether making manager the manager of email would
+// put email in its own chain of command.
+func (m Managers) WouldCycle(email string, manager string) bool {
+	email = strings.ToLower(email)
+	seen := map[string]bool{}
+	for current := strings.ToLower(manager); current != "" && !seen[current]; current = m[current] {
+		if current == email {
+			return true
+		}
+		seen[current] = true
+	}
+	return false
+}
+
+// OrgNode is an employee's place in an OrgChart.
+type OrgNode struct {
+	Employee *Employee
+	// Manager is the node the employee appears under, or nil at the top
+	// of the chart.
+	Manager *OrgNode
+	// Reports are the employee's direct reports, ordered by name.
+	Reports []*OrgNode
+	// InCycle marks an employee whose chain of command leads back to
+	// them, which needs an admin to correct.
+	InCycle bool
+}
+
+// OrgChart arranges employees by who they report to.
+type OrgChart struct {
+	// Roots are the employees at the top of the chart: those without a
+	// manager, those whose manager is not in the chart and one member of
+	// each reporting cycle.
+	Roots []*OrgNode
+	// Cycles counts the employees in reporting cycles.
+	Cycles int
+	nodes  map[string]*OrgNode
+}
+
+// NewOrgChart builds the chart of employees. The chart refers to the
+// employees, which must not be modified while it is in use.
+func NewOrgChart(employees []Employee) *OrgChart {
+	chart := &OrgChart{nodes: map[string]*OrgNode{}}
+	for i := range employees {
+		chart.nodes[strings.ToLower(employees[i].Email)] = &OrgNode{Employee: &employees[i]}
+	}
+	managerOf := func(node *OrgNode) *OrgNode {
+		manager := chart.nodes[strings.ToLower(node.Employee.ManagerEmail)]
+		if manager == node {
+			return nil
+		}
+		return manager
+	}
+
+	// Each cycle is broken at the member whose email address sorts
+	// first, who is placed at the top of the chart.
+	breaks := map[*OrgNode]bool{}
+	for _, node := range chart.nodes {
+		if node.InCycle {
+			continue
+		}
+		var cycle []*OrgNode
+		seen := map[*OrgNode]bool{}
+		for current := managerOf(node); current != nil && !seen[current]; current = managerOf(current) {
+			seen[current] = true
+			if current == node {
+				cycle = append(cycle, node)
+				for member := managerOf(node); member != node; member = managerOf(member) {
+					cycle = append(cycle, member)
+				}
+				break
+			}
+		}
+		if len(cycle) == 0 {
+			continue
+		}
+		for _, member := range cycle {
+			member.InCycle = true
+		}
+		chart.Cycles += len(cycle)
+		breaks[slices.MinFunc(cycle, func(a, b *OrgNode) int {
+			return strings.Compare(strings.ToLower(a.Employee.Email), strings.ToLower(b.Employee.Email))
+		})] = true
+	}
+
+	for _, node := range chart.nodes {
+		manager := managerOf(node)
+		if manager == nil || breaks[node] {
+			chart.Roots = append(chart.Roots, node)
+			continue
+		}
+		node.Manager = manager
+		manager.Reports = append(manager.Reports, node)
+	}
+	sortNodes(chart.Roots)
+	for _, node := range chart.nodes {
+		sortNodes(node.Reports)
+	}
+	return chart
+}
+
+func sortNodes(nodes []*OrgNode) {
+	slices.SortFunc(nodes, func(a, b *OrgNode) int {
+		if c := strings.Compare(strings.ToLower(a.Employee.Name), strings.ToLower(b.Employee.Name)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Employee.Email, b.Employee.Email)
+	})
+}
+
+// Node returns the employee's node, or nil when they are not in the
+// chart.
+func (c *OrgChart) Node(email string) *OrgNode {
+	return c.nodes[strings.ToLower(email)]
+}
+
+// ChainOfCommand returns the managers above the node, from the top of
+// the chart down to its own manager.
+func (n *OrgNode) ChainOfCommand() []*OrgNode {
+	var chain []*OrgNode
+	for manager := n.Manager; manager != nil; manager = manager.Manager {
+		chain = append(chain, manager)
+	}
+	slices.Reverse(chain)
+	return chain
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func names(nodes []*OrgNode) []string {
+	var names []string
+	for _, node := range nodes {
+		names = append(names, node.Employee.Name)
+	}
+	return names
+}
+
+func TestOrgChart(t *testing.T) {
+	chart := NewOrgChart([]Employee{
+		{Email: "ceo@example.com", Name: "Ceo"},
+		{Email: "vp@example.com", Name: "Vp", ManagerEmail: "CEO@example.com"},
+		{Email: "bob@example.com", Name: "Bob", ManagerEmail: "vp@example.com"},
+		{Email: "amy@example.com", Name: "Amy", ManagerEmail: "vp@example.com"},
+		{Email: "orphan@example.com", Name: "Orphan", ManagerEmail: "gone@example.com"},
+		{Email: "self@example.com", Name: "Self", ManagerEmail: "self@example.com"},
+	})
+
+	assert.Equal(t, []string{"Ceo", "Orphan", "Self"}, names(chart.Roots), "People without a manager in the chart should be at the top")
+	assert.Equal(t, []string{"Amy", "Bob"}, names(chart.Node("vp@example.com").Reports))
+	assert.Equal(t, []string{"Ceo", "Vp"}, names(chart.Node("BOB@example.com").ChainOfCommand()))
+	assert.Empty(t, chart.Node("ceo@example.com").ChainOfCommand())
+	assert.Nil(t, chart.Node("gone@example.com"))
+	assert.Zero(t, chart.Cycles)
+}
+
+func TestOrgChart_Cycles(t *testing.T) {
+	chart := NewOrgChart([]Employee{
+		{Email: "c@example.com", Name: "C", ManagerEmail: "a@example.com"},
+		{Email: "a@example.com", Name: "A", ManagerEmail: "b@example.com"},
+		{Email: "b@example.com", Name: "B", ManagerEmail: "c@example.com"},
+		{Email: "d@example.com", Name: "D", ManagerEmail: "b@example.com"},
+	})
+
+	assert.Equal(t, 3, chart.Cycles)
+	assert.Equal(t, []string{"A"}, names(chart.Roots), "A cycle should be broken at one of its members")
+	assert.Equal(t, []string{"A", "C", "B"}, names(chart.Node("d@example.com").ChainOfCommand()))
+	assert.True(t, chart.Node("b@example.com").InCycle)
+	assert.False(t, chart.Node("d@example.com").InCycle)
+}
+
+func TestManagers_WouldCycle(t *testing.T) {
+	managers := NewManagers([]Employee{
+		{Email: "ceo@example.com"},
+		{Email: "vp@example.com", ManagerEmail: "ceo@example.com"},
+		{Email: "dev@example.com", ManagerEmail: "vp@example.com"},
+		{Email: "x@example.com", ManagerEmail: "y@example.com"},
+		{Email: "y@example.com", ManagerEmail: "x@example.com"},
+	})
+
+	assert.True(t, managers.WouldCycle("ceo@example.com", "dev@example.com"))
+	assert.True(t, managers.WouldCycle("vp@example.com", "VP@example.com"))
+	assert.False(t, managers.WouldCycle("dev@example.com", "ceo@example.com"))
+	assert.False(t, managers.WouldCycle("ceo@example.com", "x@example.com"), "An existing cycle elsewhere should not loop forever")
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Prompt is a question from the bank admins manage, which employees are
+// invited to answer as one of their reflections.
+type Prompt struct {
+	ID       uint   `gorm:"primaryKey"`
+	Question string `gorm:"uniqueIndex;not null"`
+	Category string
+	// Inactive prompts are no longer offered but remain on the profiles
+	// of employees who answered them.
+	Active bool
+	// Curated prompts are given to every new employee ahead of those
+	// chosen at random.
+	Curated   bool
+	CreatedAt time.Time
+}
+
+// ChoosePrompts picks up to count of the active prompts for a new
+// profile: every curated prompt first, then the others in the order
+// shuffle leaves them.
+func ChoosePrompts(prompts []Prompt, count int, shuffle func(n int, swap func(i, j int))) []Prompt {
+	var curated, others []Prompt
+	for i := range prompts {
+		switch {
+		case !prompts[i].Active:
+		case prompts[i].Curated:
+			curated = append(curated, prompts[i])
+		default:
+			others = append(others, prompts[i])
+		}
+	}
+	shuffle(len(curated), func(i, j int) { curated[i], curated[j] = curated[j], curated[i] })
+	shuffle(len(others), func(i, j int) { others[i], others[j] = others[j], others[i] })
+	chosen := append(curated, others...)
+	return chosen[:min(count, len(chosen))]
+}
+
+// AddPrompt adds an unanswered reflection for the prompt.
+func (e *Employee) AddPrompt(prompt Prompt) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := prompt.ID
+	e.Reflections = append(e.Reflections, Reflection{
+		Key:      prompt.Question,
+		PromptID: &id,
+	})
+}
+
+// HasPrompt reports whether the employee already has a reflection for
+// the prompt, either because it was given to them or because they wrote
+// the same question themselves.
+func (e *Employee) HasPrompt(prompt Prompt) bool {
+	return slices.ContainsFunc(e.Reflections, func(r Reflection) bool {
+		return (r.PromptID != nil && *r.PromptID == prompt.ID) || strings.EqualFold(strings.TrimSpace(r.Key), prompt.Question)
+	})
+}
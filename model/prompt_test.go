@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func noShuffle(int, func(i, j int)) {}
+
+func TestChoosePrompts(t *testing.T) {
+	prompts := []Prompt{
+		{ID: 1, Question: "Favorite band", Active: true},
+		{ID: 2, Question: "Hometown", Active: true, Curated: true},
+		{ID: 3, Question: "Retired question", Active: false, Curated: true},
+		{ID: 4, Question: "Pets", Active: true},
+		{ID: 5, Question: "Ask me about", Active: true, Curated: true},
+	}
+
+	chosen := ChoosePrompts(prompts, 3, noShuffle)
+
+	questions := make([]string, len(chosen))
+	for i, prompt := range chosen {
+		questions[i] = prompt.Question
+	}
+	assert.Equal(t, []string{"Hometown", "Ask me about", "Favorite band"}, questions, "Curated prompts should come first and inactive ones never")
+	assert.Len(t, ChoosePrompts(prompts, 10, noShuffle), 4)
+	assert.Empty(t, ChoosePrompts(nil, 4, noShuffle))
+}
+
+func TestEmployee_AddPrompt(t *testing.T) {
+	e := &Employee{}
+	e.AddReflection("hometown", "St. Louis")
+
+	e.AddPrompt(Prompt{ID: 7, Question: "Favorite band"})
+
+	assert.Len(t, e.Reflections, 2)
+	assert.Equal(t, "Favorite band", e.Reflections[1].Key)
+	assert.Empty(t, e.Reflections[1].Value, "A prompt should start unanswered")
+	assert.Equal(t, uint(7), *e.Reflections[1].PromptID)
+	assert.True(t, e.HasPrompt(Prompt{ID: 7, Question: "Renamed since"}))
+	assert.True(t, e.HasPrompt(Prompt{ID: 8, Question: "Hometown"}), "A question the employee wrote themselves counts")
+	assert.False(t, e.HasPrompt(Prompt{ID: 9, Question: "Pets"}))
+}
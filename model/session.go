@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Session is a browser session. The session cookie holds a random key
+// and only its hash is stored, so a session can be ended from anywhere
+// by deleting it.
+type Session struct {
+	ID            uint   `gorm:"primaryKey"`
+	KeyHash       string `gorm:"uniqueIndex;not null"`
+	EmployeeEmail string `gorm:"index;not null"`
+	UserAgent     string
+	IPAddress     string
+	CreatedAt     time.Time
+	LastSeenAt    time.Time
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Skill is an entry in the catalog of skills admins curate, which
+// employees choose from when they record what they know.
+type Skill struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"uniqueIndex;not null"`
+	// Synonyms lists, separated by commas, other names the skill goes by,
+	// such as "k8s" for Kubernetes.
+	Synonyms  string
+	CreatedAt time.Time
+}
+
+// SynonymList returns the skill's synonyms.
+func (s *Skill) SynonymList() []string {
+	var synonyms []string
+	for _, synonym := range strings.Split(s.Synonyms, ",") {
+		if synonym = strings.TrimSpace(synonym); synonym != "" {
+			synonyms = append(synonyms, synonym)
+		}
+	}
+	return synonyms
+}
+
+// Matches reports whether name, ignoring case, is the skill's name or
+// one of its synonyms.
+func (s *Skill) Matches(name string) bool {
+	name = strings.TrimSpace(name)
+	if strings.EqualFold(s.Name, name) {
+		return true
+	}
+	for _, synonym := range s.SynonymList() {
+		if strings.EqualFold(synonym, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindSkill returns the skill in skills which name matches, or nil when
+// there is none.
+func FindSkill(skills []Skill, name string) *Skill {
+	for i := range skills {
+		if skills[i].Matches(name) {
+			return &skills[i]
+		}
+	}
+	return nil
+}
+
+// Proficiency levels, from MinProficiency to MaxProficiency.
+const (
+	MinProficiency = 1
+	MaxProficiency = 5
+)
+
+var proficiencyNames = []string{"Novice", "Advanced Beginner", "Competent", "Proficient", "Expert"}
+
+// ProficiencyName describes a proficiency level.
+func ProficiencyName(level int) string {
+	if level < MinProficiency || level > MaxProficiency {
+		return ""
+	}
+	return proficiencyNames[level-MinProficiency]
+}
+
+// ProficiencyLevels returns every proficiency level, lowest first.
+func ProficiencyLevels() []int {
+	levels := make([]int, 0, MaxProficiency-MinProficiency+1)
+	for level := MinProficiency; level <= MaxProficiency; level++ {
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// EmployeeSkill records an employee's proficiency, from MinProficiency
+// to MaxProficiency, and years of experience with a skill.
+type EmployeeSkill struct {
+	ID           uint      `gorm:"primaryKey"`
+	EmployeeID   uint      `gorm:"uniqueIndex:idx_employee_skill;not null"`
+	Employee     *Employee `gorm:"constraint:OnDelete:CASCADE"`
+	SkillID      uint      `gorm:"uniqueIndex:idx_employee_skill;not null"`
+	Skill        *Skill    `gorm:"constraint:OnDelete:CASCADE"`
+	Proficiency  int
+	Years        int
+	Endorsements []Endorsement `gorm:"constraint:OnDelete:CASCADE;foreignKey:EmployeeSkillID"`
+	UpdatedAt    time.Time
+}
+
+// EndorsedBy reports whether the employee with the given ID endorsed
+// the skill.
+func (s *EmployeeSkill) EndorsedBy(employeeID uint) bool {
+	for i := range s.Endorsements {
+		if s.Endorsements[i].EndorserID == employeeID {
+			return true
+		}
+	}
+	return false
+}
+
+// Endorsement is a colleague vouching for an employee's skill.
+type Endorsement struct {
+	ID              uint      `gorm:"primaryKey"`
+	EmployeeSkillID uint      `gorm:"uniqueIndex:idx_endorsement;not null"`
+	EndorserID      uint      `gorm:"uniqueIndex:idx_endorsement;not null"`
+	Endorser        *Employee `gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt       time.Time
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSkill(t *testing.T) {
+	skills := []Skill{
+		{ID: 1, Name: "Go", Synonyms: "golang"},
+		{ID: 2, Name: "Kubernetes", Synonyms: "k8s, kube,"},
+	}
+
+	assert.Equal(t, uint(2), FindSkill(skills, " K8S ").ID)
+	assert.Equal(t, uint(1), FindSkill(skills, "go").ID)
+	assert.Nil(t, FindSkill(skills, "kotlin"))
+	assert.Equal(t, []string{"k8s", "kube"}, skills[1].SynonymList())
+}
+
+func TestProficiency(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ProficiencyLevels())
+	assert.Equal(t, "Novice", ProficiencyName(MinProficiency))
+	assert.Equal(t, "Expert", ProficiencyName(MaxProficiency))
+	assert.Empty(t, ProficiencyName(6))
+}
+
+func TestEmployeeSkill_EndorsedBy(t *testing.T) {
+	skill := &EmployeeSkill{Endorsements: []Endorsement{{EndorserID: 7}}}
+
+	assert.True(t, skill.EndorsedBy(7))
+	assert.False(t, skill.EndorsedBy(8))
+}
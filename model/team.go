@@ -0,0 +1,73 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Team roles.
+const (
+	TeamLead   = "lead"
+	TeamMember = "member"
+)
+
+// ValidTeamRole reports whether role is one of the team roles.
+func ValidTeamRole(role string) bool {
+	return role == TeamLead || role == TeamMember
+}
+
+// Team is a team, department or project group employees belong to.
+type Team struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"uniqueIndex;not null"`
+	Description string
+	// Open teams may be joined by any employee. Admins add the members
+	// of the others.
+	Open        bool
+	Memberships []TeamMembership `gorm:"constraint:OnDelete:CASCADE;foreignKey:TeamID"`
+	CreatedAt   time.Time
+}
+
+// TeamMembership places an employee on a team in one of the team roles.
+type TeamMembership struct {
+	ID         uint      `gorm:"primaryKey"`
+	TeamID     uint      `gorm:"uniqueIndex:idx_team_membership;not null"`
+	EmployeeID uint      `gorm:"uniqueIndex:idx_team_membership;not null"`
+	Employee   *Employee `gorm:"constraint:OnDelete:CASCADE"`
+	Role       string
+}
+
+// Membership returns the employee's membership of the team, or nil when
+// they are not on it.
+func (t *Team) Membership(employeeID uint) *TeamMembership {
+	for i := range t.Memberships {
+		if t.Memberships[i].EmployeeID == employeeID {
+			return &t.Memberships[i]
+		}
+	}
+	return nil
+}
+
+// Members returns the team's memberships of active employees, leads
+// first and then by name. The employees must have been loaded.
+func (t *Team) Members() []TeamMembership {
+	var members []TeamMembership
+	for _, membership := range t.Memberships {
+		if membership.Employee != nil && membership.Employee.DeactivatedAt == nil {
+			members = append(members, membership)
+		}
+	}
+	slices.SortStableFunc(members, func(a, b TeamMembership) int {
+		if a.Role != b.Role {
+			if a.Role == TeamLead {
+				return -1
+			}
+			if b.Role == TeamLead {
+				return 1
+			}
+		}
+		return strings.Compare(strings.ToLower(a.Employee.Name), strings.ToLower(b.Employee.Name))
+	})
+	return members
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeam_Members(t *testing.T) {
+	now := time.Now()
+	team := &Team{Memberships: []TeamMembership{
+		{EmployeeID: 1, Role: TeamMember, Employee: &Employee{ID: 1, Name: "zed"}},
+		{EmployeeID: 2, Role: TeamMember, Employee: &Employee{ID: 2, Name: "Amy"}},
+		{EmployeeID: 3, Role: TeamLead, Employee: &Employee{ID: 3, Name: "Yolanda"}},
+		{EmployeeID: 4, Role: TeamMember, Employee: &Employee{ID: 4, Name: "Bob", DeactivatedAt: &now}},
+	}}
+
+	var names []string
+	for _, membership := range team.Members() {
+		names = append(names, membership.Employee.Name)
+	}
+	assert.Equal(t, []string{"Yolanda", "Amy", "zed"}, names, "Leads should come first and deactivated employees be left out")
+	assert.Equal(t, TeamLead, team.Membership(3).Role)
+	assert.Nil(t, team.Membership(5))
+}
+
+func TestValidTeamRole(t *testing.T) {
+	assert.True(t, ValidTeamRole(TeamLead))
+	assert.True(t, ValidTeamRole(TeamMember))
+	assert.False(t, ValidTeamRole("owner"))
+}
@@ -0,0 +1,98 @@
+// Package photos processes and stores the profile photos employees
+// upload. Every upload is stored at each of Sizes under a new random key
+// so that a photo's address never changes and it can be cached forever.
+package photos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+)
+
+var (
+	storage        Storage
+	maxUploadBytes = int64(config.Default().Photos.MaxUploadBytes)
+)
+
+// Configure creates the storage described by settings and uses it for
+// every photo saved or served afterwards.
+func Configure(ctx context.Context, settings config.Photos) error {
+	configured, err := NewStorage(ctx, settings)
+	if err != nil {
+		return err
+	}
+	storage = configured
+	maxUploadBytes = int64(settings.MaxUploadBytes)
+	slog.Info("photo storage configured", slog.String("storage", settings.Storage))
+	return nil
+}
+
+// MaxUploadBytes is the size of the largest photo which may be uploaded.
+func MaxUploadBytes() int64 {
+	return maxUploadBytes
+}
+
+// namePattern matches the file names built by FileName, which are the
+// only names Open will look up.
+var namePattern = regexp.MustCompile(`^[0-9a-f]{32}-[a-z]+\.jpg$`)
+
+// FileName returns the name the photo stored under key has at size.
+func FileName(key string, size Size) string {
+	return key + "-" + size.Name + ".jpg"
+}
+
+// Save processes an uploaded image, stores it at every size and returns
+// the key it was stored under.
+func Save(ctx context.Context, data []byte) (string, error) {
+	if storage == nil {
+		return "", errors.New("photo storage has not been configured")
+	}
+	encoded, err := Process(data)
+	if err != nil {
+		return "", err
+	}
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(random)
+	for size, photo := range encoded {
+		if err := storage.Put(ctx, FileName(key, size), photo); err != nil {
+			return "", err
+		}
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "photo saved", slog.String("photo", key))
+	return key, nil
+}
+
+// Open returns the JPEG stored under name, which is one of the file
+// names linked to by model.Employee.PhotoURL. Unknown and malformed
+// names are reported as ErrNotFound.
+func Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	if storage == nil {
+		return nil, errors.New("photo storage has not been configured")
+	}
+	if !namePattern.MatchString(name) {
+		return nil, ErrNotFound
+	}
+	return storage.Get(ctx, name)
+}
+
+// Remove deletes every size of the photo stored under key.
+func Remove(ctx context.Context, key string) error {
+	if storage == nil {
+		return errors.New("photo storage has not been configured")
+	}
+	var problems []error
+	for _, size := range Sizes {
+		problems = append(problems, storage.Delete(ctx, FileName(key, size)))
+	}
+	return errors.Join(problems...)
+}
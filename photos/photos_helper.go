@@ -0,0 +1,19 @@
+//go:build !production
+
+package photos
+
+import "testing"
+
+// ConfigureStorageForTest stores photos in a temporary directory for the
+// rest of the test and restores the previous storage afterwards.
+func ConfigureStorageForTest(t testing.TB) {
+	originalStorage := storage
+	t.Cleanup(func() {
+		storage = originalStorage
+	})
+	local, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage = local
+}
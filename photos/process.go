@@ -0,0 +1,227 @@
+package photos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+
+	// Registered so that image.Decode accepts these formats.
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Size is one of the square sizes every photo is stored at.
+type Size struct {
+	Name   string
+	Pixels int
+}
+
+var (
+	// Thumbnail is shown beside names in the directory.
+	Thumbnail = Size{Name: "thumb", Pixels: 64}
+	// Full is shown on the employee's card.
+	Full = Size{Name: "full", Pixels: 400}
+
+	Sizes = []Size{Thumbnail, Full}
+)
+
+// SizeNamed returns the size with the given name.
+func SizeNamed(name string) (Size, bool) {
+	for _, size := range Sizes {
+		if size.Name == name {
+			return size, true
+		}
+	}
+	return Size{}, false
+}
+
+// ContentType is the media type of every stored photo.
+const ContentType = "image/jpeg"
+
+const (
+	jpegQuality = 85
+	// maxPixels guards against images which are small on disk but
+	// enormous once decoded. It allows the photos of any current phone
+	// camera.
+	maxPixels = 16_000_000
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("photos must be JPEG, PNG, GIF or WebP images")
+	ErrTooManyPixels     = errors.New("photo dimensions are too large")
+)
+
+var acceptedContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Process decodes an uploaded image, crops the largest centered square
+// from it, rotates it upright and encodes it as a JPEG at every size.
+// The square is shrunk to the largest size before it is rotated so that
+// rotation never has to handle the full image. The format is sniffed from the content rather than trusted from
+// the upload. Re-encoding drops all metadata, including EXIF location.
+func Process(data []byte) (map[Size][]byte, error) {
+	sniffed := http.DetectContentType(data)
+	if !acceptedContentTypes[sniffed] {
+		return nil, ErrUnsupportedFormat
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrTooManyPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	square := centerSquare(img.Bounds())
+	largest := 0
+	for _, size := range Sizes {
+		largest = max(largest, size.Pixels)
+	}
+	side := min(largest, square.Dx())
+	base := image.NewRGBA(image.Rect(0, 0, side, side))
+	// JPEG has no transparency, so transparent areas become white.
+	draw.Draw(base, base.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(base, base.Bounds(), img, square, draw.Over, nil)
+	if sniffed == "image/jpeg" {
+		base = orient(base, exifOrientation(data))
+	}
+
+	encoded := make(map[Size][]byte, len(Sizes))
+	for _, size := range Sizes {
+		resized := base
+		if size.Pixels < side {
+			resized = image.NewRGBA(image.Rect(0, 0, size.Pixels, size.Pixels))
+			draw.CatmullRom.Scale(resized, resized.Bounds(), base, base.Bounds(), draw.Src, nil)
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		encoded[size] = buf.Bytes()
+	}
+	return encoded, nil
+}
+
+// centerSquare returns the largest square centered within bounds.
+func centerSquare(bounds image.Rectangle) image.Rectangle {
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+// exifOrientation returns the EXIF orientation (1 to 8) recorded in a
+// JPEG, or 1, meaning upright, when there is none.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	remaining := data[2:]
+	for len(remaining) >= 4 && remaining[0] == 0xFF {
+		marker := remaining[1]
+		length := int(binary.BigEndian.Uint16(remaining[2:4]))
+		if length < 2 || len(remaining) < 2+length {
+			return 1
+		}
+		segment := remaining[4 : 2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		if marker == 0xDA {
+			// Image data follows the start of scan, so there are no more
+			// metadata segments.
+			return 1
+		}
+		remaining = remaining[2+length:]
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	directory := int(order.Uint32(tiff[4:8]))
+	if directory < 8 || directory+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[directory:]))
+	for i := range entries {
+		entry := directory + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if orientation := int(order.Uint16(tiff[entry+8:])); orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient transforms img so that a photo taken with the given EXIF
+// orientation appears upright once the EXIF data is gone. Pixels are
+// copied directly between the Pix slices.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if orientation >= 5 {
+		width, height = height, width
+	}
+	oriented := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = bounds.Dx()-1-x, y
+			case 3: // upside down
+				sx, sy = bounds.Dx()-1-x, bounds.Dy()-1-y
+			case 4: // mirrored upside down
+				sx, sy = x, bounds.Dy()-1-y
+			case 5: // mirrored and rotated a quarter turn counterclockwise
+				sx, sy = y, x
+			case 6: // rotated a quarter turn counterclockwise
+				sx, sy = y, bounds.Dy()-1-x
+			case 7: // mirrored and rotated a quarter turn clockwise
+				sx, sy = bounds.Dx()-1-y, bounds.Dy()-1-x
+			case 8: // rotated a quarter turn clockwise
+				sx, sy = bounds.Dx()-1-y, x
+			}
+			from := img.PixOffset(bounds.Min.X+sx, bounds.Min.Y+sy)
+			to := oriented.PixOffset(x, y)
+			copy(oriented.Pix[to:to+4], img.Pix[from:from+4])
+		}
+	}
+	return oriented
+}
@@ -0,0 +1,169 @@
+package photos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+)
+
+// bands draws an image split into vertical bands of the given colors.
+func bands(width int, height int, colors ...color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, colors[x*len(colors)/width])
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF segment recording orientation into a
+// JPEG, along with a camera model so that stripping can be checked.
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "SecretPhone 9"...)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	var out []byte
+	out = append(out, data[:2]...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func decode(t *testing.T, data []byte) image.Image {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	return img
+}
+
+// dominant reports which of red, green or blue is strongest at x, y,
+// allowing for JPEG compression.
+func dominant(img image.Image, x int, y int) color.RGBA {
+	r, g, b, _ := img.At(x, y).RGBA()
+	switch {
+	case r > g && r > b:
+		return red
+	case g > r && g > b:
+		return green
+	default:
+		return blue
+	}
+}
+
+func TestProcess_CropsCenterSquareAtEverySize(t *testing.T) {
+	data := encodePNG(t, bands(600, 200, red, green, blue))
+
+	encoded, err := Process(data)
+
+	assert.NoError(t, err)
+	assert.Len(t, encoded, len(Sizes))
+	full := decode(t, encoded[Full])
+	assert.Equal(t, image.Rect(0, 0, 200, 200), full.Bounds(), "Small photos should not be enlarged")
+	assert.Equal(t, green, dominant(full, 5, 100), "The sides should be cropped away")
+	assert.Equal(t, green, dominant(full, 195, 100), "The sides should be cropped away")
+	thumbnail := decode(t, encoded[Thumbnail])
+	assert.Equal(t, image.Rect(0, 0, Thumbnail.Pixels, Thumbnail.Pixels), thumbnail.Bounds())
+}
+
+func TestProcess_ShrinksLargePhotos(t *testing.T) {
+	data := encodePNG(t, bands(1000, 900, green))
+
+	encoded, err := Process(data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, Full.Pixels, Full.Pixels), decode(t, encoded[Full]).Bounds())
+}
+
+func TestProcess_AppliesOrientationAndStripsEXIF(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, bands(80, 40, red, blue), &jpeg.Options{Quality: 95}))
+	// Orientation 6 means the camera was turned a quarter turn, so the
+	// left of the stored image is the top of the photo.
+	data := withOrientation(buf.Bytes(), 6)
+
+	encoded, err := Process(data)
+
+	assert.NoError(t, err)
+	full := decode(t, encoded[Full])
+	assert.Equal(t, image.Rect(0, 0, 40, 40), full.Bounds())
+	assert.Equal(t, red, dominant(full, 20, 5))
+	assert.Equal(t, blue, dominant(full, 20, 35))
+	for _, photo := range encoded {
+		assert.NotContains(t, string(photo), "Exif")
+		assert.NotContains(t, string(photo), "SecretPhone")
+	}
+}
+
+func TestOrient(t *testing.T) {
+	img := bands(2, 1, red, blue)
+
+	assert.Same(t, img, orient(img, 1))
+	for orientation, want := range map[int][]color.RGBA{
+		2: {blue, red},
+		3: {blue, red},
+		6: {red, blue},
+		8: {blue, red},
+	} {
+		oriented := orient(img, orientation)
+		if orientation >= 5 {
+			assert.Equal(t, image.Rect(0, 0, 1, 2), oriented.Bounds(), "orientation %d", orientation)
+			assert.Equal(t, want, []color.RGBA{oriented.RGBAAt(0, 0), oriented.RGBAAt(0, 1)}, "orientation %d", orientation)
+		} else {
+			assert.Equal(t, image.Rect(0, 0, 2, 1), oriented.Bounds(), "orientation %d", orientation)
+			assert.Equal(t, want, []color.RGBA{oriented.RGBAAt(0, 0), oriented.RGBAAt(1, 0)}, "orientation %d", orientation)
+		}
+	}
+}
+
+func TestProcess_RejectsOtherContent(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"text":      []byte("this is not a photo"),
+		"html":      []byte("<html><body><img src=x onerror=alert(1)></body></html>"),
+		"svg":       []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`),
+		"truncated": []byte("\x89PNG\r\n\x1a\n\x00\x00"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Process(data)
+			assert.ErrorIs(t, err, ErrUnsupportedFormat)
+		})
+	}
+}
+
+func TestExifOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, bands(4, 4, red), nil))
+
+	assert.Equal(t, 1, exifOrientation(buf.Bytes()))
+	for orientation := uint16(1); orientation <= 8; orientation++ {
+		assert.Equal(t, int(orientation), exifOrientation(withOrientation(buf.Bytes(), orientation)))
+	}
+	assert.Equal(t, 1, exifOrientation(withOrientation(buf.Bytes(), 42)))
+	assert.Equal(t, 1, exifOrientation([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF}))
+}
@@ -0,0 +1,67 @@
+package photos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage keeps photos in a bucket of any S3-compatible object store,
+// such as AWS S3 or MinIO.
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Storage connects to the bucket, creating it when it does not
+// exist yet.
+func NewS3Storage(ctx context.Context, settings config.S3) (*S3Storage, error) {
+	client, err := minio.New(settings.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(settings.AccessKeyID, settings.SecretAccessKey, ""),
+		Secure: !settings.Insecure,
+		Region: settings.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating S3 client: %w", err)
+	}
+	exists, err := client.BucketExists(ctx, settings.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("checking photo bucket %q: %w", settings.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, settings.Bucket, minio.MakeBucketOptions{Region: settings.Region}); err != nil {
+			return nil, fmt.Errorf("creating photo bucket %q: %w", settings.Bucket, err)
+		}
+	}
+	return &S3Storage{client: client, bucket: settings.Bucket}, nil
+}
+
+// Put implements Storage.
+func (s *S3Storage) Put(ctx context.Context, name string, data []byte) error {
+	_, err := s.client.PutObject(ctx, s.bucket, name, bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: ContentType})
+	return err
+}
+
+// Get implements Storage.
+func (s *S3Storage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	// GetObject does not contact the store, so stat first to report a
+	// missing photo as ErrNotFound rather than as a failed read.
+	if _, err := s.client.StatObject(ctx, s.bucket, name, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.client.GetObject(ctx, s.bucket, name, minio.GetObjectOptions{})
+}
+
+// Delete implements Storage.
+func (s *S3Storage) Delete(ctx context.Context, name string) error {
+	return s.client.RemoveObject(ctx, s.bucket, name, minio.RemoveObjectOptions{})
+}
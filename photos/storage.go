@@ -0,0 +1,103 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jeffscottbrown/satchel/config"
+)
+
+// ErrNotFound is returned when a stored photo does not exist.
+var ErrNotFound = errors.New("photo not found")
+
+// Storage keeps the encoded photos, addressed by the file names built by
+// FileName.
+type Storage interface {
+	Put(ctx context.Context, name string, data []byte) error
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// NewStorage returns the storage selected by settings.Storage.
+func NewStorage(ctx context.Context, settings config.Photos) (Storage, error) {
+	switch settings.Storage {
+	case "local":
+		return NewLocalStorage(settings.Directory)
+	case "s3":
+		return NewS3Storage(ctx, settings.S3)
+	default:
+		return nil, fmt.Errorf("unsupported photo storage %q", settings.Storage)
+	}
+}
+
+// LocalStorage keeps photos as files in a directory.
+type LocalStorage struct {
+	directory string
+}
+
+// NewLocalStorage creates directory if it does not already exist.
+func NewLocalStorage(directory string) (*LocalStorage, error) {
+	if err := os.MkdirAll(directory, 0o750); err != nil {
+		return nil, fmt.Errorf("creating photo directory: %w", err)
+	}
+	return &LocalStorage{directory: directory}, nil
+}
+
+func (s *LocalStorage) path(name string) (string, error) {
+	if name == "" || filepath.Base(name) != name || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid photo name %q", name)
+	}
+	return filepath.Join(s.directory, name), nil
+}
+
+// Put implements Storage. The file is written under a temporary name and
+// renamed so that a partially written photo is never served.
+func (s *LocalStorage) Put(ctx context.Context, name string, data []byte) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	temporary, err := os.CreateTemp(s.directory, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporary.Name())
+	if _, err := temporary.Write(data); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporary.Name(), path)
+}
+
+// Get implements Storage.
+func (s *LocalStorage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete implements Storage. Deleting a photo which does not exist is
+// not an error.
+func (s *LocalStorage) Delete(ctx context.Context, name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
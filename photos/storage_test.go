@@ -0,0 +1,116 @@
+package photos
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// exerciseStorage checks the behavior every Storage must share.
+func exerciseStorage(t *testing.T, storage Storage) {
+	ctx := t.Context()
+	name := "0123456789abcdef0123456789abcdef-full.jpg"
+
+	_, err := storage.Get(ctx, name)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, storage.Put(ctx, name, []byte("first")))
+	assert.NoError(t, storage.Put(ctx, name, []byte("second")))
+	reader, err := storage.Get(ctx, name)
+	if assert.NoError(t, err) {
+		contents, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, "second", string(contents))
+		assert.NoError(t, reader.Close())
+	}
+
+	assert.NoError(t, storage.Delete(ctx, name))
+	_, err = storage.Get(ctx, name)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, storage.Delete(ctx, name), "Deleting a missing photo should succeed")
+}
+
+func TestLocalStorage(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	exerciseStorage(t, storage)
+
+	assert.Error(t, storage.Put(t.Context(), "../escape.jpg", []byte("x")))
+	_, err = storage.Get(t.Context(), "../../etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestS3Storage_MinIO(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "minio/minio:latest",
+			ExposedPorts: []string{"9000/tcp"},
+			Cmd:          []string{"server", "/data"},
+			Env: map[string]string{
+				"MINIO_ROOT_USER":     "satchel",
+				"MINIO_ROOT_PASSWORD": "satchel-secret",
+			},
+			WaitingFor: wait.ForHTTP("/minio/health/live").WithPort("9000/tcp").WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	t.Cleanup(func() {
+		_ = container.Terminate(context.Background())
+	})
+	endpoint, err := container.PortEndpoint(ctx, "9000/tcp", "")
+	assert.NoError(t, err)
+
+	storage, err := NewS3Storage(ctx, config.S3{
+		Endpoint:        endpoint,
+		Bucket:          "photos",
+		AccessKeyID:     "satchel",
+		SecretAccessKey: "satchel-secret",
+		Insecure:        true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	exerciseStorage(t, storage)
+}
+
+func TestSaveOpenAndRemove(t *testing.T) {
+	ConfigureStorageForTest(t)
+	ctx := t.Context()
+
+	key, err := Save(ctx, encodePNG(t, bands(100, 100, green)))
+
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{32}$`, key)
+	for _, size := range Sizes {
+		reader, err := Open(ctx, key+"-"+size.Name+".jpg")
+		if assert.NoError(t, err) {
+			decode(t, readAll(t, reader))
+		}
+	}
+	_, err = Open(ctx, "../"+key+"-full.jpg")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, Remove(ctx, key))
+	_, err = Open(ctx, key+"-full.jpg")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func readAll(t *testing.T, reader io.ReadCloser) []byte {
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return data
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"log/slog"
+
+	"github.com/jeffscottbrown/satchel/markdown"
+	"github.com/jeffscottbrown/satchel/model"
+	"gorm.io/gorm"
+)
+
+// fillBioText sets BioText for the employees whose bios were written
+// before it was kept, which SaveEmployee now does. It only needs to run
+// once, so useDatabase runs it with runOnce.
+func fillBioText(db *gorm.DB) error {
+	var employees []model.Employee
+	if err := db.Select("id", "bio").Where("bio <> '' AND (bio_text = '' OR bio_text IS NULL)").Find(&employees).Error; err != nil {
+		return err
+	}
+	for i := range employees {
+		bioText := markdown.PlainText(employees[i].Bio)
+		if err := db.Model(&model.Employee{}).Where("id = ?", employees[i].ID).Update("bio_text", bioText).Error; err != nil {
+			return err
+		}
+	}
+	if len(employees) > 0 {
+		slog.Info("plain text bios filled in", slog.Int("count", len(employees)))
+	}
+	return nil
+}
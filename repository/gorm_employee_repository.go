@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
+	"strings"
 	"time"
 
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/metrics"
 	"github.com/jeffscottbrown/satchel/model"
-	"github.com/jeffscottbrown/satchel/utils"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 type gormEmployeeDb struct {
@@ -18,104 +21,484 @@ type gormEmployeeDb struct {
 }
 
 // DeleteEmployee implements EmployeeRepository.
-func (r *gormEmployeeDb) DeleteEmployee(email string) error {
-	emp, err := r.GetEmployeeByEmail(email)
+func (r *gormEmployeeDb) DeleteEmployee(ctx context.Context, email string) error {
+	emp, err := r.GetEmployeeByEmail(ctx, email)
 	if err != nil {
-		slog.Error("failed to find employee by email", slog.Any("error", err), slog.String("email", email))
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to find employee by email", slog.Any("error", err), slog.String("email", email))
 		return err
 	}
-	if err := r.db.WithContext(context.Background()).Where("employee_id = ?", emp.ID).Delete(&model.Reflection{}).Error; err != nil {
-		slog.Error("failed to delete reflections for employee", slog.Any("error", err), slog.Any("employeeId", emp.ID))
+	if err := r.db.WithContext(ctx).Where("employee_id = ?", emp.ID).Delete(&model.Reflection{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete reflections for employee", slog.Any("error", err), slog.Any("employeeId", emp.ID))
 		return err
 	}
-	if err := r.db.WithContext(context.Background()).Delete(&model.Employee{}, emp.ID).Error; err != nil {
-		slog.Error("failed to delete employee", slog.Any("error", err), slog.Any("employeeId", emp.ID))
+	if err := r.db.WithContext(ctx).Where("employee_id = ?", emp.ID).Delete(&model.APIToken{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete API tokens for employee", slog.Any("error", err), slog.Any("employeeId", emp.ID))
 		return err
 	}
-	slog.Info("employee deleted successfully", slog.String("email", email))
+	if err := r.db.WithContext(ctx).Where("employee_email = ?", emp.Email).Delete(&model.Session{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete sessions for employee", slog.Any("error", err), slog.Any("employeeId", emp.ID))
+		return err
+	}
+	if err := r.db.WithContext(ctx).Where("employee_id = ?", emp.ID).Delete(&model.TeamMembership{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete team memberships for employee", slog.Any("error", err), slog.Any("employeeId", emp.ID))
+		return err
+	}
+	skills := r.db.Model(&model.EmployeeSkill{}).Select("id").Where("employee_id = ?", emp.ID)
+	if err := r.db.WithContext(ctx).Where("endorser_id = ? OR employee_skill_id IN (?)", emp.ID, skills).Delete(&model.Endorsement{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete endorsements for employee", slog.Any("error", err), slog.Any("employeeId", emp.ID))
+		return err
+	}
+	if err := r.db.WithContext(ctx).Where("employee_id = ?", emp.ID).Delete(&model.EmployeeSkill{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete skills for employee", slog.Any("error", err), slog.Any("employeeId", emp.ID))
+		return err
+	}
+	if err := r.db.WithContext(ctx).Delete(&model.Employee{}, emp.ID).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete employee", slog.Any("error", err), slog.Any("employeeId", emp.ID))
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "employee deleted successfully", slog.String("email", email))
 	return nil
 }
 
 // DeleteReflection implements repository.EmployeeRepository.
-func (r *gormEmployeeDb) DeleteReflection(reflectionId uint) error {
-	if err := r.db.WithContext(context.Background()).Delete(&model.Reflection{}, reflectionId).Error; err != nil {
-		slog.Error("failed to delete reflection", slog.Any("error", err), slog.Any("reflectionId", reflectionId))
+func (r *gormEmployeeDb) DeleteReflection(ctx context.Context, reflectionId uint) error {
+	if err := r.db.WithContext(ctx).Delete(&model.Reflection{}, reflectionId).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete reflection", slog.Any("error", err), slog.Any("reflectionId", reflectionId))
 		return err
 	}
-	slog.Info("reflection deleted successfully", slog.Any("reflectionId", reflectionId))
+	logging.FromContext(ctx).InfoContext(ctx, "reflection deleted successfully", slog.Any("reflectionId", reflectionId))
 	return nil
 }
 
 // SaveEmployee implements repository.EmployeeRepository.
-func (r *gormEmployeeDb) SaveEmployee(employee *model.Employee) error {
-	// Use the context.Background() to avoid passing nil context
-	ctx := context.Background()
-	if err := r.db.WithContext(ctx).Save(employee).Error; err != nil {
-		slog.Error("failed to save employee", slog.Any("error", err))
+// Employees refer to their manager by email, so when the employee's
+// email changes, as it may over SCIM, their reports are moved to the
+// new email in the same transaction.
+func (r *gormEmployeeDb) SaveEmployee(ctx context.Context, employee *model.Employee) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var previous []string
+		if employee.ID != 0 {
+			if err := tx.Model(&model.Employee{}).Where("id = ?", employee.ID).Pluck("email", &previous).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(employee).Error; err != nil {
+			return err
+		}
+		if len(previous) == 0 || strings.EqualFold(previous[0], employee.Email) {
+			return nil
+		}
+		return tx.Model(&model.Employee{}).Where("LOWER(manager_email) = LOWER(?)", previous[0]).Update("manager_email", employee.Email).Error
+	})
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save employee", slog.Any("error", err))
 		return err
 	}
-	slog.Info("employee saved successfully", slog.String("name", employee.Name))
+	logging.FromContext(ctx).InfoContext(ctx, "employee saved successfully", slog.String("name", employee.Name))
 	return nil
 }
 
-// GetEmployeeByName implements repository.EmployeeRepository.
-func (r *gormEmployeeDb) GetEmployeeByEmail(email string) (model.Employee, error) {
-	var employee model.Employee
-	err := r.db.WithContext(context.Background()).Preload("Reflections").Where("email = ?", email).First(&employee).Error
+// SaveAvatar implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SaveAvatar(ctx context.Context, employeeID uint, avatar string, source string) error {
+	return r.db.WithContext(ctx).Model(&model.Employee{}).Where("id = ?", employeeID).
+		Updates(map[string]any{"avatar": avatar, "avatar_source": source}).Error
+}
+
+// SaveRemindedAt implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SaveRemindedAt(ctx context.Context, employeeID uint, remindedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.Employee{}).Where("id = ?", employeeID).
+		Update("reminded_at", remindedAt).Error
+}
+
+// GetEmployeeByEmail implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetEmployeeByEmail(ctx context.Context, email string) (employee model.Employee, err error) {
+	err = r.db.WithContext(ctx).Preload("Reflections").Where("email = ?", email).First(&employee).Error
+	return
+}
+
+// GetEmployees implements repository.EmployeeRepository. Deactivated
+// employees are not included.
+func (r *gormEmployeeDb) GetEmployees(ctx context.Context) ([]model.Employee, error) {
+	var employees []model.Employee
+	err := r.db.WithContext(ctx).Where("deactivated_at IS NULL").Order("last_name").Order("first_name").Find(&employees).Error
 	if err != nil {
-		return model.Employee{}, err
+		return nil, err
 	}
-	return employee, nil
+	return employees, nil
 }
 
-// GetEmployees implements repository.EmployeeRepository.
-func (r *gormEmployeeDb) GetEmployees() ([]model.Employee, error) {
+// GetEmployeesWithReflections implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetEmployeesWithReflections(ctx context.Context) ([]model.Employee, error) {
 	var employees []model.Employee
-	err := r.db.WithContext(context.Background()).Order("last_name").Order("first_name").Find(&employees).Error
+	err := r.db.WithContext(ctx).Preload("Reflections").Where("deactivated_at IS NULL").Order("last_name").Order("first_name").Find(&employees).Error
 	if err != nil {
 		return nil, err
 	}
 	return employees, nil
 }
 
-func NewGormEmployeeRepository(db *gorm.DB) EmployeeRepository {
-	return &gormEmployeeDb{db: db}
+// GetAllEmployees implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetAllEmployees(ctx context.Context) ([]model.Employee, error) {
+	var employees []model.Employee
+	err := r.db.WithContext(ctx).Order("id").Find(&employees).Error
+	if err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+// GetEmployeeByID implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetEmployeeByID(ctx context.Context, id uint) (*model.Employee, error) {
+	var employee model.Employee
+	err := r.db.WithContext(ctx).Preload("Reflections").First(&employee, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+// CountEmployees implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) CountEmployees(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Employee{}).Count(&count).Error
+	return count, err
+}
+
+// CountEmployeesWithoutBio implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) CountEmployeesWithoutBio(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Employee{}).Where("bio IS NULL OR TRIM(bio) = ''").Count(&count).Error
+	return count, err
+}
+
+// SaveAPIToken implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SaveAPIToken(ctx context.Context, token *model.APIToken) error {
+	if err := r.db.WithContext(ctx).Save(token).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save API token", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// GetAPITokens implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetAPITokens(ctx context.Context, employeeID uint) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	err := r.db.WithContext(ctx).Where("employee_id = ?", employeeID).Order("created_at DESC").Order("id DESC").Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetAPITokenByHash implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetAPITokenByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	var token model.APIToken
+	err := r.db.WithContext(ctx).Where("hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteAPIToken implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) DeleteAPIToken(ctx context.Context, employeeID uint, id uint) error {
+	result := r.db.WithContext(ctx).Where("employee_id = ?", employeeID).Delete(&model.APIToken{}, id)
+	if result.Error != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete API token", slog.Any("error", result.Error), slog.Any("tokenId", id))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "API token revoked", slog.Any("tokenId", id))
+	return nil
+}
+
+// SaveSession implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SaveSession(ctx context.Context, session *model.Session) error {
+	if err := r.db.WithContext(ctx).Save(session).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save session", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// GetSessionByKeyHash implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetSessionByKeyHash(ctx context.Context, keyHash string) (*model.Session, error) {
+	var session model.Session
+	err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetSessions implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetSessions(ctx context.Context, email string) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.WithContext(ctx).Where("employee_email = ?", email).Order("last_seen_at DESC").Order("id DESC").Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// DeleteSession implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) DeleteSession(ctx context.Context, email string, id uint) error {
+	result := r.db.WithContext(ctx).Where("employee_email = ?", email).Delete(&model.Session{}, id)
+	if result.Error != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete session", slog.Any("error", result.Error), slog.Any("sessionId", id))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "session revoked", slog.String("email", email), slog.Any("sessionId", id))
+	return nil
+}
+
+// DeleteSessions implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) DeleteSessions(ctx context.Context, email string) (int64, error) {
+	result := r.db.WithContext(ctx).Where("employee_email = ?", email).Delete(&model.Session{})
+	if result.Error != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete sessions", slog.Any("error", result.Error), slog.String("email", email))
+		return 0, result.Error
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "sessions revoked", slog.String("email", email), slog.Int64("count", result.RowsAffected))
+	return result.RowsAffected, nil
+}
+
+// SaveReflection implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SaveReflection(ctx context.Context, reflection *model.Reflection) error {
+	if err := r.db.WithContext(ctx).Save(reflection).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save reflection", slog.Any("error", err), slog.Any("reflectionId", reflection.ID))
+		return err
+	}
+	return nil
+}
+
+// SavePrompt implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SavePrompt(ctx context.Context, prompt *model.Prompt) error {
+	if err := r.db.WithContext(ctx).Save(prompt).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save prompt", slog.Any("error", err))
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "prompt saved", slog.String("question", prompt.Question), slog.Bool("active", prompt.Active))
+	return nil
+}
+
+// GetPrompts implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetPrompts(ctx context.Context) ([]model.Prompt, error) {
+	var prompts []model.Prompt
+	err := r.db.WithContext(ctx).Order("category").Order("question").Find(&prompts).Error
+	if err != nil {
+		return nil, err
+	}
+	return prompts, nil
+}
+
+// GetActivePrompts implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetActivePrompts(ctx context.Context) ([]model.Prompt, error) {
+	var prompts []model.Prompt
+	err := r.db.WithContext(ctx).Where("active = ?", true).Order("category").Order("question").Find(&prompts).Error
+	if err != nil {
+		return nil, err
+	}
+	return prompts, nil
 }
 
-func InitializeDatabase() {
-	dbUser := utils.RetrieveSecretValue("SATCHEL_DB_USER")
-	if dbUser == "" {
-		slog.Error("environment variable SATCHEL_DB_USER not set")
-		os.Exit(1)
+// GetPrompt implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetPrompt(ctx context.Context, id uint) (*model.Prompt, error) {
+	var prompt model.Prompt
+	if err := r.db.WithContext(ctx).First(&prompt, id).Error; err != nil {
+		return nil, err
 	}
-	dbPassword := utils.RetrieveSecretValue("SATCHEL_DB_PASSWORD")
-	if dbPassword == "" {
-		slog.Error("environment variable SATCHEL_DB_PASSWORD not set")
-		os.Exit(1)
+	return &prompt, nil
+}
+
+// SaveTeam implements repository.EmployeeRepository. Memberships are
+// saved with SaveTeamMembership.
+func (r *gormEmployeeDb) SaveTeam(ctx context.Context, team *model.Team) error {
+	if err := r.db.WithContext(ctx).Omit("Memberships").Save(team).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save team", slog.Any("error", err))
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "team saved", slog.String("name", team.Name), slog.Bool("open", team.Open))
+	return nil
+}
+
+// GetTeams implements repository.EmployeeRepository. Memberships are
+// loaded without their employees.
+func (r *gormEmployeeDb) GetTeams(ctx context.Context) ([]model.Team, error) {
+	var teams []model.Team
+	err := r.db.WithContext(ctx).Preload("Memberships").Order("name").Find(&teams).Error
+	if err != nil {
+		return nil, err
 	}
-	dbName := utils.RetrieveSecretValue("SATCHEL_DB_NAME")
-	if dbName == "" {
-		slog.Error("environment variable SATCHEL_DB_NAME not set")
-		os.Exit(1)
+	return teams, nil
+}
+
+// GetTeam implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetTeam(ctx context.Context, id uint) (*model.Team, error) {
+	var team model.Team
+	err := r.db.WithContext(ctx).Preload("Memberships.Employee").First(&team, id).Error
+	if err != nil {
+		return nil, err
 	}
-	dbHost := utils.RetrieveSecretValue("SATCHEL_DB_HOST")
-	if dbHost == "" {
-		slog.Error("environment variable SATCHEL_DB_HOST not set")
-		os.Exit(1)
+	return &team, nil
+}
+
+// SaveTeamMembership implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SaveTeamMembership(ctx context.Context, membership *model.TeamMembership) error {
+	if err := r.db.WithContext(ctx).Omit("Employee").Save(membership).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save team membership", slog.Any("error", err))
+		return err
 	}
-	dbPort := utils.RetrieveSecretValue("SATCHEL_DB_PORT")
-	if dbPort == "" {
-		slog.Error("environment variable SATCHEL_DB_PORT not set")
-		os.Exit(1)
+	logging.FromContext(ctx).InfoContext(ctx, "team membership saved", slog.Any("teamId", membership.TeamID), slog.Any("employeeId", membership.EmployeeID), slog.String("role", membership.Role))
+	return nil
+}
+
+// DeleteTeamMembership implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) DeleteTeamMembership(ctx context.Context, teamID uint, employeeID uint) error {
+	if err := r.db.WithContext(ctx).Where("team_id = ? AND employee_id = ?", teamID, employeeID).Delete(&model.TeamMembership{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete team membership", slog.Any("error", err))
+		return err
 	}
-	connStr := fmt.Sprintf("user=%s password=%s dbname=%s host=%s port=%s sslmode=disable", dbUser, dbPassword, dbName, dbHost, dbPort)
+	logging.FromContext(ctx).InfoContext(ctx, "team membership deleted", slog.Any("teamId", teamID), slog.Any("employeeId", employeeID))
+	return nil
+}
+
+// SaveSkill implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SaveSkill(ctx context.Context, skill *model.Skill) error {
+	if err := r.db.WithContext(ctx).Save(skill).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save skill", slog.Any("error", err))
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "skill saved", slog.String("name", skill.Name))
+	return nil
+}
+
+// GetSkills implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetSkills(ctx context.Context) ([]model.Skill, error) {
+	var skills []model.Skill
+	err := r.db.WithContext(ctx).Order("name").Find(&skills).Error
+	if err != nil {
+		return nil, err
+	}
+	return skills, nil
+}
+
+// GetSkill implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetSkill(ctx context.Context, id uint) (*model.Skill, error) {
+	var skill model.Skill
+	if err := r.db.WithContext(ctx).First(&skill, id).Error; err != nil {
+		return nil, err
+	}
+	return &skill, nil
+}
+
+// GetEmployeeSkills implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) GetEmployeeSkills(ctx context.Context, employeeID uint) ([]model.EmployeeSkill, error) {
+	var skills []model.EmployeeSkill
+	err := r.db.WithContext(ctx).Preload("Skill").Preload("Endorsements.Endorser").
+		Where("employee_id = ?", employeeID).Order("proficiency DESC").Order("years DESC").Find(&skills).Error
+	if err != nil {
+		return nil, err
+	}
+	return skills, nil
+}
+
+// FindEmployeeSkills implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) FindEmployeeSkills(ctx context.Context, skillID uint, minProficiency int) ([]model.EmployeeSkill, error) {
+	var skills []model.EmployeeSkill
+	err := r.db.WithContext(ctx).Preload("Employee").Preload("Skill").Preload("Endorsements").
+		Where("skill_id = ? AND proficiency >= ?", skillID, minProficiency).
+		Order("proficiency DESC").Order("years DESC").Find(&skills).Error
+	if err != nil {
+		return nil, err
+	}
+	return skills, nil
+}
+
+// SaveEmployeeSkill implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SaveEmployeeSkill(ctx context.Context, employeeSkill *model.EmployeeSkill) error {
+	if err := r.db.WithContext(ctx).Omit("Employee", "Skill", "Endorsements").Save(employeeSkill).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save employee skill", slog.Any("error", err))
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "employee skill saved", slog.Any("employeeId", employeeSkill.EmployeeID), slog.Any("skillId", employeeSkill.SkillID), slog.Int("proficiency", employeeSkill.Proficiency))
+	return nil
+}
+
+// DeleteEmployeeSkill implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) DeleteEmployeeSkill(ctx context.Context, employeeID uint, skillID uint) error {
+	skill := r.db.Model(&model.EmployeeSkill{}).Select("id").Where("employee_id = ? AND skill_id = ?", employeeID, skillID)
+	if err := r.db.WithContext(ctx).Where("employee_skill_id IN (?)", skill).Delete(&model.Endorsement{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete endorsements for employee skill", slog.Any("error", err))
+		return err
+	}
+	if err := r.db.WithContext(ctx).Where("employee_id = ? AND skill_id = ?", employeeID, skillID).Delete(&model.EmployeeSkill{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete employee skill", slog.Any("error", err))
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "employee skill deleted", slog.Any("employeeId", employeeID), slog.Any("skillId", skillID))
+	return nil
+}
+
+// SaveEndorsement implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) SaveEndorsement(ctx context.Context, endorsement *model.Endorsement) error {
+	if err := r.db.WithContext(ctx).Omit("Endorser").Save(endorsement).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to save endorsement", slog.Any("error", err))
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "endorsement saved", slog.Any("employeeSkillId", endorsement.EmployeeSkillID), slog.Any("endorserId", endorsement.EndorserID))
+	return nil
+}
+
+// DeleteEndorsement implements repository.EmployeeRepository.
+func (r *gormEmployeeDb) DeleteEndorsement(ctx context.Context, employeeSkillID uint, endorserID uint) error {
+	if err := r.db.WithContext(ctx).Where("employee_skill_id = ? AND endorser_id = ?", employeeSkillID, endorserID).Delete(&model.Endorsement{}).Error; err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to delete endorsement", slog.Any("error", err))
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "endorsement deleted", slog.Any("employeeSkillId", employeeSkillID), slog.Any("endorserId", endorserID))
+	return nil
+}
+
+func NewGormEmployeeRepository(db *gorm.DB) EmployeeRepository {
+	return &gormEmployeeDb{db: db}
+}
+
+var database *gorm.DB
+
+// CloseDatabase closes the connection pool opened by InitializeDatabase.
+func CloseDatabase() error {
+	if database == nil {
+		return nil
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.Close(); err != nil {
+		return err
+	}
+	database = nil
+	slog.Info("database connection pool closed")
+	return nil
+}
+
+// InitializeDatabase connects to the configured database, retrying a
+// few times to allow for a database which is still starting, and
+// migrates the schema.
+func InitializeDatabase(settings config.Database) error {
+	connStr := settings.ConnectionString()
 
 	var db *gorm.DB
 	var err error
 	for i := 0; i < 3; i++ {
 		db, err = gorm.Open(postgres.Open(connStr), &gorm.Config{})
 		if err == nil {
-			SetRepository(NewGormEmployeeRepository(db))
 			break
 		}
 		if i < 2 {
@@ -125,12 +508,45 @@ func InitializeDatabase() {
 		}
 	}
 	if err != nil {
-		slog.Error("could not connect to database after 3 attempts", slog.Any("error", err))
-		os.Exit(-1)
+		return fmt.Errorf("could not connect to database after 3 attempts: %w", err)
 	}
-	if err := db.AutoMigrate(&model.Employee{}, &model.Reflection{}); err != nil {
-		slog.Error("failed to auto-migrate database", slog.Any("error", err))
-		os.Exit(-1)
+	if err := useDatabase(db); err != nil {
+		return err
 	}
 	slog.Info("database initialized successfully")
+	return nil
+}
+
+// useDatabase instruments and migrates db and then makes it the
+// database behind the package level repository functions.
+func useDatabase(db *gorm.DB) error {
+	if err := db.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics())); err != nil {
+		slog.Error("failed to install database tracing", slog.Any("error", err))
+	}
+	if err := db.AutoMigrate(&model.Employee{}, &model.Reflection{}, &model.APIToken{}, &model.Session{}, &model.Prompt{}, &model.Team{}, &model.TeamMembership{}, &model.Skill{}, &model.EmployeeSkill{}, &model.Endorsement{}, &dataMigration{}); err != nil {
+		return fmt.Errorf("auto-migrating database: %w", err)
+	}
+	if err := seedPrompts(db); err != nil {
+		return fmt.Errorf("seeding prompts: %w", err)
+	}
+	if err := seedSkills(db); err != nil {
+		return fmt.Errorf("seeding skills: %w", err)
+	}
+	if err := runOnce(db, "fill-bio-text", fillBioText); err != nil {
+		return fmt.Errorf("filling in plain text bios: %w", err)
+	}
+	if err := runOnce(db, "mark-onboarding-started", markOnboardingStarted); err != nil {
+		return fmt.Errorf("recording onboarding as started: %w", err)
+	}
+
+	database = db
+	SetRepository(NewInstrumentedEmployeeRepository(NewGormEmployeeRepository(db)))
+
+	if sqlDB, err := db.DB(); err == nil {
+		metrics.RegisterDatabase(sqlDB)
+	}
+	metrics.RegisterProfileStatistics(func() (metrics.ProfileStatistics, error) {
+		return ProfileStatistics(context.Background())
+	})
+	return nil
 }
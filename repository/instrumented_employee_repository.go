@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/metrics"
+	"github.com/jeffscottbrown/satchel/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/jeffscottbrown/satchel/repository")
+
+// instrumentedEmployeeRepository records the latency and errors of every
+// call made to the EmployeeRepository it wraps and wraps each call in a
+// span so that the queries it makes appear beneath it in a trace.
+type instrumentedEmployeeRepository struct {
+	delegate EmployeeRepository
+}
+
+func NewInstrumentedEmployeeRepository(delegate EmployeeRepository) EmployeeRepository {
+	return &instrumentedEmployeeRepository{delegate: delegate}
+}
+
+// start begins the span for operation. The returned function is
+// deferred with a pointer to the named error result so that the error
+// recorded is the one actually returned.
+func start(ctx context.Context, operation string, attributes ...attribute.KeyValue) (context.Context, func(*error)) {
+	began := time.Now()
+	ctx, span := tracer.Start(ctx, "repository."+operation,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attributes...))
+	return ctx, func(err *error) {
+		metrics.ObserveRepositoryOperation(operation, began, *err)
+		if *err != nil {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
+
+func (r *instrumentedEmployeeRepository) GetEmployees(ctx context.Context) (employees []model.Employee, err error) {
+	ctx, end := start(ctx, "GetEmployees")
+	defer end(&err)
+	return r.delegate.GetEmployees(ctx)
+}
+
+func (r *instrumentedEmployeeRepository) GetEmployeeByEmail(ctx context.Context, email string) (employee model.Employee, err error) {
+	ctx, end := start(ctx, "GetEmployeeByEmail")
+	defer end(&err)
+	return r.delegate.GetEmployeeByEmail(ctx, email)
+}
+
+func (r *instrumentedEmployeeRepository) GetAllEmployees(ctx context.Context) (employees []model.Employee, err error) {
+	ctx, end := start(ctx, "GetAllEmployees")
+	defer end(&err)
+	return r.delegate.GetAllEmployees(ctx)
+}
+
+func (r *instrumentedEmployeeRepository) GetEmployeesWithReflections(ctx context.Context) (employees []model.Employee, err error) {
+	ctx, end := start(ctx, "GetEmployeesWithReflections")
+	defer end(&err)
+	return r.delegate.GetEmployeesWithReflections(ctx)
+}
+
+func (r *instrumentedEmployeeRepository) GetEmployeeByID(ctx context.Context, id uint) (employee *model.Employee, err error) {
+	ctx, end := start(ctx, "GetEmployeeByID", attribute.Int("employee.id", int(id)))
+	defer end(&err)
+	return r.delegate.GetEmployeeByID(ctx, id)
+}
+
+func (r *instrumentedEmployeeRepository) SaveAvatar(ctx context.Context, employeeID uint, avatar string, source string) (err error) {
+	ctx, end := start(ctx, "SaveAvatar", attribute.Int("employee.id", int(employeeID)))
+	defer end(&err)
+	return r.delegate.SaveAvatar(ctx, employeeID, avatar, source)
+}
+
+func (r *instrumentedEmployeeRepository) SaveRemindedAt(ctx context.Context, employeeID uint, remindedAt time.Time) (err error) {
+	ctx, end := start(ctx, "SaveRemindedAt", attribute.Int("employee.id", int(employeeID)))
+	defer end(&err)
+	return r.delegate.SaveRemindedAt(ctx, employeeID, remindedAt)
+}
+
+func (r *instrumentedEmployeeRepository) SaveEmployee(ctx context.Context, employee *model.Employee) (err error) {
+	ctx, end := start(ctx, "SaveEmployee", attribute.Int("employee.id", int(employee.ID)))
+	defer end(&err)
+	return r.delegate.SaveEmployee(ctx, employee)
+}
+
+func (r *instrumentedEmployeeRepository) DeleteReflection(ctx context.Context, reflectionId uint) (err error) {
+	ctx, end := start(ctx, "DeleteReflection", attribute.Int("reflection.id", int(reflectionId)))
+	defer end(&err)
+	return r.delegate.DeleteReflection(ctx, reflectionId)
+}
+
+func (r *instrumentedEmployeeRepository) DeleteEmployee(ctx context.Context, email string) (err error) {
+	ctx, end := start(ctx, "DeleteEmployee")
+	defer end(&err)
+	return r.delegate.DeleteEmployee(ctx, email)
+}
+
+func (r *instrumentedEmployeeRepository) CountEmployees(ctx context.Context) (count int64, err error) {
+	ctx, end := start(ctx, "CountEmployees")
+	defer end(&err)
+	return r.delegate.CountEmployees(ctx)
+}
+
+func (r *instrumentedEmployeeRepository) CountEmployeesWithoutBio(ctx context.Context) (count int64, err error) {
+	ctx, end := start(ctx, "CountEmployeesWithoutBio")
+	defer end(&err)
+	return r.delegate.CountEmployeesWithoutBio(ctx)
+}
+
+func (r *instrumentedEmployeeRepository) SaveAPIToken(ctx context.Context, token *model.APIToken) (err error) {
+	ctx, end := start(ctx, "SaveAPIToken", attribute.Int("employee.id", int(token.EmployeeID)))
+	defer end(&err)
+	return r.delegate.SaveAPIToken(ctx, token)
+}
+
+func (r *instrumentedEmployeeRepository) GetAPITokens(ctx context.Context, employeeID uint) (tokens []model.APIToken, err error) {
+	ctx, end := start(ctx, "GetAPITokens", attribute.Int("employee.id", int(employeeID)))
+	defer end(&err)
+	return r.delegate.GetAPITokens(ctx, employeeID)
+}
+
+func (r *instrumentedEmployeeRepository) GetAPITokenByHash(ctx context.Context, hash string) (token *model.APIToken, err error) {
+	ctx, end := start(ctx, "GetAPITokenByHash")
+	defer end(&err)
+	return r.delegate.GetAPITokenByHash(ctx, hash)
+}
+
+func (r *instrumentedEmployeeRepository) DeleteAPIToken(ctx context.Context, employeeID uint, id uint) (err error) {
+	ctx, end := start(ctx, "DeleteAPIToken", attribute.Int("employee.id", int(employeeID)), attribute.Int("token.id", int(id)))
+	defer end(&err)
+	return r.delegate.DeleteAPIToken(ctx, employeeID, id)
+}
+
+func (r *instrumentedEmployeeRepository) SaveSession(ctx context.Context, session *model.Session) (err error) {
+	ctx, end := start(ctx, "SaveSession")
+	defer end(&err)
+	return r.delegate.SaveSession(ctx, session)
+}
+
+func (r *instrumentedEmployeeRepository) GetSessionByKeyHash(ctx context.Context, keyHash string) (session *model.Session, err error) {
+	ctx, end := start(ctx, "GetSessionByKeyHash")
+	defer end(&err)
+	return r.delegate.GetSessionByKeyHash(ctx, keyHash)
+}
+
+func (r *instrumentedEmployeeRepository) GetSessions(ctx context.Context, email string) (sessions []model.Session, err error) {
+	ctx, end := start(ctx, "GetSessions")
+	defer end(&err)
+	return r.delegate.GetSessions(ctx, email)
+}
+
+func (r *instrumentedEmployeeRepository) DeleteSession(ctx context.Context, email string, id uint) (err error) {
+	ctx, end := start(ctx, "DeleteSession", attribute.Int("session.id", int(id)))
+	defer end(&err)
+	return r.delegate.DeleteSession(ctx, email, id)
+}
+
+func (r *instrumentedEmployeeRepository) DeleteSessions(ctx context.Context, email string) (count int64, err error) {
+	ctx, end := start(ctx, "DeleteSessions")
+	defer end(&err)
+	return r.delegate.DeleteSessions(ctx, email)
+}
+
+func (r *instrumentedEmployeeRepository) SaveReflection(ctx context.Context, reflection *model.Reflection) (err error) {
+	ctx, end := start(ctx, "SaveReflection", attribute.Int("reflection.id", int(reflection.ID)))
+	defer end(&err)
+	return r.delegate.SaveReflection(ctx, reflection)
+}
+
+func (r *instrumentedEmployeeRepository) SavePrompt(ctx context.Context, prompt *model.Prompt) (err error) {
+	ctx, end := start(ctx, "SavePrompt", attribute.Int("prompt.id", int(prompt.ID)))
+	defer end(&err)
+	return r.delegate.SavePrompt(ctx, prompt)
+}
+
+func (r *instrumentedEmployeeRepository) GetPrompts(ctx context.Context) (prompts []model.Prompt, err error) {
+	ctx, end := start(ctx, "GetPrompts")
+	defer end(&err)
+	return r.delegate.GetPrompts(ctx)
+}
+
+func (r *instrumentedEmployeeRepository) GetActivePrompts(ctx context.Context) (prompts []model.Prompt, err error) {
+	ctx, end := start(ctx, "GetActivePrompts")
+	defer end(&err)
+	return r.delegate.GetActivePrompts(ctx)
+}
+
+func (r *instrumentedEmployeeRepository) GetPrompt(ctx context.Context, id uint) (prompt *model.Prompt, err error) {
+	ctx, end := start(ctx, "GetPrompt", attribute.Int("prompt.id", int(id)))
+	defer end(&err)
+	return r.delegate.GetPrompt(ctx, id)
+}
+
+func (r *instrumentedEmployeeRepository) SaveTeam(ctx context.Context, team *model.Team) (err error) {
+	ctx, end := start(ctx, "SaveTeam", attribute.Int("team.id", int(team.ID)))
+	defer end(&err)
+	return r.delegate.SaveTeam(ctx, team)
+}
+
+func (r *instrumentedEmployeeRepository) GetTeams(ctx context.Context) (teams []model.Team, err error) {
+	ctx, end := start(ctx, "GetTeams")
+	defer end(&err)
+	return r.delegate.GetTeams(ctx)
+}
+
+func (r *instrumentedEmployeeRepository) GetTeam(ctx context.Context, id uint) (team *model.Team, err error) {
+	ctx, end := start(ctx, "GetTeam", attribute.Int("team.id", int(id)))
+	defer end(&err)
+	return r.delegate.GetTeam(ctx, id)
+}
+
+func (r *instrumentedEmployeeRepository) SaveTeamMembership(ctx context.Context, membership *model.TeamMembership) (err error) {
+	ctx, end := start(ctx, "SaveTeamMembership", attribute.Int("team.id", int(membership.TeamID)), attribute.Int("employee.id", int(membership.EmployeeID)))
+	defer end(&err)
+	return r.delegate.SaveTeamMembership(ctx, membership)
+}
+
+func (r *instrumentedEmployeeRepository) DeleteTeamMembership(ctx context.Context, teamID uint, employeeID uint) (err error) {
+	ctx, end := start(ctx, "DeleteTeamMembership", attribute.Int("team.id", int(teamID)), attribute.Int("employee.id", int(employeeID)))
+	defer end(&err)
+	return r.delegate.DeleteTeamMembership(ctx, teamID, employeeID)
+}
+
+func (r *instrumentedEmployeeRepository) SaveSkill(ctx context.Context, skill *model.Skill) (err error) {
+	ctx, end := start(ctx, "SaveSkill", attribute.Int("skill.id", int(skill.ID)))
+	defer end(&err)
+	return r.delegate.SaveSkill(ctx, skill)
+}
+
+func (r *instrumentedEmployeeRepository) GetSkills(ctx context.Context) (skills []model.Skill, err error) {
+	ctx, end := start(ctx, "GetSkills")
+	defer end(&err)
+	return r.delegate.GetSkills(ctx)
+}
+
+func (r *instrumentedEmployeeRepository) GetSkill(ctx context.Context, id uint) (skill *model.Skill, err error) {
+	ctx, end := start(ctx, "GetSkill", attribute.Int("skill.id", int(id)))
+	defer end(&err)
+	return r.delegate.GetSkill(ctx, id)
+}
+
+func (r *instrumentedEmployeeRepository) GetEmployeeSkills(ctx context.Context, employeeID uint) (skills []model.EmployeeSkill, err error) {
+	ctx, end := start(ctx, "GetEmployeeSkills", attribute.Int("employee.id", int(employeeID)))
+	defer end(&err)
+	return r.delegate.GetEmployeeSkills(ctx, employeeID)
+}
+
+func (r *instrumentedEmployeeRepository) FindEmployeeSkills(ctx context.Context, skillID uint, minProficiency int) (skills []model.EmployeeSkill, err error) {
+	ctx, end := start(ctx, "FindEmployeeSkills", attribute.Int("skill.id", int(skillID)), attribute.Int("skill.min_proficiency", minProficiency))
+	defer end(&err)
+	return r.delegate.FindEmployeeSkills(ctx, skillID, minProficiency)
+}
+
+func (r *instrumentedEmployeeRepository) SaveEmployeeSkill(ctx context.Context, employeeSkill *model.EmployeeSkill) (err error) {
+	ctx, end := start(ctx, "SaveEmployeeSkill", attribute.Int("employee.id", int(employeeSkill.EmployeeID)), attribute.Int("skill.id", int(employeeSkill.SkillID)))
+	defer end(&err)
+	return r.delegate.SaveEmployeeSkill(ctx, employeeSkill)
+}
+
+func (r *instrumentedEmployeeRepository) DeleteEmployeeSkill(ctx context.Context, employeeID uint, skillID uint) (err error) {
+	ctx, end := start(ctx, "DeleteEmployeeSkill", attribute.Int("employee.id", int(employeeID)), attribute.Int("skill.id", int(skillID)))
+	defer end(&err)
+	return r.delegate.DeleteEmployeeSkill(ctx, employeeID, skillID)
+}
+
+func (r *instrumentedEmployeeRepository) SaveEndorsement(ctx context.Context, endorsement *model.Endorsement) (err error) {
+	ctx, end := start(ctx, "SaveEndorsement", attribute.Int("employee_skill.id", int(endorsement.EmployeeSkillID)), attribute.Int("employee.id", int(endorsement.EndorserID)))
+	defer end(&err)
+	return r.delegate.SaveEndorsement(ctx, endorsement)
+}
+
+func (r *instrumentedEmployeeRepository) DeleteEndorsement(ctx context.Context, employeeSkillID uint, endorserID uint) (err error) {
+	ctx, end := start(ctx, "DeleteEndorsement", attribute.Int("employee_skill.id", int(employeeSkillID)), attribute.Int("employee.id", int(endorserID)))
+	defer end(&err)
+	return r.delegate.DeleteEndorsement(ctx, employeeSkillID, endorserID)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dataMigration records a one-time change to existing data, such as
+// removing rows an older version created, so that the change is made
+// once rather than on every start.
+type dataMigration struct {
+	Name      string `gorm:"primaryKey"`
+	AppliedAt time.Time
+}
+
+// runOnce calls migrate unless the migration called name has already
+// been applied, recording it in the same transaction. Instances which
+// start together race to record it, and only the winner migrates.
+func runOnce(db *gorm.DB, name string, migrate func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dataMigration{Name: name, AppliedAt: time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		slog.Info("data migration applied", slog.String("migration", name))
+		return nil
+	})
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"gorm.io/gorm"
+)
+
+// markOnboardingStarted records onboarding as started for the employees
+// who were saved before OnboardingStartedAt was kept, so that only
+// profiles provisioned from now on are onboarded when the employee
+// first logs in. It only needs to run once, so useDatabase runs it with
+// runOnce.
+func markOnboardingStarted(db *gorm.DB) error {
+	result := db.Model(&model.Employee{}).Where("onboarding_started_at IS NULL").Update("onboarding_started_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		slog.Info("onboarding recorded as started for existing employees", slog.Int64("count", result.RowsAffected))
+	}
+	return nil
+}
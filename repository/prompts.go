@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"log/slog"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"gorm.io/gorm"
+)
+
+// defaultPrompts start the prompt bank of a new installation. Admins can
+// change or deactivate them afterwards.
+var defaultPrompts = []model.Prompt{
+	{Question: "Ask me about", Category: "Work", Curated: true},
+	{Question: "Hometown", Category: "About Me", Curated: true},
+	{Question: "Favorite band", Category: "Music"},
+	{Question: "Favorite book", Category: "Reading"},
+	{Question: "Currently learning", Category: "Work"},
+	{Question: "Favorite tool", Category: "Work"},
+	{Question: "Hidden talent", Category: "About Me"},
+	{Question: "Pets", Category: "About Me"},
+	{Question: "Best vacation", Category: "Travel"},
+	{Question: "Go-to karaoke song", Category: "Music"},
+}
+
+// placeholderReflection matches the "Temporary Thing #N" reflections,
+// valued N, that every profile used to start with. There was no way to
+// edit a reflection, so one still valued N has never been touched.
+const placeholderReflection = "key = 'Temporary Thing #' || value"
+
+// seedPrompts fills an empty prompt bank with defaultPrompts and, once,
+// removes the placeholder reflections the prompts replace.
+func seedPrompts(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&model.Prompt{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		prompts := make([]model.Prompt, len(defaultPrompts))
+		for i, prompt := range defaultPrompts {
+			prompt.Active = true
+			prompts[i] = prompt
+		}
+		if err := db.Create(&prompts).Error; err != nil {
+			return err
+		}
+		slog.Info("prompt bank seeded", slog.Int("prompts", len(prompts)))
+	}
+	return runOnce(db, "remove-placeholder-reflections", removePlaceholderReflections)
+}
+
+func removePlaceholderReflections(db *gorm.DB) error {
+	result := db.Where(placeholderReflection).Delete(&model.Reflection{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		slog.Info("placeholder reflections removed", slog.Int64("count", result.RowsAffected))
+	}
+	return nil
+}
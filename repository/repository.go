@@ -1,9 +1,17 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/markdown"
+	"github.com/jeffscottbrown/satchel/metrics"
 	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/photos"
 )
 
 var employeeRepository EmployeeRepository
@@ -13,69 +21,263 @@ func SetRepository(r EmployeeRepository) {
 }
 
 type EmployeeRepository interface {
-	GetEmployees() ([]model.Employee, error)
-	GetEmployeeByEmail(string) (model.Employee, error)
-	SaveEmployee(employee *model.Employee) error
-	DeleteReflection(reflectionId uint) error
-	DeleteEmployee(email string) error
+	GetEmployees(ctx context.Context) ([]model.Employee, error)
+	GetAllEmployees(ctx context.Context) ([]model.Employee, error)
+	GetEmployeesWithReflections(ctx context.Context) ([]model.Employee, error)
+	GetEmployeeByEmail(ctx context.Context, email string) (model.Employee, error)
+	GetEmployeeByID(ctx context.Context, id uint) (*model.Employee, error)
+	SaveEmployee(ctx context.Context, employee *model.Employee) error
+	SaveAvatar(ctx context.Context, employeeID uint, avatar string, source string) error
+	SaveRemindedAt(ctx context.Context, employeeID uint, remindedAt time.Time) error
+	DeleteReflection(ctx context.Context, reflectionId uint) error
+	DeleteEmployee(ctx context.Context, email string) error
+	CountEmployees(ctx context.Context) (int64, error)
+	CountEmployeesWithoutBio(ctx context.Context) (int64, error)
+	SaveAPIToken(ctx context.Context, token *model.APIToken) error
+	GetAPITokens(ctx context.Context, employeeID uint) ([]model.APIToken, error)
+	GetAPITokenByHash(ctx context.Context, hash string) (*model.APIToken, error)
+	DeleteAPIToken(ctx context.Context, employeeID uint, id uint) error
+	SaveSession(ctx context.Context, session *model.Session) error
+	GetSessionByKeyHash(ctx context.Context, keyHash string) (*model.Session, error)
+	GetSessions(ctx context.Context, email string) ([]model.Session, error)
+	DeleteSession(ctx context.Context, email string, id uint) error
+	DeleteSessions(ctx context.Context, email string) (int64, error)
+	SaveReflection(ctx context.Context, reflection *model.Reflection) error
+	SavePrompt(ctx context.Context, prompt *model.Prompt) error
+	GetPrompts(ctx context.Context) ([]model.Prompt, error)
+	GetActivePrompts(ctx context.Context) ([]model.Prompt, error)
+	GetPrompt(ctx context.Context, id uint) (*model.Prompt, error)
+	SaveTeam(ctx context.Context, team *model.Team) error
+	GetTeams(ctx context.Context) ([]model.Team, error)
+	GetTeam(ctx context.Context, id uint) (*model.Team, error)
+	SaveTeamMembership(ctx context.Context, membership *model.TeamMembership) error
+	DeleteTeamMembership(ctx context.Context, teamID uint, employeeID uint) error
+	SaveSkill(ctx context.Context, skill *model.Skill) error
+	GetSkills(ctx context.Context) ([]model.Skill, error)
+	GetSkill(ctx context.Context, id uint) (*model.Skill, error)
+	GetEmployeeSkills(ctx context.Context, employeeID uint) ([]model.EmployeeSkill, error)
+	FindEmployeeSkills(ctx context.Context, skillID uint, minProficiency int) ([]model.EmployeeSkill, error)
+	SaveEmployeeSkill(ctx context.Context, employeeSkill *model.EmployeeSkill) error
+	DeleteEmployeeSkill(ctx context.Context, employeeID uint, skillID uint) error
+	SaveEndorsement(ctx context.Context, endorsement *model.Endorsement) error
+	DeleteEndorsement(ctx context.Context, employeeSkillID uint, endorserID uint) error
 }
 
-func SaveEmployee(employee *model.Employee) error {
+func SaveEmployee(ctx context.Context, employee *model.Employee) error {
 	if employeeRepository == nil {
 		return errors.New("repository has not been initialized")
 	}
-	return employeeRepository.SaveEmployee(employee)
+	employee.BioText = markdown.PlainText(employee.Bio)
+	return employeeRepository.SaveEmployee(ctx, employee)
 }
 
-func GetEmployees() ([]model.Employee, error) {
+// SaveAvatar records the key of the employee's cached avatar and the
+// address it was fetched from without touching any other column, so
+// that changes saved while the avatar was being fetched are kept.
+func SaveAvatar(ctx context.Context, employeeID uint, avatar string, source string) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	return employeeRepository.SaveAvatar(ctx, employeeID, avatar, source)
+}
+
+// SaveRemindedAt records when the employee was last reminded to complete
+// their profile without touching any other column.
+func SaveRemindedAt(ctx context.Context, employeeID uint, remindedAt time.Time) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	return employeeRepository.SaveRemindedAt(ctx, employeeID, remindedAt)
+}
+
+func GetEmployees(ctx context.Context) ([]model.Employee, error) {
 	if employeeRepository == nil {
 		return nil, errors.New("repository has not been initialized")
 	}
-	employees, err := employeeRepository.GetEmployees()
+	employees, err := employeeRepository.GetEmployees(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return employees, nil
 }
 
-func DeleteEmployee(email string) error {
+// GetEmployeesWithReflections returns the same employees as GetEmployees
+// with their reflections loaded.
+func GetEmployeesWithReflections(ctx context.Context) ([]model.Employee, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetEmployeesWithReflections(ctx)
+}
+
+// ProfileStatistics returns the business measures exposed as metrics.
+func ProfileStatistics(ctx context.Context) (metrics.ProfileStatistics, error) {
+	if employeeRepository == nil {
+		return metrics.ProfileStatistics{}, errors.New("repository has not been initialized")
+	}
+	employees, err := employeeRepository.CountEmployees(ctx)
+	if err != nil {
+		return metrics.ProfileStatistics{}, err
+	}
+	emptyBios, err := employeeRepository.CountEmployeesWithoutBio(ctx)
+	if err != nil {
+		return metrics.ProfileStatistics{}, err
+	}
+	return metrics.ProfileStatistics{Employees: employees, EmptyBios: emptyBios}, nil
+}
+
+// GetAllEmployees returns every employee, including deactivated ones,
+// in the order they were created.
+func GetAllEmployees(ctx context.Context) ([]model.Employee, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetAllEmployees(ctx)
+}
+
+func GetEmployeeByID(ctx context.Context, id uint) (*model.Employee, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetEmployeeByID(ctx, id)
+}
+
+// DeleteEmployee permanently removes the employee along with their
+// uploaded photo and cached avatar.
+func DeleteEmployee(ctx context.Context, email string) error {
 	if employeeRepository == nil {
 		return errors.New("repository has not been initialized")
 	}
-	return employeeRepository.DeleteEmployee(email)
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if err := employeeRepository.DeleteEmployee(ctx, email); err != nil {
+		return err
+	}
+	// A failure only leaves orphaned files behind, so it is logged
+	// rather than reported.
+	for _, key := range []string{employee.Photo, employee.Avatar} {
+		if key == "" {
+			continue
+		}
+		if err := photos.Remove(ctx, key); err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "failed to remove photo of deleted employee",
+				slog.String("photo", key), slog.Any("error", err))
+		}
+	}
+	return nil
 }
 
-func GetEmployeeByEmail(email string) (*model.Employee, error) {
+func GetEmployeeByEmail(ctx context.Context, email string) (*model.Employee, error) {
 	if employeeRepository == nil {
 		return nil, errors.New("repository has not been initialized")
 	}
-	employee, err := employeeRepository.GetEmployeeByEmail(email)
+	employee, err := employeeRepository.GetEmployeeByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 	return &employee, nil
 }
 
-func SavePosition(email string, position string) error {
-	employee, err := GetEmployeeByEmail(email)
+func SavePosition(ctx context.Context, email string, position string) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
 	if err != nil {
 		return err
 	}
 	employee.Position = position
-	return SaveEmployee(employee)
+	return SaveEmployee(ctx, employee)
 }
 
-func SaveBio(email string, bio string) error {
-	employee, err := GetEmployeeByEmail(email)
+// ErrBioTooLong is returned by SaveBio for bios longer than
+// model.MaxBioLength.
+var ErrBioTooLong = fmt.Errorf("bios cannot be longer than %d characters", model.MaxBioLength)
+
+func SaveBio(ctx context.Context, email string, bio string) error {
+	if model.BioTooLong(bio) {
+		return ErrBioTooLong
+	}
+	employee, err := GetEmployeeByEmail(ctx, email)
 	if err != nil {
 		return err
 	}
 	employee.Bio = bio
-	return SaveEmployee(employee)
+	return SaveEmployee(ctx, employee)
+}
+
+// ErrManagerCycle is returned by SetManager when the manager already
+// reports, directly or indirectly, to the employee.
+var ErrManagerCycle = errors.New("the manager reports to the employee")
+
+// SetManager makes the employee with managerEmail the manager of the
+// employee with email, or clears their manager when managerEmail is
+// blank.
+func SetManager(ctx context.Context, email string, managerEmail string) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if managerEmail != "" {
+		manager, err := GetEmployeeByEmail(ctx, managerEmail)
+		if err != nil {
+			return err
+		}
+		if manager.ID == employee.ID {
+			return errors.New("an employee cannot be their own manager")
+		}
+		employees, err := GetAllEmployees(ctx)
+		if err != nil {
+			return err
+		}
+		if model.NewManagers(employees).WouldCycle(employee.Email, manager.Email) {
+			return ErrManagerCycle
+		}
+		managerEmail = manager.Email
+	}
+	employee.ManagerEmail = managerEmail
+	return SaveEmployee(ctx, employee)
+}
+
+func SetAdmin(ctx context.Context, email string, admin bool) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	employee.Admin = admin
+	return SaveEmployee(ctx, employee)
+}
+
+// DeactivateEmployee hides the employee from the directory, ends their
+// sessions and prevents them from logging in while keeping their
+// profile.
+func DeactivateEmployee(ctx context.Context, email string) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if employee.DeactivatedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	employee.DeactivatedAt = &now
+	if err := SaveEmployee(ctx, employee); err != nil {
+		return err
+	}
+	_, err = DeleteSessions(ctx, email)
+	return err
 }
 
-func DeleteReflection(email string, reflectionId uint) error {
-	employee, err := GetEmployeeByEmail(email)
+func ReactivateEmployee(ctx context.Context, email string) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	employee.DeactivatedAt = nil
+	return SaveEmployee(ctx, employee)
+}
+
+func DeleteReflection(ctx context.Context, email string, reflectionId uint) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
 	if err != nil {
 		return err
 	}
@@ -89,13 +291,152 @@ func DeleteReflection(email string, reflectionId uint) error {
 	if !found {
 		return errors.New("reflection not found")
 	}
-	return employeeRepository.DeleteReflection(reflectionId)
+	return employeeRepository.DeleteReflection(ctx, reflectionId)
 }
-func AddReflection(email string, name string, value string) error {
-	employee, err := GetEmployeeByEmail(email)
+
+func AddReflection(ctx context.Context, email string, name string, value string) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
 	if err != nil {
 		return err
 	}
 	employee.AddReflection(name, value)
-	return SaveEmployee(employee)
+	return SaveEmployee(ctx, employee)
+}
+
+// AnswerReflection sets the value of one of the employee's reflections,
+// typically to answer a prompt they were given.
+func AnswerReflection(ctx context.Context, email string, reflectionId uint, value string) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	for i := range employee.Reflections {
+		if employee.Reflections[i].ID == reflectionId {
+			employee.Reflections[i].Value = value
+			return employeeRepository.SaveReflection(ctx, &employee.Reflections[i])
+		}
+	}
+	return errors.New("reflection not found")
+}
+
+// AnswerPrompt adds the employee's answer to a prompt from the bank as a
+// reflection.
+func AnswerPrompt(ctx context.Context, email string, prompt model.Prompt, value string) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if employee.HasPrompt(prompt) {
+		return errors.New("prompt has already been answered")
+	}
+	employee.AddPrompt(prompt)
+	employee.Reflections[len(employee.Reflections)-1].Value = value
+	return SaveEmployee(ctx, employee)
+}
+
+func SavePrompt(ctx context.Context, prompt *model.Prompt) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	return employeeRepository.SavePrompt(ctx, prompt)
+}
+
+// GetPrompts returns every prompt in the bank, including inactive ones,
+// ordered by category and question.
+func GetPrompts(ctx context.Context) ([]model.Prompt, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetPrompts(ctx)
+}
+
+// GetActivePrompts returns the prompts which are offered to employees.
+func GetActivePrompts(ctx context.Context) ([]model.Prompt, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetActivePrompts(ctx)
+}
+
+// GetPrompt returns the prompt with the given ID, or
+// gorm.ErrRecordNotFound when there is none.
+func GetPrompt(ctx context.Context, id uint) (*model.Prompt, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetPrompt(ctx, id)
+}
+
+func SaveAPIToken(ctx context.Context, token *model.APIToken) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	return employeeRepository.SaveAPIToken(ctx, token)
+}
+
+// GetAPITokens returns the employee's tokens, newest first.
+func GetAPITokens(ctx context.Context, employeeID uint) ([]model.APIToken, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetAPITokens(ctx, employeeID)
+}
+
+func GetAPITokenByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetAPITokenByHash(ctx, hash)
+}
+
+// DeleteAPIToken revokes the token with the given ID, provided it
+// belongs to the employee. It returns gorm.ErrRecordNotFound otherwise.
+func DeleteAPIToken(ctx context.Context, employeeID uint, id uint) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	return employeeRepository.DeleteAPIToken(ctx, employeeID, id)
+}
+
+func SaveSession(ctx context.Context, session *model.Session) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	return employeeRepository.SaveSession(ctx, session)
+}
+
+func GetSessionByKeyHash(ctx context.Context, keyHash string) (*model.Session, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetSessionByKeyHash(ctx, keyHash)
+}
+
+// GetSessions returns the employee's sessions, most recently seen first.
+func GetSessions(ctx context.Context, email string) ([]model.Session, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetSessions(ctx, email)
+}
+
+// DeleteSession ends the session with the given ID, provided it belongs
+// to the employee. It returns gorm.ErrRecordNotFound otherwise.
+func DeleteSession(ctx context.Context, email string, id uint) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	return employeeRepository.DeleteSession(ctx, email, id)
+}
+
+// DeleteSessions ends every session of the employee, logging them out
+// everywhere, and returns how many there were.
+func DeleteSessions(ctx context.Context, email string) (int64, error) {
+	if employeeRepository == nil {
+		return 0, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.DeleteSessions(ctx, email)
 }
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/model"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
@@ -25,6 +27,14 @@ func ConfigureRepositoryForTest(t *testing.T, testRepo EmployeeRepository) {
 	employeeRepository = testRepo
 }
 
+// DeleteTeamForTest deletes the team with id and its memberships, for
+// cleaning up after tests which create teams.
+func DeleteTeamForTest(t *testing.T, id uint) {
+	if err := database.Delete(&model.Team{}, id).Error; err != nil {
+		t.Errorf("deleting team %d: %v", id, err)
+	}
+}
+
 func RunTestsWithTestContainer(m *testing.M) {
 	ctx := context.Background()
 
@@ -60,13 +70,17 @@ func RunTestsWithTestContainer(m *testing.M) {
 		os.Exit(1)
 	}
 
-	os.Setenv("SATCHEL_DB_HOST", host)
-	os.Setenv("SATCHEL_DB_PORT", port.Port())
-	os.Setenv("SATCHEL_DB_USER", "testuser")
-	os.Setenv("SATCHEL_DB_PASSWORD", "testpass")
-	os.Setenv("SATCHEL_DB_NAME", "testdb")
-
-	InitializeDatabase()
+	err = InitializeDatabase(config.Database{
+		Host:     host,
+		Port:     port.Port(),
+		User:     "testuser",
+		Password: "testpass",
+		Name:     "testdb",
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not initialize database: %v\n", err)
+		os.Exit(1)
+	}
 
 	code := m.Run()
 
@@ -1,50 +1,57 @@
 package repository
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
 	"testing"
 
 	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/photos"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestGetEmployees_RepositoryNotInitialized(t *testing.T) {
 	// Ensure repository is not initialized
 	ConfigureRepositoryForTest(t, nil)
 
-	employees, err := GetEmployees()
+	employees, err := GetEmployees(t.Context())
 	assert.Nil(t, employees)
 	assert.Error(t, err)
 	assert.EqualError(t, err, "repository has not been initialized")
 }
 
 func TestGetEmployees_RepositoryInitialized(t *testing.T) {
-	_, err := GetEmployees()
+	_, err := GetEmployees(t.Context())
 	assert.NoError(t, err)
 }
 
 func TestGetEmployees_RepositoryReturnsError(t *testing.T) {
 	t.Skip()
 
-	employees, err := GetEmployees()
+	employees, err := GetEmployees(t.Context())
 	assert.Nil(t, employees)
 	assert.Error(t, err)
 	assert.EqualError(t, err, "database error")
 }
 
 func TestGetEmployeeByName_Found(t *testing.T) {
-	SaveEmployee(&model.Employee{
+	SaveEmployee(t.Context(), &model.Employee{
 		Email: "bob@somewhere.com",
 		Name:  "Bob",
 	})
 
-	employee, err := GetEmployeeByEmail("bob@somewhere.com")
+	employee, err := GetEmployeeByEmail(t.Context(), "bob@somewhere.com")
 	assert.NoError(t, err)
 	assert.NotNil(t, employee)
 	assert.Equal(t, "Bob", employee.Name)
 }
 
 func TestGetEmployeeByName_NotFound(t *testing.T) {
-	_, err := GetEmployeeByEmail("charlie@somewhere.com")
+	_, err := GetEmployeeByEmail(t.Context(), "charlie@somewhere.com")
 	assert.Error(t, err)
 	assert.EqualError(t, err, "record not found")
 }
@@ -52,14 +59,14 @@ func TestGetEmployeeByName_NotFound(t *testing.T) {
 func TestGetEmployeeByName_RepositoryNotInitialized(t *testing.T) {
 	ConfigureRepositoryForTest(t, nil)
 
-	employee, err := GetEmployeeByEmail("alice@somewhere.com")
+	employee, err := GetEmployeeByEmail(t.Context(), "alice@somewhere.com")
 	assert.Nil(t, employee)
 	assert.Error(t, err)
 	assert.EqualError(t, err, "repository has not been initialized")
 }
 
 func TestGetEmployeeByName_RepositoryReturnsError(t *testing.T) {
-	employee, err := GetEmployeeByEmail("alice@somewhere.com")
+	employee, err := GetEmployeeByEmail(t.Context(), "alice@somewhere.com")
 	assert.Nil(t, employee)
 	assert.Error(t, err)
 	assert.EqualError(t, err, "record not found")
@@ -67,22 +74,22 @@ func TestGetEmployeeByName_RepositoryReturnsError(t *testing.T) {
 
 func TestDeleteEmployee(t *testing.T) {
 	email := "test@somedomain.com"
-	emp, err := GetEmployeeByEmail(email)
+	emp, err := GetEmployeeByEmail(t.Context(), email)
 	assert.Error(t, err)
 	assert.Nil(t, emp)
 
-	err = SaveEmployee(&model.Employee{
+	err = SaveEmployee(t.Context(), &model.Employee{
 		Email: email,
 	})
 	assert.NoError(t, err)
 
-	emp, err = GetEmployeeByEmail(email)
+	emp, err = GetEmployeeByEmail(t.Context(), email)
 	assert.NoError(t, err)
 	assert.NotNil(t, emp)
 
-	err = DeleteEmployee(email)
+	err = DeleteEmployee(t.Context(), email)
 	assert.NoError(t, err)
-	emp, err = GetEmployeeByEmail(email)
+	emp, err = GetEmployeeByEmail(t.Context(), email)
 	assert.Error(t, err)
 	assert.Nil(t, emp)
 }
@@ -90,20 +97,20 @@ func TestDeleteEmployee(t *testing.T) {
 func TestSavePosition(t *testing.T) {
 	email := "someone@somewhere.com"
 	t.Cleanup(func() {
-		err := DeleteEmployee(email)
+		err := DeleteEmployee(context.Background(), email)
 		assert.NoError(t, err)
 	})
-	SaveEmployee(&model.Employee{
+	SaveEmployee(t.Context(), &model.Employee{
 		Email: email,
 	})
-	emp, err := GetEmployeeByEmail(email)
+	emp, err := GetEmployeeByEmail(t.Context(), email)
 	assert.NoError(t, err)
 	assert.NotNil(t, emp)
 	assert.Equal(t, "", emp.Position)
 
-	err = SavePosition(email, "Some New Position")
+	err = SavePosition(t.Context(), email, "Some New Position")
 
-	emp, err = GetEmployeeByEmail(email)
+	emp, err = GetEmployeeByEmail(t.Context(), email)
 	assert.NoError(t, err)
 	assert.NotNil(t, emp)
 	assert.Equal(t, "Some New Position", emp.Position)
@@ -112,20 +119,20 @@ func TestSavePosition(t *testing.T) {
 func TestSaveBio(t *testing.T) {
 	email := "someone@somewhere.com"
 	t.Cleanup(func() {
-		err := DeleteEmployee(email)
+		err := DeleteEmployee(context.Background(), email)
 		assert.NoError(t, err)
 	})
-	SaveEmployee(&model.Employee{
+	SaveEmployee(t.Context(), &model.Employee{
 		Email: email,
 	})
-	emp, err := GetEmployeeByEmail(email)
+	emp, err := GetEmployeeByEmail(t.Context(), email)
 	assert.NoError(t, err)
 	assert.NotNil(t, emp)
 	assert.Equal(t, "", emp.Bio)
 
-	err = SaveBio(email, "Some New Bio")
+	err = SaveBio(t.Context(), email, "Some New Bio")
 
-	emp, err = GetEmployeeByEmail(email)
+	emp, err = GetEmployeeByEmail(t.Context(), email)
 	assert.NoError(t, err)
 	assert.NotNil(t, emp)
 	assert.Equal(t, "Some New Bio", emp.Bio)
@@ -134,23 +141,23 @@ func TestSaveBio(t *testing.T) {
 func TestUpdatingReflections(t *testing.T) {
 	email := "someone@someplace.com"
 	t.Cleanup(func() {
-		err := DeleteEmployee(email)
+		err := DeleteEmployee(context.Background(), email)
 		assert.NoError(t, err)
 	})
-	SaveEmployee(&model.Employee{
+	SaveEmployee(t.Context(), &model.Employee{
 		Email: email,
 	})
-	emp, err := GetEmployeeByEmail(email)
+	emp, err := GetEmployeeByEmail(t.Context(), email)
 	assert.NoError(t, err)
 	assert.NotNil(t, emp)
 	assert.Empty(t, emp.Reflections)
 
-	AddReflection(email, "Favorite Band", "Grateful Dead")
-	AddReflection(email, "Home", "Here")
+	AddReflection(t.Context(), email, "Favorite Band", "Grateful Dead")
+	AddReflection(t.Context(), email, "Home", "Here")
 
 	assert.NoError(t, err)
 
-	emp, err = GetEmployeeByEmail(email)
+	emp, err = GetEmployeeByEmail(t.Context(), email)
 	assert.NoError(t, err)
 	assert.NotNil(t, emp)
 	assert.Len(t, emp.Reflections, 2)
@@ -163,10 +170,10 @@ func TestUpdatingReflections(t *testing.T) {
 		}
 	}
 
-	err = DeleteReflection(email, homeReflectionID)
+	err = DeleteReflection(t.Context(), email, homeReflectionID)
 	assert.NoError(t, err)
 
-	emp, err = GetEmployeeByEmail(email)
+	emp, err = GetEmployeeByEmail(t.Context(), email)
 	assert.NoError(t, err)
 	assert.NotNil(t, emp)
 	assert.Len(t, emp.Reflections, 1)
@@ -174,6 +181,347 @@ func TestUpdatingReflections(t *testing.T) {
 
 }
 
+func TestProfileStatistics(t *testing.T) {
+	before, err := ProfileStatistics(t.Context())
+	assert.NoError(t, err)
+
+	withBio := "with.bio@somewhere.com"
+	withoutBio := "without.bio@somewhere.com"
+	t.Cleanup(func() {
+		assert.NoError(t, DeleteEmployee(context.Background(), withBio))
+		assert.NoError(t, DeleteEmployee(context.Background(), withoutBio))
+	})
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: withBio, Bio: "Something about me"}))
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: withoutBio, Bio: "   "}))
+
+	after, err := ProfileStatistics(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, before.Employees+2, after.Employees)
+	assert.Equal(t, before.EmptyBios+1, after.EmptyBios)
+}
+
+func TestSetAdmin(t *testing.T) {
+	email := "admin@somewhere.com"
+	t.Cleanup(func() {
+		assert.NoError(t, DeleteEmployee(context.Background(), email))
+	})
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: email}))
+
+	assert.NoError(t, SetAdmin(t.Context(), email, true))
+	emp, err := GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	assert.True(t, emp.Admin)
+
+	assert.NoError(t, SetAdmin(t.Context(), email, false))
+	emp, err = GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	assert.False(t, emp.Admin)
+}
+
+func TestDeactivateEmployee(t *testing.T) {
+	email := "leaver@somewhere.com"
+	t.Cleanup(func() {
+		assert.NoError(t, DeleteEmployee(context.Background(), email))
+	})
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: email}))
+
+	assert.NoError(t, DeactivateEmployee(t.Context(), email))
+	emp, err := GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	assert.False(t, emp.IsActive())
+	employees, err := GetEmployees(t.Context())
+	assert.NoError(t, err)
+	for i := range employees {
+		assert.NotEqual(t, email, employees[i].Email, "Deactivated employees should not be listed")
+	}
+
+	assert.NoError(t, ReactivateEmployee(t.Context(), email))
+	emp, err = GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	assert.True(t, emp.IsActive())
+}
+
+func TestGetEmployeesWithReflections(t *testing.T) {
+	active, leaver := "reflective@somewhere.com", "reflective.leaver@somewhere.com"
+	t.Cleanup(func() {
+		assert.NoError(t, DeleteEmployee(context.Background(), active))
+		assert.NoError(t, DeleteEmployee(context.Background(), leaver))
+	})
+	employee := &model.Employee{Email: active}
+	employee.AddReflection("Favorite Book", "Walden")
+	assert.NoError(t, SaveEmployee(t.Context(), employee))
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: leaver}))
+	assert.NoError(t, DeactivateEmployee(t.Context(), leaver))
+
+	employees, err := GetEmployeesWithReflections(t.Context())
+	assert.NoError(t, err)
+	found := false
+	for i := range employees {
+		assert.NotEqual(t, leaver, employees[i].Email, "Deactivated employees should not be listed")
+		if employees[i].Email == active {
+			found = true
+			assert.Len(t, employees[i].Reflections, 1)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestDeleteEmployee_RemovesPhotos(t *testing.T) {
+	photos.ConfigureStorageForTest(t)
+	var upload bytes.Buffer
+	assert.NoError(t, png.Encode(&upload, image.NewRGBA(image.Rect(0, 0, 10, 10))))
+	key, err := photos.Save(t.Context(), upload.Bytes())
+	assert.NoError(t, err)
+
+	email := "photographed@somewhere.com"
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: email, Photo: key}))
+	assert.NoError(t, DeleteEmployee(t.Context(), email))
+
+	for _, size := range photos.Sizes {
+		_, err := photos.Open(t.Context(), photos.FileName(key, size))
+		assert.ErrorIs(t, err, photos.ErrNotFound)
+	}
+}
+
 func TestMain(m *testing.M) {
 	RunTestsWithTestContainer(m)
 }
+
+func TestPrompts_DefaultsAreSeeded(t *testing.T) {
+	prompts, err := GetActivePrompts(t.Context())
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(prompts), len(defaultPrompts))
+	prompt, err := GetPrompt(t.Context(), prompts[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, prompts[0].Question, prompt.Question)
+	_, err = GetPrompt(t.Context(), 999999)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.NoError(t, seedPrompts(database), "Seeding again should change nothing")
+	again, err := GetActivePrompts(t.Context())
+	assert.NoError(t, err)
+	assert.Len(t, again, len(prompts))
+}
+
+func TestPrompts_PlaceholderReflectionsAreRemoved(t *testing.T) {
+	email := "placeholder@someplace.com"
+	t.Cleanup(func() {
+		DeleteEmployee(context.Background(), email)
+	})
+	employee := &model.Employee{Email: email}
+	employee.AddReflection("Temporary Thing #1", "1")
+	employee.AddReflection("Temporary Thing #2", "Kept because it was changed")
+	employee.AddReflection("Favorite Band", "Grateful Dead")
+	assert.NoError(t, SaveEmployee(t.Context(), employee))
+
+	assert.NoError(t, removePlaceholderReflections(database))
+
+	employee, err := GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	keys := []string{}
+	for _, reflection := range employee.Reflections {
+		keys = append(keys, reflection.Key)
+	}
+	assert.ElementsMatch(t, []string{"Temporary Thing #2", "Favorite Band"}, keys)
+}
+
+func TestRunOnce(t *testing.T) {
+	runs := 0
+	migrate := func(tx *gorm.DB) error {
+		runs++
+		return nil
+	}
+
+	assert.NoError(t, runOnce(database, "test-run-once", migrate))
+	assert.NoError(t, runOnce(database, "test-run-once", migrate))
+	assert.Equal(t, 1, runs, "A migration should only be applied once")
+
+	failing := func(tx *gorm.DB) error {
+		return errors.New("migration failed")
+	}
+	assert.Error(t, runOnce(database, "test-run-once-failing", failing))
+	assert.NoError(t, runOnce(database, "test-run-once-failing", migrate))
+	assert.Equal(t, 2, runs, "A failed migration should be tried again")
+}
+
+func TestPrompts_Answering(t *testing.T) {
+	email := "prompted@someplace.com"
+	t.Cleanup(func() {
+		DeleteEmployee(context.Background(), email)
+	})
+	prompts, err := GetActivePrompts(t.Context())
+	assert.NoError(t, err)
+	employee := &model.Employee{Email: email}
+	employee.AddPrompt(prompts[0])
+	assert.NoError(t, SaveEmployee(t.Context(), employee))
+
+	employee, _ = GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, AnswerReflection(t.Context(), email, employee.Reflections[0].ID, "An answer"))
+	assert.NoError(t, AnswerPrompt(t.Context(), email, prompts[1], "Another answer"))
+	assert.Error(t, AnswerPrompt(t.Context(), email, prompts[1], "Twice"), "A prompt should only be answered once")
+	assert.Error(t, AnswerReflection(t.Context(), email, 0, "Nobody's"))
+
+	employee, err = GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	assert.Len(t, employee.Reflections, 2)
+	for _, reflection := range employee.Reflections {
+		assert.NotEmpty(t, reflection.Value)
+		assert.NotNil(t, reflection.PromptID)
+	}
+}
+
+func TestBioText(t *testing.T) {
+	email := "markdown@someplace.com"
+	t.Cleanup(func() {
+		DeleteEmployee(context.Background(), email)
+	})
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: email}))
+
+	assert.NoError(t, SaveBio(t.Context(), email, "I *really* like [Phish](https://phish.com)"))
+	employee, err := GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	assert.Equal(t, "I really like Phish (https://phish.com)", employee.BioText)
+
+	assert.NoError(t, database.Model(&model.Employee{}).Where("email = ?", email).Update("bio_text", "").Error)
+	assert.NoError(t, fillBioText(database))
+	employee, err = GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	assert.Equal(t, "I really like Phish (https://phish.com)", employee.BioText, "Bios written before BioText was kept should be filled in")
+}
+
+func TestMarkOnboardingStarted(t *testing.T) {
+	email := "long.timer@someplace.com"
+	t.Cleanup(func() {
+		DeleteEmployee(context.Background(), email)
+	})
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: email}))
+
+	assert.NoError(t, markOnboardingStarted(database))
+	employee, err := GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	assert.NotNil(t, employee.OnboardingStartedAt, "Employees saved before onboarding was recorded should not be onboarded at their next login")
+}
+
+func TestTeams(t *testing.T) {
+	lead, member := "team.lead@someplace.com", "team.member@someplace.com"
+	team := &model.Team{Name: "Platform", Description: "Keeps the lights on", Open: true}
+	t.Cleanup(func() {
+		DeleteEmployee(context.Background(), lead)
+		DeleteEmployee(context.Background(), member)
+		database.Delete(&model.Team{}, team.ID)
+	})
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: lead, Name: "Lead"}))
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: member, Name: "Member"}))
+	assert.NoError(t, SaveTeam(t.Context(), team))
+	assert.Error(t, SaveTeam(t.Context(), &model.Team{Name: "Platform"}), "Team names should be unique")
+
+	assert.NoError(t, AddTeamMember(t.Context(), team.ID, lead, model.TeamMember))
+	assert.NoError(t, AddTeamMember(t.Context(), team.ID, lead, model.TeamLead), "Adding a member again should change their role")
+	assert.NoError(t, AddTeamMember(t.Context(), team.ID, member, model.TeamMember))
+	assert.Error(t, AddTeamMember(t.Context(), team.ID, member, "owner"))
+
+	loaded, err := GetTeam(t.Context(), team.ID)
+	assert.NoError(t, err)
+	members := loaded.Members()
+	assert.Len(t, members, 2)
+	assert.Equal(t, "Lead", members[0].Employee.Name)
+	assert.Equal(t, model.TeamLead, members[0].Role)
+
+	assert.NoError(t, RemoveTeamMember(t.Context(), team.ID, member))
+	assert.NoError(t, DeleteEmployee(t.Context(), lead), "Deleting an employee should delete their memberships")
+	teams, err := GetTeams(t.Context())
+	assert.NoError(t, err)
+	for i := range teams {
+		if teams[i].ID == team.ID {
+			assert.Empty(t, teams[i].Memberships)
+		}
+	}
+}
+
+func TestSetManager(t *testing.T) {
+	boss, worker := "org.boss@someplace.com", "org.worker@someplace.com"
+	t.Cleanup(func() {
+		DeleteEmployee(context.Background(), boss)
+		DeleteEmployee(context.Background(), worker)
+	})
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: boss}))
+	assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: worker}))
+
+	assert.NoError(t, SetManager(t.Context(), worker, boss))
+	assert.ErrorIs(t, SetManager(t.Context(), boss, worker), ErrManagerCycle)
+	assert.Error(t, SetManager(t.Context(), boss, boss))
+	assert.ErrorIs(t, SetManager(t.Context(), boss, "nobody@someplace.com"), gorm.ErrRecordNotFound)
+
+	renamed := "org.renamed.boss@someplace.com"
+	t.Cleanup(func() {
+		DeleteEmployee(context.Background(), renamed)
+	})
+	manager, err := GetEmployeeByEmail(t.Context(), boss)
+	assert.NoError(t, err)
+	manager.Email = renamed
+	assert.NoError(t, SaveEmployee(t.Context(), manager))
+	employee, err := GetEmployeeByEmail(t.Context(), worker)
+	assert.NoError(t, err)
+	assert.Equal(t, renamed, employee.ManagerEmail, "Reports should follow their manager to a new email")
+
+	assert.NoError(t, SetManager(t.Context(), worker, ""))
+	employee, err = GetEmployeeByEmail(t.Context(), worker)
+	assert.NoError(t, err)
+	assert.Empty(t, employee.ManagerEmail)
+}
+
+func TestSkills(t *testing.T) {
+	expert, novice, colleague := "skills.expert@someplace.com", "skills.novice@someplace.com", "skills.colleague@someplace.com"
+	t.Cleanup(func() {
+		for _, email := range []string{expert, novice, colleague} {
+			DeleteEmployee(context.Background(), email)
+		}
+	})
+	for _, email := range []string{expert, novice, colleague} {
+		assert.NoError(t, SaveEmployee(t.Context(), &model.Employee{Email: email}))
+	}
+	assert.NoError(t, seedSkills(database), "Seeding again should change nothing")
+	kubernetes, err := FindSkill(t.Context(), "K8s")
+	assert.NoError(t, err)
+	assert.Equal(t, "Kubernetes", kubernetes.Name, "Skills should be found by their synonyms")
+	skill, err := GetSkill(t.Context(), kubernetes.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kubernetes", skill.Name)
+	_, err = GetSkill(t.Context(), 999999)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	assert.NoError(t, SetEmployeeSkill(t.Context(), expert, kubernetes.ID, 3, 2))
+	assert.NoError(t, SetEmployeeSkill(t.Context(), expert, kubernetes.ID, 5, 6), "Setting a skill again should update it")
+	assert.NoError(t, SetEmployeeSkill(t.Context(), novice, kubernetes.ID, 1, 0))
+	assert.Error(t, SetEmployeeSkill(t.Context(), novice, kubernetes.ID, 6, 0))
+	assert.Error(t, SetEmployeeSkill(t.Context(), novice, kubernetes.ID, 2, -1))
+	assert.ErrorIs(t, SetEmployeeSkill(t.Context(), novice, 999999, 2, 1), gorm.ErrRecordNotFound)
+
+	assert.NoError(t, Endorse(t.Context(), colleague, expert, kubernetes.ID))
+	assert.NoError(t, Endorse(t.Context(), colleague, expert, kubernetes.ID), "Endorsing twice should change nothing")
+	assert.ErrorIs(t, Endorse(t.Context(), expert, expert, kubernetes.ID), ErrSelfEndorsement)
+	assert.ErrorIs(t, Endorse(t.Context(), colleague, colleague+"x", kubernetes.ID), gorm.ErrRecordNotFound)
+
+	people, err := FindPeopleWithSkill(t.Context(), kubernetes.ID, 4)
+	assert.NoError(t, err)
+	assert.Len(t, people, 1)
+	assert.Equal(t, expert, people[0].Employee.Email)
+	assert.Equal(t, 6, people[0].Years)
+	assert.Len(t, people[0].Endorsements, 1)
+
+	people, err = FindPeopleWithSkill(t.Context(), kubernetes.ID, 1)
+	assert.NoError(t, err)
+	assert.Len(t, people, 2)
+
+	assert.NoError(t, WithdrawEndorsement(t.Context(), colleague, expert, kubernetes.ID))
+	skills, err := GetEmployeeSkills(t.Context(), expert)
+	assert.NoError(t, err)
+	assert.Empty(t, skills[0].Endorsements)
+
+	assert.NoError(t, Endorse(t.Context(), colleague, novice, kubernetes.ID))
+	assert.NoError(t, DeleteEmployee(t.Context(), colleague), "Deleting an endorser should delete their endorsements")
+	assert.NoError(t, RemoveEmployeeSkill(t.Context(), novice, kubernetes.ID))
+	skills, err = GetEmployeeSkills(t.Context(), novice)
+	assert.NoError(t, err)
+	assert.Empty(t, skills)
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"gorm.io/gorm"
+)
+
+// defaultSkills start the catalog, which admins then curate.
+var defaultSkills = []model.Skill{
+	{Name: "Go", Synonyms: "golang"},
+	{Name: "Java"},
+	{Name: "JavaScript", Synonyms: "js, ecmascript"},
+	{Name: "TypeScript", Synonyms: "ts"},
+	{Name: "Python"},
+	{Name: "Kubernetes", Synonyms: "k8s, kube"},
+	{Name: "Docker", Synonyms: "containers"},
+	{Name: "PostgreSQL", Synonyms: "postgres, psql"},
+	{Name: "Amazon Web Services", Synonyms: "aws"},
+	{Name: "Google Cloud Platform", Synonyms: "gcp, google cloud"},
+	{Name: "Micronaut"},
+	{Name: "Grails"},
+}
+
+// seedSkills fills an empty skill catalog with defaultSkills.
+func seedSkills(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&model.Skill{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	skills := append([]model.Skill(nil), defaultSkills...)
+	if err := db.Create(&skills).Error; err != nil {
+		return err
+	}
+	slog.Info("skill catalog seeded", slog.Int("skills", len(skills)))
+	return nil
+}
+
+func SaveSkill(ctx context.Context, skill *model.Skill) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	return employeeRepository.SaveSkill(ctx, skill)
+}
+
+// GetSkills returns the skill catalog ordered by name.
+func GetSkills(ctx context.Context) ([]model.Skill, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetSkills(ctx)
+}
+
+// GetSkill returns the skill with the given ID, or
+// gorm.ErrRecordNotFound when there is none.
+func GetSkill(ctx context.Context, id uint) (*model.Skill, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetSkill(ctx, id)
+}
+
+// FindSkill returns the skill whose name or one of whose synonyms is
+// name, or gorm.ErrRecordNotFound when there is none.
+func FindSkill(ctx context.Context, name string) (*model.Skill, error) {
+	skills, err := GetSkills(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if skill := model.FindSkill(skills, name); skill != nil {
+		return skill, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// GetEmployeeSkills returns the employee's skills, with their
+// endorsements, most proficient first.
+func GetEmployeeSkills(ctx context.Context, email string) ([]model.EmployeeSkill, error) {
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return employeeRepository.GetEmployeeSkills(ctx, employee.ID)
+}
+
+// SetEmployeeSkill records the employee's proficiency and years of
+// experience with a skill from the catalog.
+func SetEmployeeSkill(ctx context.Context, email string, skillID uint, proficiency int, years int) error {
+	if proficiency < model.MinProficiency || proficiency > model.MaxProficiency {
+		return fmt.Errorf("proficiency must be between %d and %d", model.MinProficiency, model.MaxProficiency)
+	}
+	if years < 0 {
+		return errors.New("years of experience cannot be negative")
+	}
+	if _, err := GetSkill(ctx, skillID); err != nil {
+		return err
+	}
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	skills, err := employeeRepository.GetEmployeeSkills(ctx, employee.ID)
+	if err != nil {
+		return err
+	}
+	employeeSkill := &model.EmployeeSkill{EmployeeID: employee.ID, SkillID: skillID}
+	for i := range skills {
+		if skills[i].SkillID == skillID {
+			employeeSkill = &skills[i]
+		}
+	}
+	employeeSkill.Proficiency = proficiency
+	employeeSkill.Years = years
+	return employeeRepository.SaveEmployeeSkill(ctx, employeeSkill)
+}
+
+// RemoveEmployeeSkill removes a skill, and its endorsements, from the
+// employee's profile.
+func RemoveEmployeeSkill(ctx context.Context, email string, skillID uint) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	return employeeRepository.DeleteEmployeeSkill(ctx, employee.ID, skillID)
+}
+
+// FindPeopleWithSkill returns the active employees with the skill at
+// minProficiency or above, most proficient and experienced first.
+func FindPeopleWithSkill(ctx context.Context, skillID uint, minProficiency int) ([]model.EmployeeSkill, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	found, err := employeeRepository.FindEmployeeSkills(ctx, skillID, minProficiency)
+	if err != nil {
+		return nil, err
+	}
+	var people []model.EmployeeSkill
+	for i := range found {
+		if found[i].Employee != nil && found[i].Employee.IsActive() {
+			people = append(people, found[i])
+		}
+	}
+	return people, nil
+}
+
+// ErrSelfEndorsement is returned by Endorse when an employee tries to
+// endorse their own skill.
+var ErrSelfEndorsement = errors.New("employees cannot endorse their own skills")
+
+// Endorse records the endorser vouching for the employee's skill. Doing
+// so again changes nothing.
+func Endorse(ctx context.Context, endorserEmail string, email string, skillID uint) error {
+	endorser, employeeSkill, err := endorsement(ctx, endorserEmail, email, skillID)
+	if err != nil {
+		return err
+	}
+	if employeeSkill.EndorsedBy(endorser.ID) {
+		return nil
+	}
+	return employeeRepository.SaveEndorsement(ctx, &model.Endorsement{EmployeeSkillID: employeeSkill.ID, EndorserID: endorser.ID})
+}
+
+// WithdrawEndorsement removes the endorser's endorsement of the
+// employee's skill.
+func WithdrawEndorsement(ctx context.Context, endorserEmail string, email string, skillID uint) error {
+	endorser, employeeSkill, err := endorsement(ctx, endorserEmail, email, skillID)
+	if err != nil {
+		return err
+	}
+	return employeeRepository.DeleteEndorsement(ctx, employeeSkill.ID, endorser.ID)
+}
+
+// endorsement looks up the endorser and the employee's skill, which
+// gorm.ErrRecordNotFound reports the employee does not have.
+func endorsement(ctx context.Context, endorserEmail string, email string, skillID uint) (*model.Employee, *model.EmployeeSkill, error) {
+	endorser, err := GetEmployeeByEmail(ctx, endorserEmail)
+	if err != nil {
+		return nil, nil, err
+	}
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return nil, nil, err
+	}
+	if employee.ID == endorser.ID {
+		return nil, nil, ErrSelfEndorsement
+	}
+	skills, err := employeeRepository.GetEmployeeSkills(ctx, employee.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range skills {
+		if skills[i].SkillID == skillID {
+			return endorser, &skills[i], nil
+		}
+	}
+	return nil, nil, gorm.ErrRecordNotFound
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jeffscottbrown/satchel/model"
+)
+
+func SaveTeam(ctx context.Context, team *model.Team) error {
+	if employeeRepository == nil {
+		return errors.New("repository has not been initialized")
+	}
+	return employeeRepository.SaveTeam(ctx, team)
+}
+
+// GetTeams returns every team, ordered by name, with its memberships but
+// not the employees they are for.
+func GetTeams(ctx context.Context) ([]model.Team, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetTeams(ctx)
+}
+
+// GetTeam returns the team with the given ID and its members, or
+// gorm.ErrRecordNotFound when there is none.
+func GetTeam(ctx context.Context, id uint) (*model.Team, error) {
+	if employeeRepository == nil {
+		return nil, errors.New("repository has not been initialized")
+	}
+	return employeeRepository.GetTeam(ctx, id)
+}
+
+// AddTeamMember puts the employee on the team in role, changing their
+// role when they are already on it.
+func AddTeamMember(ctx context.Context, teamID uint, email string, role string) error {
+	if !model.ValidTeamRole(role) {
+		return errors.New("team role must be lead or member")
+	}
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	team, err := GetTeam(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	membership := team.Membership(employee.ID)
+	if membership == nil {
+		membership = &model.TeamMembership{TeamID: team.ID, EmployeeID: employee.ID}
+	}
+	membership.Role = role
+	return employeeRepository.SaveTeamMembership(ctx, membership)
+}
+
+// RemoveTeamMember takes the employee off the team.
+func RemoveTeamMember(ctx context.Context, teamID uint, email string) error {
+	employee, err := GetEmployeeByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	return employeeRepository.DeleteTeamMembership(ctx, teamID, employee.ID)
+}
@@ -0,0 +1,63 @@
+package scim
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Error is a SCIM error response. It is returned by the functions which
+// apply changes to an employee so that handlers can report the precise
+// problem to the identity provider.
+type Error struct {
+	Status   int
+	SCIMType string
+	Detail   string
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+type errorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+func (e *Error) response() errorResponse {
+	return errorResponse{
+		Schemas:  []string{errorSchema},
+		Status:   strconv.Itoa(e.Status),
+		SCIMType: e.SCIMType,
+		Detail:   e.Detail,
+	}
+}
+
+func invalidValue(detail string) *Error {
+	return &Error{Status: http.StatusBadRequest, SCIMType: "invalidValue", Detail: detail}
+}
+
+func invalidPath(detail string) *Error {
+	return &Error{Status: http.StatusBadRequest, SCIMType: "invalidPath", Detail: detail}
+}
+
+func invalidFilter(detail string) *Error {
+	return &Error{Status: http.StatusBadRequest, SCIMType: "invalidFilter", Detail: detail}
+}
+
+func invalidSyntax(detail string) *Error {
+	return &Error{Status: http.StatusBadRequest, SCIMType: "invalidSyntax", Detail: detail}
+}
+
+func noTarget(detail string) *Error {
+	return &Error{Status: http.StatusBadRequest, SCIMType: "noTarget", Detail: detail}
+}
+
+func uniqueness(detail string) *Error {
+	return &Error{Status: http.StatusConflict, SCIMType: "uniqueness", Detail: detail}
+}
+
+func notFound(id string) *Error {
+	return &Error{Status: http.StatusNotFound, Detail: "User " + id + " not found"}
+}
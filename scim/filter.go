@@ -0,0 +1,212 @@
+package scim
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jeffscottbrown/satchel/model"
+)
+
+// filter is a parsed SCIM filter expression. Satchel supports the subset
+// identity providers use: comparisons joined by "and" and "or", without
+// parentheses, where "and" binds more tightly than "or".
+type filter [][]comparison
+
+type comparison struct {
+	attribute string
+	operator  string
+	value     string
+}
+
+// attributeValues returns the values of the attribute named by path
+// which can appear in a filter. Attribute names are not case sensitive.
+var attributeValues = map[string]func(*model.Employee) string{
+	"id": func(e *model.Employee) string {
+		return strconv.FormatUint(uint64(e.ID), 10)
+	},
+	"externalid":      func(e *model.Employee) string { return e.ExternalID },
+	"username":        func(e *model.Employee) string { return e.Email },
+	"emails.value":    func(e *model.Employee) string { return e.Email },
+	"emails":          func(e *model.Employee) string { return e.Email },
+	"displayname":     func(e *model.Employee) string { return e.Name },
+	"name.formatted":  func(e *model.Employee) string { return e.Name },
+	"name.givenname":  func(e *model.Employee) string { return e.FirstName },
+	"name.familyname": func(e *model.Employee) string { return e.LastName },
+	"title":           func(e *model.Employee) string { return e.Position },
+	"active": func(e *model.Employee) string {
+		return strconv.FormatBool(e.IsActive())
+	},
+}
+
+// parseFilter parses expression. An empty expression matches everyone.
+func parseFilter(expression string) (filter, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var parsed filter
+	var conjunction []comparison
+	for len(tokens) > 0 {
+		var c comparison
+		c, tokens, err = parseComparison(tokens)
+		if err != nil {
+			return nil, err
+		}
+		conjunction = append(conjunction, c)
+		if len(tokens) == 0 {
+			break
+		}
+		switch strings.ToLower(tokens[0].text) {
+		case "and":
+		case "or":
+			parsed = append(parsed, conjunction)
+			conjunction = nil
+		default:
+			return nil, invalidFilter("expected and or or but found " + tokens[0].text)
+		}
+		tokens = tokens[1:]
+		if len(tokens) == 0 {
+			return nil, invalidFilter("filter ends with a logical operator")
+		}
+	}
+	return append(parsed, conjunction), nil
+}
+
+func parseComparison(tokens []token) (comparison, []token, error) {
+	if len(tokens) < 2 {
+		return comparison{}, nil, invalidFilter("incomplete comparison")
+	}
+	c := comparison{attribute: normalizeAttribute(tokens[0].text), operator: strings.ToLower(tokens[1].text)}
+	if tokens[0].quoted {
+		return comparison{}, nil, invalidFilter("expected an attribute but found a value")
+	}
+	if _, ok := attributeValues[c.attribute]; !ok {
+		return comparison{}, nil, invalidFilter("filtering on " + tokens[0].text + " is not supported")
+	}
+	if c.operator == "pr" {
+		return c, tokens[2:], nil
+	}
+	switch c.operator {
+	case "eq", "ne", "co", "sw", "ew":
+	default:
+		return comparison{}, nil, invalidFilter("operator " + tokens[1].text + " is not supported")
+	}
+	if len(tokens) < 3 {
+		return comparison{}, nil, invalidFilter("comparison is missing a value")
+	}
+	c.value = tokens[2].text
+	if !tokens[2].quoted {
+		c.value = strings.ToLower(c.value)
+		if c.value != "true" && c.value != "false" && c.value != "null" {
+			return comparison{}, nil, invalidFilter("values must be quoted strings, true, false or null")
+		}
+	}
+	return c, tokens[3:], nil
+}
+
+// normalizeAttribute lower cases path, removes the core schema prefix
+// and reduces value filters such as emails[type eq "work"].value to the
+// attribute they select, since every employee has a single address.
+func normalizeAttribute(path string) string {
+	path = strings.ToLower(path)
+	path = strings.TrimPrefix(path, strings.ToLower(userSchema)+":")
+	if open := strings.Index(path, "["); open >= 0 {
+		if close := strings.Index(path, "]"); close > open {
+			path = path[:open] + path[close+1:]
+		}
+	}
+	return path
+}
+
+func (f filter) matches(employee *model.Employee) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, conjunction := range f {
+		matched := true
+		for _, c := range conjunction {
+			if !c.matches(employee) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matches compares without regard to case, as userName and email
+// addresses are not case sensitive.
+func (c comparison) matches(employee *model.Employee) bool {
+	actual := strings.ToLower(attributeValues[c.attribute](employee))
+	expected := strings.ToLower(c.value)
+	switch c.operator {
+	case "pr":
+		return actual != ""
+	case "eq":
+		return actual == expected || (expected == "null" && actual == "")
+	case "ne":
+		return actual != expected
+	case "co":
+		return strings.Contains(actual, expected)
+	case "sw":
+		return strings.HasPrefix(actual, expected)
+	case "ew":
+		return strings.HasSuffix(actual, expected)
+	}
+	return false
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits expression into words and quoted strings. Brackets
+// are kept within words so that value filters stay a single token.
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		switch {
+		case unicode.IsSpace(runes[i]):
+			i++
+		case runes[i] == '"':
+			var value strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, invalidFilter("unterminated string")
+			}
+			i++
+			tokens = append(tokens, token{text: value.String(), quoted: true})
+		case runes[i] == '(' || runes[i] == ')':
+			return nil, invalidFilter("grouping with parentheses is not supported")
+		default:
+			start := i
+			depth := 0
+			for ; i < len(runes) && (depth > 0 || !unicode.IsSpace(runes[i])); i++ {
+				switch runes[i] {
+				case '[':
+					depth++
+				case ']':
+					depth--
+				}
+			}
+			tokens = append(tokens, token{text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
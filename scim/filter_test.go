@@ -0,0 +1,62 @@
+package scim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	deactivated := time.Now()
+	jane := &model.Employee{ID: 7, Email: "jane.doe@objectcomputing.com", Name: "Jane Doe", Position: "Engineer", ExternalID: "00u1"}
+	john := &model.Employee{ID: 8, Email: "john.roe@objectcomputing.com", Name: "John Roe", DeactivatedAt: &deactivated}
+
+	tests := []struct {
+		filter string
+		jane   bool
+		john   bool
+	}{
+		{``, true, true},
+		{`userName eq "Jane.Doe@objectcomputing.com"`, true, false},
+		{`emails[type eq "work"].value eq "john.roe@objectcomputing.com"`, false, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName sw "john"`, false, true},
+		{`externalId eq "00u1"`, true, false},
+		{`id eq "8"`, false, true},
+		{`title pr`, true, false},
+		{`displayName co "o" and active eq true`, true, false},
+		{`active eq false or title eq "Engineer"`, true, true},
+		{`userName ne "jane.doe@objectcomputing.com"`, false, true},
+		{`userName ew "@objectcomputing.com" and title eq null`, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			parsed, err := parseFilter(test.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, test.jane, parsed.matches(jane))
+			assert.Equal(t, test.john, parsed.matches(john))
+		})
+	}
+}
+
+func TestParseFilter_RejectsUnsupportedExpressions(t *testing.T) {
+	for _, expression := range []string{
+		`userName eq`,
+		`userName gt "a"`,
+		`password eq "secret"`,
+		`userName eq "a" and`,
+		`userName eq "a" xor title pr`,
+		`(userName eq "a")`,
+		`userName eq "unterminated`,
+		`userName eq jane`,
+	} {
+		t.Run(expression, func(t *testing.T) {
+			_, err := parseFilter(expression)
+			var scimError *Error
+			if assert.ErrorAs(t, err, &scimError) {
+				assert.Equal(t, "invalidFilter", scimError.SCIMType)
+			}
+		})
+	}
+}
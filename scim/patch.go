@@ -0,0 +1,189 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/jeffscottbrown/satchel/model"
+)
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// patch applies the operations of request to employee. Identity
+// providers differ in how they express the same change, so both a path
+// with a value and a value holding several attributes without a path
+// are accepted, as are booleans sent as strings.
+func patch(ctx context.Context, employee *model.Employee, request patchRequest) error {
+	if len(request.Operations) == 0 {
+		return invalidValue("PATCH requires at least one operation")
+	}
+	for _, operation := range request.Operations {
+		op := strings.ToLower(operation.Op)
+		switch op {
+		case "add", "replace":
+		case "remove":
+			if operation.Path == "" {
+				return noTarget("remove requires a path")
+			}
+		default:
+			return invalidValue("unsupported operation " + operation.Op)
+		}
+
+		if operation.Path != "" {
+			if err := patchAttribute(ctx, employee, operation.Path, operation.Value, op == "remove"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+			return invalidValue("an operation without a path needs an object value")
+		}
+		for path, value := range attributes {
+			if err := patchAttribute(ctx, employee, path, value, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// patchAttribute sets, or with remove clears, the attribute at path.
+func patchAttribute(ctx context.Context, employee *model.Employee, path string, value json.RawMessage, remove bool) error {
+	attribute := normalizeAttribute(path)
+	enterprisePrefix := strings.ToLower(enterpriseUserSchema) + ":"
+
+	switch {
+	case attribute == "active":
+		if remove {
+			return invalidPath("active cannot be removed")
+		}
+		active, err := decodeBool(value)
+		if err != nil {
+			return err
+		}
+		setActive(employee, active)
+	case attribute == "username" || attribute == "emails.value" || attribute == "emails":
+		if remove {
+			return invalidPath(path + " cannot be removed")
+		}
+		var userName string
+		if attribute == "emails" {
+			var emails []Email
+			if err := json.Unmarshal(value, &emails); err != nil || len(emails) == 0 {
+				return invalidValue("emails must be a list of email addresses")
+			}
+			userName = User{Emails: emails}.userName()
+		} else {
+			s, err := decodeString(value, remove)
+			if err != nil {
+				return err
+			}
+			userName = normalizeEmail(s)
+		}
+		return setUserName(ctx, employee, userName)
+	case attribute == "externalid":
+		return setString(&employee.ExternalID, value, remove)
+	case attribute == "displayname" || attribute == "name.formatted":
+		return setString(&employee.Name, value, remove)
+	case attribute == "name.givenname":
+		return setString(&employee.FirstName, value, remove)
+	case attribute == "name.familyname":
+		return setString(&employee.LastName, value, remove)
+	case attribute == "title":
+		return setString(&employee.Position, value, remove)
+	case attribute == "name":
+		if remove {
+			employee.FirstName, employee.LastName, employee.Name = "", "", ""
+			return nil
+		}
+		var name Name
+		if err := json.Unmarshal(value, &name); err != nil {
+			return invalidValue("name must be an object")
+		}
+		setName(employee, name)
+		if formatted := displayName("", name); formatted != "" {
+			employee.Name = formatted
+		}
+	case attribute == enterprisePrefix+"manager" || attribute == enterprisePrefix+"manager.value" || attribute == "manager":
+		if remove {
+			return setManager(ctx, employee, "")
+		}
+		id, err := decodeManager(value)
+		if err != nil {
+			return err
+		}
+		return setManager(ctx, employee, id)
+	case attribute == strings.TrimSuffix(enterprisePrefix, ":"):
+		var enterprise EnterpriseUser
+		if err := json.Unmarshal(value, &enterprise); err != nil {
+			return invalidValue("the enterprise extension must be an object")
+		}
+		if enterprise.Manager != nil {
+			return setManager(ctx, employee, enterprise.Manager.Value)
+		}
+	default:
+		return invalidPath("unsupported attribute " + path)
+	}
+	return nil
+}
+
+func setString(target *string, value json.RawMessage, remove bool) error {
+	s, err := decodeString(value, remove)
+	if err != nil {
+		return err
+	}
+	*target = s
+	return nil
+}
+
+func decodeString(value json.RawMessage, remove bool) (string, error) {
+	if remove {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return "", invalidValue("expected a string but found " + string(value))
+	}
+	return strings.TrimSpace(s), nil
+}
+
+func decodeBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if parsed, err := strconv.ParseBool(s); err == nil {
+			return parsed, nil
+		}
+	}
+	return false, invalidValue("expected a boolean but found " + string(value))
+}
+
+// decodeManager accepts a manager ID either on its own or as the value
+// of a manager object.
+func decodeManager(value json.RawMessage) (string, error) {
+	var manager Manager
+	if err := json.Unmarshal(value, &manager); err == nil {
+		return manager.Value, nil
+	}
+	var id string
+	if err := json.Unmarshal(value, &id); err == nil {
+		return id, nil
+	}
+	return "", invalidValue("manager must be an ID or an object with a value")
+}
@@ -0,0 +1,248 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	userSchema           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	enterpriseUserSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	listResponseSchema   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	patchOpSchema        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	errorSchema          = "urn:ietf:params:scim:api:messages:2.0:Error"
+	serviceConfigSchema  = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// User is the SCIM representation of a model.Employee. The employee's
+// email address is the userName and the ID is the database ID.
+type User struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *Name           `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Title       string          `json:"title,omitempty"`
+	Emails      []Email         `json:"emails,omitempty"`
+	Active      *bool           `json:"active,omitempty"`
+	Enterprise  *EnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta        *Meta           `json:"meta,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type EnterpriseUser struct {
+	Manager *Manager `json:"manager,omitempty"`
+}
+
+// Manager refers to another user by ID.
+type Manager struct {
+	Value       string `json:"value"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []User   `json:"Resources"`
+}
+
+// newUser builds the SCIM representation of employee. manager is the
+// employee's manager, or nil when they have none or they are unknown.
+func newUser(employee *model.Employee, manager *model.Employee, location string) User {
+	active := employee.IsActive()
+	user := User{
+		Schemas:     []string{userSchema},
+		ID:          strconv.FormatUint(uint64(employee.ID), 10),
+		ExternalID:  employee.ExternalID,
+		UserName:    employee.Email,
+		DisplayName: employee.Name,
+		Title:       employee.Position,
+		Emails:      []Email{{Value: employee.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta:        &Meta{ResourceType: "User", Location: location},
+	}
+	if employee.FirstName != "" || employee.LastName != "" || employee.Name != "" {
+		user.Name = &Name{Formatted: employee.Name, GivenName: employee.FirstName, FamilyName: employee.LastName}
+	}
+	if manager != nil {
+		user.Schemas = append(user.Schemas, enterpriseUserSchema)
+		user.Enterprise = &EnterpriseUser{Manager: &Manager{
+			Value:       strconv.FormatUint(uint64(manager.ID), 10),
+			DisplayName: manager.Name,
+		}}
+	}
+	return user
+}
+
+// userName returns the address the user should be identified by: the
+// userName, falling back to the primary or first email address.
+func (u User) userName() string {
+	if u.UserName != "" {
+		return normalizeEmail(u.UserName)
+	}
+	for _, email := range u.Emails {
+		if email.Primary {
+			return normalizeEmail(email.Value)
+		}
+	}
+	if len(u.Emails) > 0 {
+		return normalizeEmail(u.Emails[0].Value)
+	}
+	return ""
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// replace overwrites the fields of employee which SCIM manages with
+// those of user, as a PUT does. Fields Satchel owns, such as the bio,
+// are left alone. The title and name parts are only changed when user
+// specifies them, since employees can also edit them in Satchel, and
+// Active is only changed when user specifies it.
+func replace(ctx context.Context, employee *model.Employee, user User) error {
+	userName := user.userName()
+	if userName == "" {
+		return invalidValue("userName is required")
+	}
+	if err := setUserName(ctx, employee, userName); err != nil {
+		return err
+	}
+	employee.ExternalID = user.ExternalID
+	if user.Title != "" {
+		employee.Position = user.Title
+	}
+
+	var name Name
+	if user.Name != nil {
+		name = *user.Name
+	}
+	setName(employee, name)
+	if formatted := displayName(user.DisplayName, name); formatted != "" {
+		employee.Name = formatted
+	}
+
+	if user.Active != nil {
+		setActive(employee, *user.Active)
+	}
+
+	var managerID string
+	if user.Enterprise != nil && user.Enterprise.Manager != nil {
+		managerID = user.Enterprise.Manager.Value
+	}
+	return setManager(ctx, employee, managerID)
+}
+
+// setName copies the parts of name which are present to employee.
+func setName(employee *model.Employee, name Name) {
+	if name.GivenName != "" {
+		employee.FirstName = name.GivenName
+	}
+	if name.FamilyName != "" {
+		employee.LastName = name.FamilyName
+	}
+}
+
+func displayName(displayName string, name Name) string {
+	switch {
+	case displayName != "":
+		return displayName
+	case name.Formatted != "":
+		return name.Formatted
+	}
+	return strings.TrimSpace(name.GivenName + " " + name.FamilyName)
+}
+
+// setUserName changes the employee's email address, refusing to take
+// one which belongs to somebody else.
+func setUserName(ctx context.Context, employee *model.Employee, userName string) error {
+	if userName == employee.Email {
+		return nil
+	}
+	if !strings.Contains(userName, "@") {
+		return invalidValue("userName must be an email address")
+	}
+	existing, err := repository.GetEmployeeByEmail(ctx, userName)
+	switch {
+	case err == nil && existing.ID != employee.ID:
+		return uniqueness("userName " + userName + " is already in use")
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+	employee.Email = userName
+	return nil
+}
+
+func setActive(employee *model.Employee, active bool) {
+	switch {
+	case active:
+		employee.DeactivatedAt = nil
+	case employee.DeactivatedAt == nil:
+		now := time.Now()
+		employee.DeactivatedAt = &now
+	}
+}
+
+// setManager records the manager with the given SCIM ID, or clears the
+// manager when id is blank.
+func setManager(ctx context.Context, employee *model.Employee, id string) error {
+	if id == "" {
+		employee.ManagerEmail = ""
+		return nil
+	}
+	manager, err := findEmployee(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidValue("manager " + id + " does not exist")
+		}
+		return err
+	}
+	if manager.ID == employee.ID {
+		return invalidValue("a user cannot be their own manager")
+	}
+	employees, err := repository.GetAllEmployees(ctx)
+	if err != nil {
+		return err
+	}
+	if model.NewManagers(employees).WouldCycle(employee.Email, manager.Email) {
+		return invalidValue("manager " + id + " reports to the user")
+	}
+	employee.ManagerEmail = manager.Email
+	return nil
+}
+
+// findEmployee looks an employee up by SCIM ID. IDs which are not
+// numbers cannot exist and are reported as not found.
+func findEmployee(ctx context.Context, id string) (*model.Employee, error) {
+	parsed, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || parsed == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return repository.GetEmployeeByID(ctx, uint(parsed))
+}
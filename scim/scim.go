@@ -0,0 +1,327 @@
+// Package scim lets an identity provider create, update and deactivate
+// employee profiles using SCIM 2.0 (RFC 7643 and RFC 7644).
+//
+// Users are deactivated rather than deleted when they are deprovisioned,
+// whether by a PATCH setting active to false or by a DELETE, so that
+// their profile survives if they return. A deactivated user remains
+// visible over SCIM with active set to false.
+package scim
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// ContentType is the media type of every SCIM request and response.
+const ContentType = "application/scim+json"
+
+const (
+	defaultPageSize = 100
+	maximumPageSize = 200
+)
+
+var bearerToken string
+
+// Configure sets the bearer token identity providers must present. SCIM
+// requests are refused while no token is configured.
+func Configure(settings config.SCIM) {
+	bearerToken = settings.Token
+	if bearerToken == "" {
+		slog.Debug("SCIM provisioning is disabled because no token is configured")
+	}
+}
+
+// ConfigureRoutes registers the SCIM endpoints under /scim/v2.
+func ConfigureRoutes(router *gin.Engine) {
+	group := router.Group("/scim/v2", authenticate)
+	group.GET("/ServiceProviderConfig", serviceProviderConfigHandler)
+	group.GET("/Users", listUsersHandler)
+	group.POST("/Users", createUserHandler)
+	group.GET("/Users/:id", getUserHandler)
+	group.PUT("/Users/:id", replaceUserHandler)
+	group.PATCH("/Users/:id", patchUserHandler)
+	group.DELETE("/Users/:id", deleteUserHandler)
+}
+
+func authenticate(c *gin.Context) {
+	presented, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if bearerToken == "" || !found || subtle.ConstantTimeCompare([]byte(presented), []byte(bearerToken)) != 1 {
+		c.Header("WWW-Authenticate", `Bearer realm="satchel-scim"`)
+		writeError(c, &Error{Status: http.StatusUnauthorized, Detail: "a valid bearer token is required"})
+		c.Abort()
+		return
+	}
+	logging.With(c.Request.Context(), slog.String("user", "scim"))
+	c.Next()
+}
+
+func writeJSON(c *gin.Context, status int, body any) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Data(status, ContentType, encoded)
+}
+
+// writeError responds with err, which is reported as an internal error
+// unless it is an *Error.
+func writeError(c *gin.Context, err error) {
+	var scimError *Error
+	if !errors.As(err, &scimError) {
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).ErrorContext(ctx, "SCIM request failed", slog.Any("error", err))
+		scimError = &Error{Status: http.StatusInternalServerError, Detail: "internal error"}
+	}
+	encoded, _ := json.Marshal(scimError.response())
+	c.Data(scimError.Status, ContentType, encoded)
+}
+
+func location(c *gin.Context, employee *model.Employee) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + "/scim/v2/Users/" + strconv.FormatUint(uint64(employee.ID), 10)
+}
+
+// decodeBody reads a JSON request body into target. Identity providers
+// send both application/scim+json and application/json.
+func decodeBody(c *gin.Context, target any) error {
+	if err := json.NewDecoder(c.Request.Body).Decode(target); err != nil {
+		return invalidSyntax("the request body is not valid JSON: " + err.Error())
+	}
+	return nil
+}
+
+func serviceProviderConfigHandler(c *gin.Context) {
+	supported := func(value bool) map[string]bool { return map[string]bool{"supported": value} }
+	writeJSON(c, http.StatusOK, map[string]any{
+		"schemas":        []string{serviceConfigSchema},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": maximumPageSize},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Authentication with the token configured as SATCHEL_SCIM_TOKEN",
+			"primary":     true,
+		}},
+	})
+}
+
+func listUsersHandler(c *gin.Context) {
+	startIndex, err := queryInt(c, "startIndex", 1)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	count, err := queryInt(c, "count", defaultPageSize)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	startIndex = max(startIndex, 1)
+	count = min(max(count, 0), maximumPageSize)
+
+	parsed, err := parseFilter(c.Query("filter"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	employees, err := repository.GetAllEmployees(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	byEmail := map[string]*model.Employee{}
+	var matching []*model.Employee
+	for i := range employees {
+		byEmail[employees[i].Email] = &employees[i]
+		if parsed.matches(&employees[i]) {
+			matching = append(matching, &employees[i])
+		}
+	}
+
+	response := ListResponse{
+		Schemas:      []string{listResponseSchema},
+		TotalResults: len(matching),
+		StartIndex:   startIndex,
+		Resources:    []User{},
+	}
+	for i := startIndex - 1; i < len(matching) && len(response.Resources) < count; i++ {
+		employee := matching[i]
+		response.Resources = append(response.Resources, newUser(employee, byEmail[employee.ManagerEmail], location(c, employee)))
+	}
+	response.ItemsPerPage = len(response.Resources)
+	writeJSON(c, http.StatusOK, response)
+}
+
+func queryInt(c *gin.Context, name string, fallback int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, invalidValue(name + " must be a number")
+	}
+	return parsed, nil
+}
+
+func getUserHandler(c *gin.Context) {
+	employee, err := lookup(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	respondWithUser(c, http.StatusOK, employee)
+}
+
+// createUserHandler provisions a new employee. Provisioning the
+// userName of a deactivated employee reactivates their profile, so that
+// people who return keep their bio and reflections.
+func createUserHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var user User
+	if err := decodeBody(c, &user); err != nil {
+		writeError(c, err)
+		return
+	}
+	userName := user.userName()
+	if userName == "" {
+		writeError(c, invalidValue("userName is required"))
+		return
+	}
+
+	employee, err := repository.GetEmployeeByEmail(ctx, userName)
+	switch {
+	case err == nil && employee.IsActive():
+		writeError(c, uniqueness("userName "+userName+" is already in use"))
+		return
+	case err == nil:
+		employee.DeactivatedAt = nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		employee = &model.Employee{}
+	default:
+		writeError(c, err)
+		return
+	}
+
+	if user.Active == nil {
+		active := true
+		user.Active = &active
+	}
+	if err := replace(ctx, employee, user); err != nil {
+		writeError(c, err)
+		return
+	}
+	if err := repository.SaveEmployee(ctx, employee); err != nil {
+		writeError(c, err)
+		return
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "employee provisioned over SCIM", slog.String("email", employee.Email))
+	respondWithUser(c, http.StatusCreated, employee)
+}
+
+func replaceUserHandler(c *gin.Context) {
+	update(c, func(employee *model.Employee) error {
+		var user User
+		if err := decodeBody(c, &user); err != nil {
+			return err
+		}
+		return replace(c.Request.Context(), employee, user)
+	})
+}
+
+func patchUserHandler(c *gin.Context) {
+	update(c, func(employee *model.Employee) error {
+		var request patchRequest
+		if err := decodeBody(c, &request); err != nil {
+			return err
+		}
+		return patch(c.Request.Context(), employee, request)
+	})
+}
+
+// deleteUserHandler deprovisions the user by deactivating them.
+func deleteUserHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	employee, err := lookup(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if err := repository.DeactivateEmployee(ctx, employee.Email); err != nil {
+		writeError(c, err)
+		return
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "employee deprovisioned over SCIM", slog.String("email", employee.Email))
+	c.Status(http.StatusNoContent)
+}
+
+// update loads the employee named in the request, applies change and
+// saves the result.
+func update(c *gin.Context, change func(*model.Employee) error) {
+	ctx := c.Request.Context()
+	employee, err := lookup(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	wasActive := employee.IsActive()
+	if err := change(employee); err != nil {
+		writeError(c, err)
+		return
+	}
+	if err := repository.SaveEmployee(ctx, employee); err != nil {
+		writeError(c, err)
+		return
+	}
+	if wasActive && !employee.IsActive() {
+		if _, err := repository.DeleteSessions(ctx, employee.Email); err != nil {
+			writeError(c, err)
+			return
+		}
+		logging.FromContext(ctx).InfoContext(ctx, "employee deprovisioned over SCIM", slog.String("email", employee.Email))
+	}
+	respondWithUser(c, http.StatusOK, employee)
+}
+
+func lookup(c *gin.Context) (*model.Employee, error) {
+	employee, err := findEmployee(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, notFound(c.Param("id"))
+	}
+	return employee, err
+}
+
+func respondWithUser(c *gin.Context, status int, employee *model.Employee) {
+	var manager *model.Employee
+	if employee.ManagerEmail != "" {
+		found, err := repository.GetEmployeeByEmail(c.Request.Context(), employee.ManagerEmail)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			writeError(c, err)
+			return
+		}
+		manager = found
+	}
+	c.Header("Location", location(c, employee))
+	writeJSON(c, status, newUser(employee, manager, location(c, employee)))
+}
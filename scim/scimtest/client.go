@@ -0,0 +1,143 @@
+// Package scimtest is a small SCIM client which plays the part of an
+// identity provider, so that provisioning can be exercised against a
+// test server or a locally running instance of Satchel.
+package scimtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jeffscottbrown/satchel/scim"
+)
+
+// Client sends SCIM requests to the server at BaseURL, such as
+// http://localhost:8080/scim/v2, authenticating with Token.
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+func NewClient(baseURL string, token string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), Token: token, HTTPClient: http.DefaultClient}
+}
+
+// StatusError is returned when the server responds with an error.
+type StatusError struct {
+	Status   int
+	SCIMType string
+	Detail   string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("SCIM request failed with status %d (%s): %s", e.Status, e.SCIMType, e.Detail)
+}
+
+// Operation is a single PATCH operation.
+type Operation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Do sends body, when it is not nil, as JSON and decodes a successful
+// response into result, when it is not nil.
+func (c *Client) Do(ctx context.Context, method string, path string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", scim.ContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", scim.ContentType)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var failure struct {
+			SCIMType string `json:"scimType"`
+			Detail   string `json:"detail"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&failure)
+		return &StatusError{Status: res.StatusCode, SCIMType: failure.SCIMType, Detail: failure.Detail}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
+
+func (c *Client) CreateUser(ctx context.Context, user scim.User) (scim.User, error) {
+	var created scim.User
+	err := c.Do(ctx, http.MethodPost, "/Users", user, &created)
+	return created, err
+}
+
+func (c *Client) GetUser(ctx context.Context, id string) (scim.User, error) {
+	var user scim.User
+	err := c.Do(ctx, http.MethodGet, "/Users/"+id, nil, &user)
+	return user, err
+}
+
+// ListUsers returns a page of the users matching filter. A blank filter
+// matches every user and a zero count uses the server's page size.
+func (c *Client) ListUsers(ctx context.Context, filter string, startIndex int, count int) (scim.ListResponse, error) {
+	query := url.Values{}
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+	if startIndex > 0 {
+		query.Set("startIndex", strconv.Itoa(startIndex))
+	}
+	if count > 0 {
+		query.Set("count", strconv.Itoa(count))
+	}
+	path := "/Users"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var list scim.ListResponse
+	err := c.Do(ctx, http.MethodGet, path, nil, &list)
+	return list, err
+}
+
+func (c *Client) ReplaceUser(ctx context.Context, id string, user scim.User) (scim.User, error) {
+	var replaced scim.User
+	err := c.Do(ctx, http.MethodPut, "/Users/"+id, user, &replaced)
+	return replaced, err
+}
+
+func (c *Client) PatchUser(ctx context.Context, id string, operations ...Operation) (scim.User, error) {
+	body := map[string]any{
+		"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		"Operations": operations,
+	}
+	var patched scim.User
+	err := c.Do(ctx, http.MethodPatch, "/Users/"+id, body, &patched)
+	return patched, err
+}
+
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.Do(ctx, http.MethodDelete, "/Users/"+id, nil, nil)
+}
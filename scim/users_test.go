@@ -0,0 +1,241 @@
+// The tests in this file provision users through scimtest.Client, as an
+// identity provider would, so they live outside the scim package.
+
+package scim_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/jeffscottbrown/satchel/scim"
+	"github.com/jeffscottbrown/satchel/scim/scimtest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testToken = "a-test-token-which-is-long-enough-for-scim"
+
+// newClient starts a server with the SCIM routes and returns a client
+// for it, as an identity provider would use.
+func newClient(t *testing.T) *scimtest.Client {
+	scim.Configure(config.SCIM{Token: testToken})
+	t.Cleanup(func() { scim.Configure(config.SCIM{}) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	scim.ConfigureRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return scimtest.NewClient(server.URL+"/scim/v2", testToken)
+}
+
+func deleteEmployees(t *testing.T, emails ...string) {
+	t.Cleanup(func() {
+		for _, email := range emails {
+			repository.DeleteEmployee(context.Background(), email)
+		}
+	})
+}
+
+func requireStatus(t *testing.T, status int, err error) {
+	t.Helper()
+	var statusError *scimtest.StatusError
+	if assert.ErrorAs(t, err, &statusError) {
+		assert.Equal(t, status, statusError.Status)
+	}
+}
+
+func TestAuthenticate_RequiresToken(t *testing.T) {
+	client := newClient(t)
+
+	client.Token = ""
+	_, err := client.ListUsers(t.Context(), "", 0, 0)
+	requireStatus(t, http.StatusUnauthorized, err)
+
+	client.Token = "not-the-token"
+	_, err = client.ListUsers(t.Context(), "", 0, 0)
+	requireStatus(t, http.StatusUnauthorized, err)
+}
+
+func TestAuthenticate_RefusesEveryTokenWhenNoneIsConfigured(t *testing.T) {
+	client := newClient(t)
+	scim.Configure(config.SCIM{})
+
+	client.Token = ""
+	_, err := client.ListUsers(t.Context(), "", 0, 0)
+	requireStatus(t, http.StatusUnauthorized, err)
+}
+
+func TestUsers_ProvisioningLifecycle(t *testing.T) {
+	client := newClient(t)
+	ctx := t.Context()
+	deleteEmployees(t, "jane.doe@objectcomputing.com", "jane.roe@objectcomputing.com")
+
+	created, err := client.CreateUser(ctx, scim.User{
+		UserName:   "Jane.Doe@objectcomputing.com",
+		ExternalID: "00u1",
+		Name:       &scim.Name{GivenName: "Jane", FamilyName: "Doe"},
+		Title:      "Engineer",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "jane.doe@objectcomputing.com", created.UserName)
+	assert.Equal(t, "Jane Doe", created.DisplayName)
+	assert.True(t, *created.Active)
+	assert.Contains(t, created.Meta.Location, "/scim/v2/Users/"+created.ID)
+
+	_, err = client.CreateUser(ctx, scim.User{UserName: "jane.doe@objectcomputing.com"})
+	requireStatus(t, http.StatusConflict, err)
+
+	found, err := client.ListUsers(ctx, `userName eq "jane.doe@objectcomputing.com"`, 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, found.Resources, 1)
+	assert.Equal(t, created.ID, found.Resources[0].ID)
+
+	patched, err := client.PatchUser(ctx, created.ID,
+		scimtest.Operation{Op: "replace", Path: "title", Value: "Principal Engineer"},
+		scimtest.Operation{Op: "Replace", Value: map[string]any{"userName": "jane.roe@objectcomputing.com", "name.familyName": "Roe"}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "Principal Engineer", patched.Title)
+	assert.Equal(t, "jane.roe@objectcomputing.com", patched.UserName)
+	assert.Equal(t, "Roe", patched.Name.FamilyName)
+
+	patched, err = client.PatchUser(ctx, created.ID, scimtest.Operation{Op: "replace", Path: "active", Value: "False"})
+	assert.NoError(t, err)
+	assert.False(t, *patched.Active)
+	employees, err := repository.GetEmployees(ctx)
+	assert.NoError(t, err)
+	for i := range employees {
+		assert.NotEqual(t, "jane.roe@objectcomputing.com", employees[i].Email, "Deactivated employees should leave the directory")
+	}
+
+	reactivated, err := client.CreateUser(ctx, scim.User{UserName: "jane.roe@objectcomputing.com", DisplayName: "Jane Roe"})
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, reactivated.ID, "Provisioning a deactivated user should reactivate their profile")
+	assert.True(t, *reactivated.Active)
+
+	assert.NoError(t, client.DeleteUser(ctx, created.ID))
+	deleted, err := client.GetUser(ctx, created.ID)
+	assert.NoError(t, err, "Deprovisioned users should be deactivated rather than deleted")
+	assert.False(t, *deleted.Active)
+}
+
+func TestUsers_ReplaceKeepsOmittedTitleAndName(t *testing.T) {
+	client := newClient(t)
+	ctx := t.Context()
+	email := "kept.fields@objectcomputing.com"
+	deleteEmployees(t, email)
+
+	created, err := client.CreateUser(ctx, scim.User{
+		UserName: email,
+		Name:     &scim.Name{GivenName: "Kept", FamilyName: "Fields"},
+		Title:    "Engineer",
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, repository.SavePosition(ctx, email, "Staff Engineer"))
+
+	replaced, err := client.ReplaceUser(ctx, created.ID, scim.User{UserName: email, ExternalID: "00u9"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Staff Engineer", replaced.Title, "A PUT without a title should keep the position the employee edited")
+	assert.Equal(t, "Kept", replaced.Name.GivenName)
+	assert.Equal(t, "Fields", replaced.Name.FamilyName)
+	assert.Equal(t, "00u9", replaced.ExternalID)
+
+	patched, err := client.PatchUser(ctx, created.ID, scimtest.Operation{Op: "replace", Path: "name", Value: map[string]any{"givenName": "Still"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Still", patched.Name.GivenName)
+	assert.Equal(t, "Fields", patched.Name.FamilyName, "Patching part of the name should keep the rest")
+}
+
+func TestUsers_Pagination(t *testing.T) {
+	client := newClient(t)
+	ctx := t.Context()
+	emails := []string{"page.one@example.com", "page.two@example.com", "page.three@example.com"}
+	deleteEmployees(t, emails...)
+	for _, email := range emails {
+		_, err := client.CreateUser(ctx, scim.User{UserName: email})
+		assert.NoError(t, err)
+	}
+
+	filter := `userName sw "page."`
+	first, err := client.ListUsers(ctx, filter, 1, 2)
+	assert.NoError(t, err)
+	second, err := client.ListUsers(ctx, filter, 3, 2)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, first.TotalResults)
+	assert.Equal(t, 2, first.ItemsPerPage)
+	assert.Equal(t, 1, second.ItemsPerPage)
+	assert.Equal(t, 3, second.StartIndex)
+	assert.Equal(t, "page.three@example.com", second.Resources[0].UserName)
+}
+
+func TestUsers_Manager(t *testing.T) {
+	client := newClient(t)
+	ctx := t.Context()
+	deleteEmployees(t, "boss@example.com", "boss.renamed@example.com", "report@example.com")
+	boss, err := client.CreateUser(ctx, scim.User{UserName: "boss@example.com", DisplayName: "The Boss"})
+	assert.NoError(t, err)
+
+	report, err := client.CreateUser(ctx, scim.User{
+		UserName:   "report@example.com",
+		Enterprise: &scim.EnterpriseUser{Manager: &scim.Manager{Value: boss.ID}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, boss.ID, report.Enterprise.Manager.Value)
+	assert.Equal(t, "The Boss", report.Enterprise.Manager.DisplayName)
+	employee, err := repository.GetEmployeeByEmail(ctx, "report@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "boss@example.com", employee.ManagerEmail)
+
+	_, err = client.PatchUser(ctx, report.ID, scimtest.Operation{Op: "replace", Path: "manager", Value: report.ID})
+	requireStatus(t, http.StatusBadRequest, err)
+	_, err = client.PatchUser(ctx, boss.ID, scimtest.Operation{Op: "replace", Path: "manager", Value: report.ID})
+	requireStatus(t, http.StatusBadRequest, err)
+
+	_, err = client.PatchUser(ctx, boss.ID, scimtest.Operation{Op: "replace", Path: "userName", Value: "boss.renamed@example.com"})
+	assert.NoError(t, err)
+	report, err = client.GetUser(ctx, report.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, report.Enterprise, "Renaming the manager should not lose their reports") {
+		assert.Equal(t, boss.ID, report.Enterprise.Manager.Value)
+	}
+	employee, err = repository.GetEmployeeByEmail(ctx, "report@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "boss.renamed@example.com", employee.ManagerEmail)
+
+	report, err = client.PatchUser(ctx, report.ID, scimtest.Operation{Op: "remove", Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager"})
+	assert.NoError(t, err)
+	assert.Nil(t, report.Enterprise)
+}
+
+func TestUsers_UnknownID(t *testing.T) {
+	client := newClient(t)
+
+	for _, id := range []string{"999999", "not-a-number"} {
+		_, err := client.GetUser(t.Context(), id)
+		requireStatus(t, http.StatusNotFound, err)
+		err = client.DeleteUser(t.Context(), id)
+		requireStatus(t, http.StatusNotFound, err)
+	}
+}
+
+func TestUsers_InvalidFilter(t *testing.T) {
+	client := newClient(t)
+
+	_, err := client.ListUsers(t.Context(), `password eq "secret"`, 0, 0)
+
+	var statusError *scimtest.StatusError
+	if assert.ErrorAs(t, err, &statusError) {
+		assert.Equal(t, http.StatusBadRequest, statusError.Status)
+		assert.Equal(t, "invalidFilter", statusError.SCIMType)
+	}
+}
+
+func TestMain(m *testing.M) {
+	repository.RunTestsWithTestContainer(m)
+}
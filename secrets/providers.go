@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gcpsecrets "github.com/jeffscottbrown/gogoogle/secrets"
+)
+
+// EnvironmentProvider reads secrets from environment variables of the
+// same name.
+type EnvironmentProvider struct{}
+
+func (EnvironmentProvider) Name() string {
+	return "env"
+}
+
+func (EnvironmentProvider) RetrieveSecret(name string) (string, error) {
+	value, found := os.LookupEnv(name)
+	if !found || value == "" {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+// FileProvider reads each secret from a file of the same name in
+// Directory, which is how Kubernetes and Docker mount secrets.
+type FileProvider struct {
+	Directory string
+}
+
+func FileProviderFromEnvironment() FileProvider {
+	directory := os.Getenv("SATCHEL_SECRETS_DIR")
+	if directory == "" {
+		directory = "/run/secrets"
+	}
+	return FileProvider{Directory: directory}
+}
+
+func (FileProvider) Name() string {
+	return "file"
+}
+
+func (p FileProvider) RetrieveSecret(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", ErrSecretNotFound
+	}
+	contents, err := os.ReadFile(filepath.Join(p.Directory, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(contents), "\r\n"), nil
+}
+
+// GCPProvider reads secrets from the GCP secret manager of the project
+// named by PROJECT_ID.
+type GCPProvider struct{}
+
+func (GCPProvider) Name() string {
+	return "gcp"
+}
+
+func (GCPProvider) RetrieveSecret(name string) (string, error) {
+	if os.Getenv("PROJECT_ID") == "" {
+		return "", ErrSecretNotFound
+	}
+	return gcpsecrets.RetrieveSecret(name)
+}
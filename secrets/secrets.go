@@ -0,0 +1,159 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrSecretNotFound is returned by a SecretProvider which does not hold
+// the requested secret. A Chain moves on to its next provider when it
+// sees this error.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider retrieves named secrets from a single backend.
+type SecretProvider interface {
+	Name() string
+	RetrieveSecret(name string) (string, error)
+}
+
+// Chain consults each of its providers in order and returns the first
+// value found. Resolved secrets are cached for the life of the Chain;
+// secrets which no provider holds, or which could not be fetched, are
+// looked up again next time.
+type Chain struct {
+	providers []SecretProvider
+	mu        sync.Mutex
+	cache     map[string]string
+	lookups   singleflight.Group
+}
+
+func NewChain(providers ...SecretProvider) *Chain {
+	return &Chain{
+		providers: providers,
+		cache:     map[string]string{},
+	}
+}
+
+// Lookup returns the value of the named secret and whether any provider
+// held it.
+func (c *Chain) Lookup(name string) (string, bool) {
+	c.mu.Lock()
+	value, ok := c.cache[name]
+	c.mu.Unlock()
+	if ok {
+		return value, true
+	}
+
+	// Providers may go over the network, so they are consulted without
+	// holding the lock. Concurrent lookups of the same name share one
+	// fetch.
+	result, err, _ := c.lookups.Do(name, func() (any, error) {
+		value, err := c.fetch(name)
+		if err != nil {
+			return "", err
+		}
+		c.mu.Lock()
+		c.cache[name] = value
+		c.mu.Unlock()
+		return value, nil
+	})
+	if err != nil {
+		return "", false
+	}
+	return result.(string), true
+}
+
+func (c *Chain) fetch(name string) (string, error) {
+	for _, provider := range c.providers {
+		value, err := provider.RetrieveSecret(name)
+		if err == nil {
+			slog.Debug("Resolved secret", slog.String("secretName", name), slog.String("provider", provider.Name()))
+			return value, nil
+		}
+		if !errors.Is(err, ErrSecretNotFound) {
+			slog.Warn("Secret provider failed", slog.String("secretName", name), slog.String("provider", provider.Name()), slog.Any("error", err))
+		}
+	}
+	return "", ErrSecretNotFound
+}
+
+// Name implements SecretProvider.
+func (c *Chain) Name() string {
+	names := make([]string, 0, len(c.providers))
+	for _, provider := range c.providers {
+		names = append(names, provider.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+// RetrieveSecret implements SecretProvider.
+func (c *Chain) RetrieveSecret(name string) (string, error) {
+	if value, found := c.Lookup(name); found {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+// ChainFromEnvironment builds a Chain from the comma separated provider
+// names in SATCHEL_SECRET_PROVIDERS. When that variable is not set the
+// GCP secret manager is tried before the environment.
+//
+//	env    - environment variables
+//	file   - one file per secret in SATCHEL_SECRETS_DIR (default /run/secrets)
+//	vault  - KV v2 secret at VAULT_ADDR using VAULT_TOKEN
+//	gcp    - GCP secret manager in PROJECT_ID
+func ChainFromEnvironment() (*Chain, error) {
+	names := os.Getenv("SATCHEL_SECRET_PROVIDERS")
+	if names == "" {
+		names = "gcp,env"
+	}
+
+	var providers []SecretProvider
+	var problems []error
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case "env":
+			providers = append(providers, EnvironmentProvider{})
+		case "file":
+			providers = append(providers, FileProviderFromEnvironment())
+		case "vault":
+			vault, err := VaultProviderFromEnvironment()
+			if err != nil {
+				problems = append(problems, err)
+				continue
+			}
+			providers = append(providers, vault)
+		case "gcp":
+			providers = append(providers, GCPProvider{})
+		case "":
+		default:
+			problems = append(problems, fmt.Errorf("unknown secret provider %q", name))
+		}
+	}
+	return NewChain(providers...), errors.Join(problems...)
+}
+
+var (
+	defaultChain     *Chain
+	defaultChainOnce sync.Once
+)
+
+// Default returns the process wide Chain, built by ChainFromEnvironment
+// the first time it is needed.
+func Default() *Chain {
+	defaultChainOnce.Do(func() {
+		chain, err := ChainFromEnvironment()
+		if err != nil {
+			slog.Error("Problem configuring secret providers", slog.Any("error", err))
+		}
+		slog.Debug("Configured secret providers", slog.String("providers", chain.Name()))
+		defaultChain = chain
+	})
+	return defaultChain
+}
@@ -0,0 +1,148 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingProvider struct {
+	name   string
+	values map[string]string
+	err    error
+	calls  int
+}
+
+func (p *countingProvider) Name() string {
+	return p.name
+}
+
+func (p *countingProvider) RetrieveSecret(name string) (string, error) {
+	p.calls++
+	if p.err != nil {
+		return "", p.err
+	}
+	value, found := p.values[name]
+	if !found {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func TestChain_ConsultsProvidersInOrder(t *testing.T) {
+	first := &countingProvider{name: "first", values: map[string]string{"A": "from first"}}
+	second := &countingProvider{name: "second", values: map[string]string{"A": "from second", "B": "from second"}}
+	chain := NewChain(first, second)
+
+	value, found := chain.Lookup("A")
+	assert.True(t, found)
+	assert.Equal(t, "from first", value)
+
+	value, found = chain.Lookup("B")
+	assert.True(t, found)
+	assert.Equal(t, "from second", value)
+
+	_, found = chain.Lookup("C")
+	assert.False(t, found)
+	assert.Equal(t, "first,second", chain.Name())
+}
+
+func TestChain_CachesResolvedSecrets(t *testing.T) {
+	provider := &countingProvider{name: "only", values: map[string]string{"A": "value"}}
+	chain := NewChain(provider)
+
+	for range 3 {
+		chain.Lookup("A")
+	}
+	assert.Equal(t, 1, provider.calls, "A resolved secret should be fetched only once")
+
+	for range 3 {
+		chain.Lookup("missing")
+	}
+	assert.Equal(t, 4, provider.calls, "A missing secret should be looked up again")
+}
+
+func TestChain_RetriesAfterProviderFailure(t *testing.T) {
+	provider := &countingProvider{name: "flaky", values: map[string]string{"A": "value"}, err: errors.New("unavailable")}
+	chain := NewChain(provider)
+
+	_, found := chain.Lookup("A")
+	assert.False(t, found)
+
+	provider.err = nil
+	value, found := chain.Lookup("A")
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+}
+
+func TestChain_ContinuesPastFailingProvider(t *testing.T) {
+	failing := &countingProvider{name: "failing", err: errors.New("backend unavailable")}
+	working := &countingProvider{name: "working", values: map[string]string{"A": "value"}}
+
+	value, err := NewChain(failing, working).RetrieveSecret("A")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+}
+
+func TestChain_RetrieveSecretNotFound(t *testing.T) {
+	_, err := NewChain().RetrieveSecret("A")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestEnvironmentProvider(t *testing.T) {
+	t.Setenv("SATCHEL_TEST_SECRET", "from the environment")
+	t.Setenv("SATCHEL_TEST_EMPTY_SECRET", "")
+
+	value, err := EnvironmentProvider{}.RetrieveSecret("SATCHEL_TEST_SECRET")
+	assert.NoError(t, err)
+	assert.Equal(t, "from the environment", value)
+
+	_, err = EnvironmentProvider{}.RetrieveSecret("SATCHEL_TEST_EMPTY_SECRET")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestFileProvider(t *testing.T) {
+	directory := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "SATCHEL_DB_PASSWORD"), []byte("mounted\n"), 0o600))
+	provider := FileProvider{Directory: directory}
+
+	value, err := provider.RetrieveSecret("SATCHEL_DB_PASSWORD")
+	assert.NoError(t, err)
+	assert.Equal(t, "mounted", value, "Trailing newlines should be trimmed")
+
+	_, err = provider.RetrieveSecret("SATCHEL_DB_USER")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	_, err = provider.RetrieveSecret("../SATCHEL_DB_PASSWORD")
+	assert.ErrorIs(t, err, ErrSecretNotFound, "Names must not escape the secrets directory")
+}
+
+func TestGCPProvider_WithoutProject(t *testing.T) {
+	t.Setenv("PROJECT_ID", "")
+
+	_, err := GCPProvider{}.RetrieveSecret("SATCHEL_DB_PASSWORD")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestChainFromEnvironment(t *testing.T) {
+	t.Setenv("SATCHEL_SECRET_PROVIDERS", "file, env")
+	chain, err := ChainFromEnvironment()
+	assert.NoError(t, err)
+	assert.Equal(t, "file,env", chain.Name())
+
+	t.Setenv("SATCHEL_SECRET_PROVIDERS", "")
+	chain, err = ChainFromEnvironment()
+	assert.NoError(t, err)
+	assert.Equal(t, "gcp,env", chain.Name())
+
+	t.Setenv("SATCHEL_SECRET_PROVIDERS", "env,vault,carrier-pigeon")
+	t.Setenv("VAULT_ADDR", "")
+	chain, err = ChainFromEnvironment()
+	assert.ErrorContains(t, err, "VAULT_ADDR and VAULT_TOKEN must be set")
+	assert.ErrorContains(t, err, `unknown secret provider "carrier-pigeon"`)
+	assert.Equal(t, "env", chain.Name())
+}
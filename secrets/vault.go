@@ -0,0 +1,123 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultProvider reads secrets from a single HashiCorp Vault KV version 2
+// secret, where each key of the secret is the name of a Satchel secret.
+// The secret is read once and its keys are served from memory after that.
+type VaultProvider struct {
+	Address    string
+	Token      string
+	Namespace  string
+	Mount      string
+	SecretPath string
+	Client     *http.Client
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// VaultProviderFromEnvironment configures a VaultProvider from
+// VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE, the same variables the
+// vault CLI uses, along with SATCHEL_VAULT_MOUNT (default "secret") and
+// SATCHEL_VAULT_PATH (default "satchel").
+func VaultProviderFromEnvironment() (*VaultProvider, error) {
+	address := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if address == "" || token == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN must be set to use the vault secret provider")
+	}
+	mount := os.Getenv("SATCHEL_VAULT_MOUNT")
+	if mount == "" {
+		mount = "secret"
+	}
+	secretPath := os.Getenv("SATCHEL_VAULT_PATH")
+	if secretPath == "" {
+		secretPath = "satchel"
+	}
+	return &VaultProvider{
+		Address:    address,
+		Token:      token,
+		Namespace:  os.Getenv("VAULT_NAMESPACE"),
+		Mount:      mount,
+		SecretPath: secretPath,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
+
+func (p *VaultProvider) RetrieveSecret(name string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.values == nil {
+		values, err := p.readSecret()
+		if err != nil {
+			return "", err
+		}
+		p.values = values
+	}
+
+	value, found := p.values[name]
+	if !found {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]any `json:"data"`
+	} `json:"data"`
+}
+
+func (p *VaultProvider) readSecret() (map[string]string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(p.Address, "/"), strings.Trim(p.Mount, "/"), strings.Trim(p.SecretPath, "/"))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return map[string]string{}, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned %s reading %s", res.Status, url)
+	}
+
+	var body vaultKVResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding vault response: %w", err)
+	}
+	values := make(map[string]string, len(body.Data.Data))
+	for key, value := range body.Data.Data {
+		values[key] = fmt.Sprint(value)
+	}
+	return values, nil
+}
@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultProvider(t *testing.T) {
+	requests := 0
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/v1/secret/data/satchel", r.URL.Path)
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"SATCHEL_DB_PASSWORD":"from vault","SATCHEL_DB_PORT":5432},"metadata":{"version":1}}}`))
+	}))
+	defer vault.Close()
+
+	provider := &VaultProvider{Address: vault.URL, Token: "root", Mount: "secret", SecretPath: "satchel"}
+
+	value, err := provider.RetrieveSecret("SATCHEL_DB_PASSWORD")
+	assert.NoError(t, err)
+	assert.Equal(t, "from vault", value)
+
+	value, err = provider.RetrieveSecret("SATCHEL_DB_PORT")
+	assert.NoError(t, err)
+	assert.Equal(t, "5432", value)
+
+	_, err = provider.RetrieveSecret("SATCHEL_DB_USER")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	assert.Equal(t, 1, requests, "The vault secret should only be read once")
+}
+
+func TestVaultProvider_Forbidden(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer vault.Close()
+
+	provider := &VaultProvider{Address: vault.URL, Token: "wrong", Mount: "secret", SecretPath: "satchel"}
+
+	_, err := provider.RetrieveSecret("SATCHEL_DB_PASSWORD")
+	assert.ErrorContains(t, err, "403")
+}
+
+// TestVaultProvider_DevServer runs against a real Vault started with
+// `vault server -dev` when VAULT_ADDR and VAULT_TOKEN are set.
+func TestVaultProvider_DevServer(t *testing.T) {
+	if os.Getenv("VAULT_ADDR") == "" || os.Getenv("VAULT_TOKEN") == "" {
+		t.Skip("VAULT_ADDR and VAULT_TOKEN are not set")
+	}
+	t.Setenv("SATCHEL_VAULT_PATH", "satchel-test")
+	provider, err := VaultProviderFromEnvironment()
+	assert.NoError(t, err)
+
+	body := bytes.NewBufferString(`{"data":{"SATCHEL_TEST_SECRET":"dev server value"}}`)
+	req, _ := http.NewRequest(http.MethodPost, provider.Address+"/v1/secret/data/satchel-test", body)
+	req.Header.Set("X-Vault-Token", provider.Token)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	value, err := provider.RetrieveSecret("SATCHEL_TEST_SECRET")
+	assert.NoError(t, err)
+	assert.Equal(t, "dev server value", value)
+}
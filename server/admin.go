@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/importer"
+)
+
+// maxImportFileSize limits HR export uploads. Exports for the whole
+// company are well under a megabyte, so this leaves plenty of room.
+const maxImportFileSize = 2 << 20
+
+func importPageHandler(c *gin.Context) {
+	renderTemplate(c, "import", gin.H{})
+}
+
+// importUploadHandler imports an uploaded HR export. The "mode" form
+// value is "preview" for a dry run and "import" to save the changes.
+func importUploadHandler(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		renderTemplateWithStatus(c, "import-report", gin.H{"Error": "Choose a CSV or XLSX file to import."}, http.StatusBadRequest)
+		return
+	}
+	format := importer.FormatFromFilename(fileHeader.Filename)
+	if format == "" {
+		renderTemplateWithStatus(c, "import-report", gin.H{"Error": "Only CSV, XLSX and JSON files can be imported."}, http.StatusBadRequest)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		renderTemplateWithStatus(c, "import-report", gin.H{"Error": err.Error()}, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	records, err := importer.Parse(format, file)
+	if err != nil {
+		renderTemplateWithStatus(c, "import-report", gin.H{"Error": "The file could not be read: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	report, err := importer.Apply(c.Request.Context(), records, c.PostForm("mode") != "import")
+	if err != nil {
+		renderTemplateWithStatus(c, "import-report", gin.H{"Error": "The import failed: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	renderTemplate(c, "import-report", gin.H{
+		"Report":    report,
+		"Filename":  fileHeader.Filename,
+		"Created":   report.Count(importer.ActionCreate),
+		"Updated":   report.Count(importer.ActionUpdate),
+		"Unchanged": report.Count(importer.ActionUnchanged),
+		"Conflicts": report.Count(importer.ActionConflict),
+	})
+}
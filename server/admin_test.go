@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func saveEmployee(t *testing.T, employee *model.Employee) {
+	t.Cleanup(func() {
+		repository.DeleteEmployee(context.Background(), employee.Email)
+	})
+	assert.NoError(t, repository.SaveEmployee(t.Context(), employee))
+}
+
+func uploadRequest(t *testing.T, filename string, contents string, mode string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	assert.NoError(t, err)
+	part.Write([]byte(contents))
+	assert.NoError(t, writer.WriteField("mode", mode))
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/import", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("HX-Request", "true")
+	return req
+}
+
+func TestAdminImport_RequiresAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "regular@objectcomputing.com", Name: "Regular"})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/import", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/admin/import", nil), "regular@objectcomputing.com"))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestAdminImport_PreviewThenImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "admin@objectcomputing.com", Name: "Admin", Admin: true})
+	t.Cleanup(func() {
+		repository.DeleteEmployee(context.Background(), "new.hire@objectcomputing.com")
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/admin/import", nil), "admin@objectcomputing.com"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Import Employees")
+	assert.Contains(t, recorder.Body.String(), `href="/admin/import"`, "Admins should see the import link")
+
+	csv := "Email,Name,Position\nnew.hire@objectcomputing.com,New Hire,Engineer\n,Missing Email,\n"
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, uploadRequest(t, "hr.csv", csv, "preview"), "admin@objectcomputing.com"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "nothing has been saved")
+	assert.Contains(t, recorder.Body.String(), "1 created, 0 updated, 0 unchanged, 1 conflicts")
+	assert.Contains(t, recorder.Body.String(), "email is missing")
+	_, err := repository.GetEmployeeByEmail(t.Context(), "new.hire@objectcomputing.com")
+	assert.Error(t, err, "A preview should not save anything")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, uploadRequest(t, "hr.csv", csv, "import"), "admin@objectcomputing.com"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Imported hr.csv")
+	hire, err := repository.GetEmployeeByEmail(t.Context(), "new.hire@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "Engineer", hire.Position)
+}
+
+func TestAdminImport_RejectsUnknownFileTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "admin@objectcomputing.com", Name: "Admin", Admin: true})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, uploadRequest(t, "hr.xls", "binary", "preview"), "admin@objectcomputing.com"))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Only CSV, XLSX and JSON files can be imported.")
+}
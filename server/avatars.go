@@ -0,0 +1,58 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/avatars"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/photos"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// avatarCacheControl lets browsers reuse an avatar for an hour. Unlike
+// uploaded photos, an avatar's address stays the same when it changes.
+const avatarCacheControl = "private, max-age=3600"
+
+// avatarHandler serves the cached copy of an employee's image from
+// another site, or an avatar of their initials when there is none.
+func avatarHandler(c *gin.Context) {
+	size, found := photos.SizeNamed(c.Param("size"))
+	id, err := strconv.ParseUint(c.Param("employeeId"), 10, 64)
+	if !found || err != nil {
+		c.String(http.StatusNotFound, "Avatar not found")
+		return
+	}
+	employee, err := repository.GetEmployeeByID(c.Request.Context(), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "Avatar not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+
+	reader, err := avatars.Open(c.Request.Context(), employee, size)
+	if err == nil {
+		defer reader.Close()
+		c.DataFromReader(http.StatusOK, -1, photos.ContentType, reader, map[string]string{
+			"Cache-Control":          avatarCacheControl,
+			"X-Content-Type-Options": "nosniff",
+		})
+		return
+	}
+	if !errors.Is(err, avatars.ErrNoAvatar) {
+		logging.FromContext(c.Request.Context()).WarnContext(c.Request.Context(), "failed to open avatar",
+			slog.Uint64("employeeId", id), slog.Any("error", err))
+	}
+	c.Header("Cache-Control", avatarCacheControl)
+	c.Header("X-Content-Type-Options", "nosniff")
+	// The SVG is only ever an image, so nothing within it may run.
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	c.Data(http.StatusOK, avatars.InitialsContentType, avatars.Initials(employee))
+}
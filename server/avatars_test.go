@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/avatars"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/photos"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// avatarSite stands in for the identity provider's image host, counting
+// the requests it receives.
+func avatarSite(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	avatar := samplePNG(t, 120, 120)
+	site := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/broken" {
+			http.Error(w, "gone", http.StatusGone)
+			return
+		}
+		w.Write(avatar)
+	}))
+	t.Cleanup(site.Close)
+	photos.ConfigureStorageForTest(t)
+	avatars.ConfigureForTest(t, []string{"127.0.0.1"}, site.Client())
+	return site, &requests
+}
+
+func getAvatar(t *testing.T, router *gin.Engine, employee *model.Employee, size string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, employee.PhotoURL(size), nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, req, "viewer@objectcomputing.com"))
+	return recorder
+}
+
+func reloadEmployee(t *testing.T, email string) *model.Employee {
+	employee, err := repository.GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	return employee
+}
+
+func TestAvatar_CachesImagesFromOtherSites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	site, requests := avatarSite(t)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "viewer@objectcomputing.com", Name: "Viewer"})
+	employee := &model.Employee{Email: "avatar@objectcomputing.com", Name: "Ava Tar", ImageName: site.URL + "/avatar.png"}
+	saveEmployee(t, employee)
+	assert.Equal(t, fmt.Sprintf("/avatars/%d/thumb", employee.ID), employee.PhotoURL(photos.Thumbnail.Name))
+
+	recorder := getAvatar(t, router, employee, photos.Thumbnail.Name)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "image/jpeg", recorder.Header().Get("Content-Type"))
+	thumbnail, _, err := image.DecodeConfig(recorder.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, photos.Thumbnail.Pixels, thumbnail.Width)
+
+	assert.Equal(t, http.StatusOK, getAvatar(t, router, employee, photos.Full.Name).Code)
+	assert.Equal(t, int32(1), requests.Load(), "The image should only be fetched once")
+	cached := reloadEmployee(t, employee.Email)
+	assert.NotEmpty(t, cached.Avatar)
+	assert.Equal(t, site.URL+"/avatar.png", cached.AvatarSource)
+
+	// A new URL is fetched again, but when it is broken the copy of the
+	// previous image is still shown.
+	cached.ImageName = site.URL + "/broken"
+	assert.NoError(t, repository.SaveEmployee(t.Context(), cached))
+	recorder = getAvatar(t, router, cached, photos.Thumbnail.Name)
+	assert.Equal(t, "image/jpeg", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, int32(2), requests.Load())
+	getAvatar(t, router, cached, photos.Thumbnail.Name)
+	assert.Equal(t, int32(2), requests.Load(), "A broken image should not be fetched on every request")
+}
+
+func TestAvatar_KeepsChangesSavedWhileFetching(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	email := "slow.avatar@objectcomputing.com"
+	avatar := samplePNG(t, 120, 120)
+	site := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The employee edits their bio while the avatar is downloading.
+		assert.NoError(t, repository.SaveBio(context.Background(), email, "Edited while fetching"))
+		w.Write(avatar)
+	}))
+	t.Cleanup(site.Close)
+	photos.ConfigureStorageForTest(t)
+	avatars.ConfigureForTest(t, []string{"127.0.0.1"}, site.Client())
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "viewer@objectcomputing.com", Name: "Viewer"})
+	employee := &model.Employee{Email: email, Name: "Slow Avatar", ImageName: site.URL + "/avatar.png"}
+	saveEmployee(t, employee)
+
+	assert.Equal(t, http.StatusOK, getAvatar(t, router, employee, photos.Thumbnail.Name).Code)
+
+	saved := reloadEmployee(t, email)
+	assert.NotEmpty(t, saved.Avatar)
+	assert.Equal(t, "Edited while fetching", saved.Bio, "Saving the avatar should not overwrite other changes")
+}
+
+func TestAvatar_FallsBackToInitials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, requests := avatarSite(t)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "viewer@objectcomputing.com", Name: "Viewer"})
+	employee := &model.Employee{Email: "initials@objectcomputing.com", FirstName: "Ina", LastName: "Itials"}
+	saveEmployee(t, employee)
+
+	recorder := getAvatar(t, router, employee, photos.Full.Name)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, avatars.InitialsContentType, recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), ">II<")
+	assert.Contains(t, recorder.Header().Get("Content-Security-Policy"), "default-src 'none'")
+
+	employee.ImageName = "https://tracker.example.com/pixel.png"
+	assert.NoError(t, repository.SaveEmployee(t.Context(), employee))
+	recorder = getAvatar(t, router, employee, photos.Full.Name)
+	assert.Equal(t, avatars.InitialsContentType, recorder.Header().Get("Content-Type"), "Images on other hosts should not be fetched")
+	assert.Equal(t, int32(0), requests.Load())
+}
+
+func TestAvatar_DirectoryNeverLinksToOtherSites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	avatarSite(t)
+	router := createRouter()
+	employee := &model.Employee{Email: "viewer@objectcomputing.com", Name: "Viewer", ImageName: "https://lh3.googleusercontent.com/a/viewer"}
+	saveEmployee(t, employee)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/", nil), employee.Email))
+
+	assert.Contains(t, recorder.Body.String(), employee.PhotoURL(photos.Thumbnail.Name))
+	assert.NotContains(t, recorder.Body.String(), "googleusercontent.com")
+}
+
+func TestAvatar_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "viewer@objectcomputing.com", Name: "Viewer"})
+
+	for _, path := range []string{"/avatars/999999/thumb", "/avatars/1/huge", "/avatars/me/thumb"} {
+		req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, path, nil), "viewer@objectcomputing.com")
+		assert.Equal(t, http.StatusNotFound, responseCode(router, req), path)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBio_RenderedAsMarkdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "markdown@objectcomputing.com", Name: "Markdown", Bio: "I *like* <script>alert(1)</script>"})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/markdown@objectcomputing.com", nil), "markdown@objectcomputing.com"))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "I <em>like</em>")
+	assert.NotContains(t, recorder.Body.String(), "<script>alert(1)</script>")
+}
+
+func TestBio_Preview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "previewer@objectcomputing.com", Name: "Previewer", Bio: "Saved"})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/bio/preview", "previewer@objectcomputing.com", url.Values{"biotext": {"- **one**\n- [two](javascript:alert(1))"}}))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `id="bio-preview"`)
+	assert.Contains(t, recorder.Body.String(), "<li><strong>one</strong></li>")
+	assert.NotContains(t, recorder.Body.String(), "javascript:")
+
+	employee, err := repository.GetEmployeeByEmail(t.Context(), "previewer@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "Saved", employee.Bio, "Previewing should not save the bio")
+}
+
+func TestBio_TooLong(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "verbose@objectcomputing.com", Name: "Verbose", Bio: "Saved"})
+	tooLong := strings.Repeat("a", model.MaxBioLength+1)
+
+	for _, path := range []string{"/bio/preview", "/bio"} {
+		assert.Equal(t, http.StatusBadRequest, responseCode(router, htmxRequest(t, http.MethodPost, path, "verbose@objectcomputing.com", url.Values{"biotext": {tooLong}})), path)
+	}
+
+	employee, err := repository.GetEmployeeByEmail(t.Context(), "verbose@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "Saved", employee.Bio)
+
+	lines := strings.Repeat("a\r\n", model.MaxBioLength/2)
+	assert.NoError(t, repository.SaveBio(t.Context(), "verbose@objectcomputing.com", lines), "Line breaks should count as one character")
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/completeness"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/mail"
+	"github.com/jeffscottbrown/satchel/repository"
+)
+
+func completenessPageHandler(c *gin.Context) {
+	renderCompleteness(c, "completeness", http.StatusOK, gin.H{})
+}
+
+// remindHandler emails the employees named by the "email" form values
+// a reminder to complete their profile. Employees who were reminded
+// recently, or whose profiles are now complete enough, are skipped.
+func remindHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	now := time.Now()
+	sent, skipped, failed := 0, 0, 0
+	for _, email := range c.PostFormArray("email") {
+		employee, err := repository.GetEmployeeByEmail(ctx, email)
+		if err != nil || !employee.IsActive() {
+			skipped++
+			continue
+		}
+		reminded, err := completeness.Remind(ctx, employee, baseURL(c)+"/employee/"+url.PathEscape(employee.Email), now)
+		switch {
+		case err != nil:
+			logging.FromContext(ctx).ErrorContext(ctx, "Error sending reminder", "email", email, "error", err)
+			failed++
+		case reminded:
+			sent++
+		default:
+			skipped++
+		}
+	}
+	data := gin.H{"Sent": sent, "Skipped": skipped}
+	if failed > 0 {
+		data["Error"] = "Some reminders could not be sent. See the log for details."
+		renderCompleteness(c, "completeness-list", http.StatusInternalServerError, data)
+		return
+	}
+	renderCompleteness(c, "completeness-list", http.StatusOK, data)
+}
+
+// renderCompleteness renders a completeness template with the report of
+// incomplete profiles added to data.
+func renderCompleteness(c *gin.Context, templateName string, status int, data gin.H) {
+	reports, err := completeness.Incomplete(c.Request.Context(), time.Now())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employees: %v", err)
+		return
+	}
+	data["Reports"] = reports
+	data["Threshold"] = completeness.Threshold()
+	data["MailEnabled"] = mail.Enabled()
+	renderTemplateWithStatus(c, templateName, data, status)
+}
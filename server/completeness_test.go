@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/mail"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteness_ShownOnOwnProfile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "partial@objectcomputing.com", Name: "Partial", Position: "Engineer"})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/partial@objectcomputing.com", nil), "partial@objectcomputing.com"))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Your Profile Is 25% Complete")
+	assert.Contains(t, recorder.Body.String(), "<li>Write a short bio.</li>")
+}
+
+func TestCompleteness_AdminsRemindIncompleteProfiles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	outbox := mail.ConfigureForTest(t)
+	saveEmployee(t, &model.Employee{Email: "admin@objectcomputing.com", Name: "Admin", Admin: true, Bio: "Runs things"})
+	saveEmployee(t, &model.Employee{Email: "regular@objectcomputing.com", Name: "Regular"})
+
+	assert.Equal(t, http.StatusForbidden, responseCode(router, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/admin/completeness", nil), "regular@objectcomputing.com")))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/admin/completeness", nil), "admin@objectcomputing.com"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `value="regular@objectcomputing.com"`)
+
+	form := url.Values{"email": {"regular@objectcomputing.com", "nobody@objectcomputing.com"}}
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, formRequest(http.MethodPost, "/admin/completeness/remind", form), "admin@objectcomputing.com"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Sent 1 reminder.")
+	messages := outbox.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "regular@objectcomputing.com", messages[0].To)
+	assert.Contains(t, messages[0].Body, "/employee/regular@objectcomputing.com")
+
+	router.ServeHTTP(httptest.NewRecorder(), auth.AuthenticateRequestForTest(t, formRequest(http.MethodPost, "/admin/completeness/remind", form), "admin@objectcomputing.com"))
+	assert.Len(t, outbox.Messages(), 1, "Employees should not be reminded twice in a row")
+}
@@ -0,0 +1,96 @@
+//go:build !production
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func developmentLoginRequest(email string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/auth/dev/login", strings.NewReader(url.Values{"email": {email}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestDevelopmentLogin_CreatesProfileAndSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	t.Cleanup(func() {
+		repository.DeleteEmployee(context.Background(), "dev.user@objectcomputing.com")
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, developmentLoginRequest("Dev.User@objectcomputing.com"))
+
+	assert.Equal(t, http.StatusTemporaryRedirect, recorder.Code)
+	employee, err := repository.GetEmployeeByEmail(t.Context(), "dev.user@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "Dev User", employee.Name)
+
+	req := httptest.NewRequest(http.MethodGet, "/employee/dev.user@objectcomputing.com", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusFound, recorder.Code, "The development login should start a session")
+	assert.Equal(t, "/onboarding", recorder.Header().Get("Location"), "New employees should be onboarded first")
+}
+
+func TestDevelopmentLogin_RejectsOtherDomains(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, developmentLoginRequest("someone@example.com"))
+
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "/forbidden", recorder.Header().Get("Location"))
+	_, err := repository.GetEmployeeByEmail(t.Context(), "someone@example.com")
+	assert.Error(t, err)
+}
+
+func TestDevelopmentLogin_LookupErrorStartsNoSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	repository.ConfigureRepositoryForTest(t, &errorThrowingEmployeeRepository{})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, developmentLoginRequest("unlucky@objectcomputing.com"))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Empty(t, recorder.Result().Cookies(), "No session should be started when the employee cannot be looked up")
+}
+
+func TestDevelopmentLogin_OnboardsProvisionedProfilesAtFirstLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	email := "provisioned@objectcomputing.com"
+	saveEmployee(t, &model.Employee{Email: email, Name: "Provisioned"})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, developmentLoginRequest(email))
+	assert.Equal(t, "/onboarding", recorder.Header().Get("Location"), "Profiles provisioned before the first login should be onboarded")
+	employee, err := repository.GetEmployeeByEmail(t.Context(), email)
+	assert.NoError(t, err)
+	assert.True(t, employee.NeedsOnboarding())
+	assert.NotNil(t, employee.OnboardingStartedAt)
+
+	for employee.Onboarding() {
+		employee.AdvanceOnboarding()
+	}
+	assert.NoError(t, repository.SaveEmployee(t.Context(), employee))
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, developmentLoginRequest(email))
+	assert.Equal(t, "/", recorder.Header().Get("Location"), "Onboarding should only start at the first login")
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/exporter"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// publicURL is the configured SATCHEL_PUBLIC_URL. When it is blank
+// absolute links are built from the request instead.
+var publicURL string
+
+func baseURL(c *gin.Context) string {
+	if publicURL != "" {
+		return publicURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := c.GetHeader("X-Forwarded-Proto"); forwarded == "http" || forwarded == "https" {
+		scheme = forwarded
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// exportHandler downloads the directory, or the part of it selected by
+// the q, position and manager query parameters, as CSV or JSON.
+func exportHandler(c *gin.Context) {
+	format := c.DefaultQuery("format", exporter.FormatCSV)
+	if format != exporter.FormatCSV && format != exporter.FormatJSON {
+		c.String(http.StatusBadRequest, "Unsupported export format %q", format)
+		return
+	}
+	employees, err := exporter.Employees(c.Request.Context(), exporter.Filter{
+		Query:        c.Query("q"),
+		Position:     c.Query("position"),
+		ManagerEmail: c.Query("manager"),
+	})
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employees: %v", err)
+		return
+	}
+	writeExport(c, format, "satchel-employees", func(buffer *bytes.Buffer) error {
+		return exporter.Write(c.Request.Context(), buffer, format, employees, baseURL(c))
+	})
+}
+
+// vCardHandler downloads a single employee as a contact card.
+func vCardHandler(c *gin.Context) {
+	employee, err := repository.GetEmployeeByEmail(c.Request.Context(), c.Param("employeeEmail"))
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !employee.IsActive()) {
+		c.String(http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	writeExport(c, exporter.FormatVCard, fileName(employee.Name, employee.Email), func(buffer *bytes.Buffer) error {
+		return exporter.WriteVCard(c.Request.Context(), buffer, employee, baseURL(c))
+	})
+}
+
+// writeExport renders the export into memory first so that a failure
+// can still be reported with an error status.
+func writeExport(c *gin.Context, format string, name string, write func(*bytes.Buffer) error) {
+	var buffer bytes.Buffer
+	if err := write(&buffer); err != nil {
+		logging.FromContext(c.Request.Context()).ErrorContext(c.Request.Context(), "failed to export employees", "error", err)
+		c.String(http.StatusInternalServerError, "Error exporting employees: %v", err)
+		return
+	}
+	contentType, extension := exporter.ContentType(format)
+	c.Header("Content-Disposition", `attachment; filename="`+name+extension+`"`)
+	c.Data(http.StatusOK, contentType, buffer.Bytes())
+}
+
+var unsafeFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName turns an employee's name into something safe to use as a
+// download file name, falling back to fallback when nothing is left.
+func fileName(name string, fallback string) string {
+	cleaned := strings.Trim(unsafeFileNameCharacters.ReplaceAllString(name, "-"), "-.")
+	if cleaned == "" {
+		cleaned = strings.Trim(unsafeFileNameCharacters.ReplaceAllString(fallback, "-"), "-.")
+	}
+	return cleaned
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport_RequiresAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+
+	for _, path := range []string{"/export", "/employee/someone@objectcomputing.com/vcard"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, path)
+	}
+}
+
+func TestExport_CSVAndJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "exported@objectcomputing.com", Name: "Exported Person", Position: "Cartographer"})
+	saveEmployee(t, &model.Employee{Email: "other@objectcomputing.com", Name: "Other Person", Position: "Engineer"})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/export?format=csv&position=cartographer", nil), "other@objectcomputing.com"))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="satchel-employees.csv"`, recorder.Header().Get("Content-Disposition"))
+	assert.Contains(t, recorder.Body.String(), "exported@objectcomputing.com,Exported Person")
+	assert.NotContains(t, recorder.Body.String(), "other@objectcomputing.com", "The filter should be applied")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/export?format=json&q=exported", nil), "other@objectcomputing.com"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"email": "exported@objectcomputing.com"`)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/export?format=xml", nil), "other@objectcomputing.com"))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestVCard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "henry@objectcomputing.com", Name: "Henry David Thoreau", FirstName: "Henry", LastName: "Thoreau", ImageName: "/static/images/henry.jpg"})
+
+	req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/henry@objectcomputing.com/vcard", nil), "henry@objectcomputing.com")
+	req.Host = "satchel.example.com"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/vcard; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="Henry-David-Thoreau.vcf"`, recorder.Header().Get("Content-Disposition"))
+	assert.Contains(t, recorder.Body.String(), "FN:Henry David Thoreau\r\n")
+	assert.Contains(t, recorder.Body.String(), "PHOTO;VALUE=URI:https://satchel.example.com/static/images/henry.jpg\r\n")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/nobody@objectcomputing.com/vcard", nil), "henry@objectcomputing.com"))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	deactivated := time.Now()
+	saveEmployee(t, &model.Employee{Email: "former@objectcomputing.com", Name: "Former Employee", DeactivatedAt: &deactivated})
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/former@objectcomputing.com/vcard", nil), "henry@objectcomputing.com"))
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "Deactivated employees should not be exported")
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "Henry-David-Thoreau", fileName("Henry David Thoreau", "henry@objectcomputing.com"))
+	assert.Equal(t, "henry-objectcomputing.com", fileName(`"../"`, "henry@objectcomputing.com"))
+}
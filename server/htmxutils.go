@@ -15,6 +15,14 @@ func renderTemplateWithStatus(c *gin.Context, templateName string, data gin.H, s
 	isHTMX := x != ""
 
 	data["IsAuthenticated"] = auth.IsAuthenticated(c.Request)
+	data["IsAdmin"] = auth.IsAdmin(c.Request)
+	data["DevelopmentLogin"] = auth.DevelopmentLoginEnabled
+	data["OnboardingDeferred"] = auth.OnboardingDeferred(c)
+	if impersonator := auth.Impersonator(c.Request); impersonator != "" {
+		data["Impersonator"] = impersonator
+		data["ImpersonatedUser"], _ = auth.AuthenticatedUser(c.Request)
+		data["ImpersonationAllowsChanges"] = auth.ImpersonationAllowsChanges(c.Request)
+	}
 
 	if isHTMX {
 		tmpl.ExecuteTemplate(c.Writer, templateName, data)
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+)
+
+var identityFieldLabels = map[string]string{
+	model.FieldName:      "Display Name",
+	model.FieldFirstName: "First Name",
+	model.FieldLastName:  "Last Name",
+}
+
+// identityFieldView describes one of the fields the identity provider
+// owns for the identity template.
+type identityFieldView struct {
+	Field      string
+	Label      string
+	Value      string
+	Provided   string
+	Overridden bool
+}
+
+// identityFields is available to templates so that every handler which
+// renders a profile shows which of its fields the employee overrode.
+func identityFields(employee *model.Employee) []identityFieldView {
+	views := make([]identityFieldView, 0, len(model.IdentityFields))
+	for _, field := range model.IdentityFields {
+		views = append(views, identityFieldView{
+			Field:      field,
+			Label:      identityFieldLabels[field],
+			Value:      *employee.IdentityField(field),
+			Provided:   employee.IdentityProfile.Value(field),
+			Overridden: employee.Overridden(field),
+		})
+	}
+	return views
+}
+
+// nameHandler saves the authenticated user's names. A name which differs
+// from the identity provider's is overridden and no longer refreshed
+// when they log in.
+func nameHandler(c *gin.Context) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	if !readIdentityFields(c, employee) {
+		c.String(http.StatusBadRequest, "Name cannot be empty")
+		return
+	}
+	saveAndRenderPerson(c, employee)
+}
+
+// readIdentityFields sets employee's names from the form, overriding
+// those which differ from the identity provider's. It reports false,
+// changing nothing, if the display name is empty.
+func readIdentityFields(c *gin.Context, employee *model.Employee) bool {
+	if strings.TrimSpace(c.PostForm(model.FieldName)) == "" {
+		return false
+	}
+	for _, field := range model.IdentityFields {
+		value := strings.TrimSpace(c.PostForm(field))
+		current := employee.IdentityField(field)
+		if value == *current {
+			continue
+		}
+		*current = value
+		employee.SetOverridden(field, value != employee.IdentityProfile.Value(field))
+	}
+	return true
+}
+
+// resetIdentityFieldHandler stops overriding a field, restoring the
+// value the identity provider gave when the user last logged in.
+func resetIdentityFieldHandler(c *gin.Context) {
+	field := c.Param("field")
+	if !slices.Contains(model.IdentityFields, field) {
+		c.String(http.StatusNotFound, "Unknown field")
+		return
+	}
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	employee.SetOverridden(field, false)
+	if provided := employee.IdentityProfile.Value(field); provided != "" {
+		*employee.IdentityField(field) = provided
+	}
+	saveAndRenderPerson(c, employee)
+}
+
+func saveAndRenderPerson(c *gin.Context, employee *model.Employee) {
+	if err := repository.SaveEmployee(c.Request.Context(), employee); err != nil {
+		c.String(http.StatusInternalServerError, "Error saving employee: %v", err)
+		return
+	}
+	renderTemplate(c, "person", gin.H{
+		"Employee":   employee,
+		"IsEditable": true})
+}
@@ -0,0 +1,76 @@
+//go:build !production
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func nameRequest(t *testing.T, email string, values url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/name", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	return auth.AuthenticateRequestForTest(t, req, email)
+}
+
+func TestIdentitySync_KeepsOverriddenFieldsAcrossLogins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	const email = "sync.user@objectcomputing.com"
+	t.Cleanup(func() {
+		repository.DeleteEmployee(context.Background(), email)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), developmentLoginRequest(email))
+	finishOnboarding(t, email)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, nameRequest(t, email, url.Values{
+		model.FieldName:      {"Syncy"},
+		model.FieldFirstName: {"Sync"},
+		model.FieldLastName:  {"User"},
+	}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Edited by you")
+	assert.Contains(t, recorder.Body.String(), "Your account says &ldquo;Sync User&rdquo;")
+
+	employee := reloadEmployee(t, email)
+	assert.True(t, employee.Overridden(model.FieldName))
+	assert.False(t, employee.Overridden(model.FieldFirstName), "Unchanged fields should stay synced")
+	employee.LastName = "Renamed Elsewhere"
+	assert.NoError(t, repository.SaveEmployee(t.Context(), employee))
+
+	router.ServeHTTP(httptest.NewRecorder(), developmentLoginRequest(email))
+
+	employee = reloadEmployee(t, email)
+	assert.Equal(t, "Syncy", employee.Name, "Logging in should not overwrite an edited field")
+	assert.Equal(t, "User", employee.LastName, "Logging in should refresh synced fields")
+
+	req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodPost, "/name/reset/name", nil), email)
+	assert.Equal(t, http.StatusOK, responseCode(router, req))
+	employee = reloadEmployee(t, email)
+	assert.Equal(t, "Sync User", employee.Name)
+	assert.False(t, employee.Overridden(model.FieldName))
+}
+
+func TestIdentitySync_RejectsInvalidEdits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "named@objectcomputing.com", Name: "Named"})
+
+	assert.Equal(t, http.StatusBadRequest, responseCode(router, nameRequest(t, "named@objectcomputing.com", url.Values{model.FieldName: {"  "}})))
+
+	req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodPost, "/name/reset/bio", nil), "named@objectcomputing.com")
+	assert.Equal(t, http.StatusNotFound, responseCode(router, req))
+	assert.Equal(t, "Named", reloadEmployee(t, "named@objectcomputing.com").Name)
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func formRequest(method string, path string, form url.Values) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// htmxRequest is a form request htmx would send on behalf of the signed
+// in employee with email.
+func htmxRequest(t *testing.T, method string, path string, email string, form url.Values) *http.Request {
+	req := formRequest(method, path, form)
+	req.Header.Set("HX-Request", "true")
+	return auth.AuthenticateRequestForTest(t, req, email)
+}
+
+// withSessionFrom adds the cookies set in recorder to req, as a browser
+// would for its next request.
+func withSessionFrom(req *http.Request, recorder *httptest.ResponseRecorder) *http.Request {
+	for _, cookie := range recorder.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+// startImpersonating logs in as admin, impersonates email and returns
+// the response holding the impersonation session.
+func startImpersonating(t *testing.T, router *gin.Engine, admin string, email string, allowChanges bool) *httptest.ResponseRecorder {
+	form := url.Values{"email": {email}}
+	if allowChanges {
+		form.Set("allow-changes", "on")
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, formRequest(http.MethodPost, "/auth/impersonation/start", form), admin))
+	return recorder
+}
+
+func TestImpersonation_RequiresAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "regular@objectcomputing.com", Name: "Regular"})
+	saveEmployee(t, &model.Employee{Email: "target@objectcomputing.com", Name: "Target"})
+
+	recorder := startImpersonating(t, router, "regular@objectcomputing.com", "target@objectcomputing.com", false)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestImpersonation_ShowsBannerAndBlocksChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "admin@objectcomputing.com", Name: "Admin", Admin: true})
+	saveEmployee(t, &model.Employee{Email: "target@objectcomputing.com", Name: "Target", Bio: "Original"})
+
+	session := startImpersonating(t, router, "admin@objectcomputing.com", "target@objectcomputing.com", false)
+	assert.Equal(t, http.StatusSeeOther, session.Code)
+	assert.Equal(t, "/employee/target@objectcomputing.com", session.Header().Get("Location"))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, withSessionFrom(httptest.NewRequest(http.MethodGet, "/employee/target@objectcomputing.com", nil), session))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "You are viewing Satchel as <strong>target@objectcomputing.com</strong>")
+	assert.Contains(t, recorder.Body.String(), "The Team Wants To Know You", "The admin should see the page as its owner does")
+	assert.NotContains(t, recorder.Body.String(), `href="/admin/import"`, "The admin should not see pages the employee cannot")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, withSessionFrom(formRequest(http.MethodPost, "/bio", url.Values{"biotext": {"Changed"}}), session))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	target, _ := repository.GetEmployeeByEmail(t.Context(), "target@objectcomputing.com")
+	assert.Equal(t, "Original", target.Bio)
+
+	stopped := httptest.NewRecorder()
+	router.ServeHTTP(stopped, withSessionFrom(httptest.NewRequest(http.MethodPost, "/auth/impersonation/stop", nil), session))
+	assert.Equal(t, http.StatusSeeOther, stopped.Code)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, withSessionFrom(httptest.NewRequest(http.MethodGet, "/employee/target@objectcomputing.com", nil), stopped))
+	assert.NotContains(t, recorder.Body.String(), "You are viewing Satchel as")
+	assert.Contains(t, recorder.Body.String(), "View As Target", "The admin should be themselves again")
+}
+
+func TestImpersonation_AllowChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "admin@objectcomputing.com", Name: "Admin", Admin: true})
+	saveEmployee(t, &model.Employee{Email: "target@objectcomputing.com", Name: "Target", Bio: "Original"})
+
+	session := startImpersonating(t, router, "admin@objectcomputing.com", "target@objectcomputing.com", true)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, withSessionFrom(formRequest(http.MethodPost, "/bio", url.Values{"biotext": {"Fixed by support"}}), session))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	target, _ := repository.GetEmployeeByEmail(t.Context(), "target@objectcomputing.com")
+	assert.Equal(t, "Fixed by support", target.Bio)
+}
+
+func TestImpersonation_CannotManageTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "admin@objectcomputing.com", Name: "Admin", Admin: true})
+	saveEmployee(t, &model.Employee{Email: "target@objectcomputing.com", Name: "Target"})
+
+	session := startImpersonating(t, router, "admin@objectcomputing.com", "target@objectcomputing.com", true)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, withSessionFrom(formRequest(http.MethodPost, "/tokens", url.Values{"name": {"Sneaky"}, "scope": {model.TokenScopeReadWrite}}), session))
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestImpersonation_EndsWhenAdminRightsAreRevoked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	configureAuth(t, func(settings *config.Auth) { settings.StatusCacheTTL = 0 })
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "admin@objectcomputing.com", Name: "Admin", Admin: true, Bio: "Admin's own"})
+	saveEmployee(t, &model.Employee{Email: "target@objectcomputing.com", Name: "Target", Bio: "Original"})
+	session := startImpersonating(t, router, "admin@objectcomputing.com", "target@objectcomputing.com", true)
+
+	assert.NoError(t, repository.SetAdmin(t.Context(), "admin@objectcomputing.com", false))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, withSessionFrom(httptest.NewRequest(http.MethodGet, "/employee/target@objectcomputing.com", nil), session))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "You are viewing Satchel as", "A former admin should no longer impersonate anyone")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, withSessionFrom(formRequest(http.MethodPost, "/bio", url.Values{"biotext": {"Changed"}}), session))
+	target, _ := repository.GetEmployeeByEmail(t.Context(), "target@objectcomputing.com")
+	assert.Equal(t, "Original", target.Bio, "A former admin should not keep changing the employee's profile")
+	former, _ := repository.GetEmployeeByEmail(t.Context(), "admin@objectcomputing.com")
+	assert.Equal(t, "Changed", former.Bio, "The request should be served as the former admin themselves")
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+)
+
+var onboardingStepLabels = map[string]string{
+	model.OnboardingName:     "Name",
+	model.OnboardingPosition: "Position",
+	model.OnboardingPhoto:    "Photo",
+	model.OnboardingBio:      "Bio",
+	model.OnboardingPrompts:  "Reflections",
+}
+
+// onboardingStepView describes one of the onboarding steps for the
+// progress shown above each step.
+type onboardingStepView struct {
+	Number  int
+	Label   string
+	Done    bool
+	Current bool
+}
+
+// onboardingHandler shows the authenticated user the onboarding step
+// they have reached. Visiting it after choosing to finish later resumes
+// onboarding, so they are sent back to it until they finish.
+func onboardingHandler(c *gin.Context) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	if !employee.Onboarding() {
+		auth.Redirect(c, "/employee/"+employee.Email)
+		return
+	}
+	if employee.OnboardingSkipped {
+		employee.OnboardingSkipped = false
+		if err := repository.SaveEmployee(c.Request.Context(), employee); err != nil {
+			c.String(http.StatusInternalServerError, "Error saving employee: %v", err)
+			return
+		}
+	}
+	renderOnboarding(c, employee, http.StatusOK, "")
+}
+
+// onboardingStepHandler saves what the authenticated user entered for
+// their current onboarding step and moves them on to the next.
+func onboardingStepHandler(c *gin.Context) {
+	employee, ok := onboardingEmployee(c)
+	if !ok {
+		return
+	}
+	switch employee.OnboardingStep {
+	case model.OnboardingName:
+		if !readIdentityFields(c, employee) {
+			renderOnboarding(c, employee, http.StatusBadRequest, "Your name cannot be empty.")
+			return
+		}
+	case model.OnboardingPosition:
+		position := strings.TrimSpace(c.PostForm("position"))
+		if position == "" {
+			renderOnboarding(c, employee, http.StatusBadRequest, "Enter your position, or skip this step.")
+			return
+		}
+		employee.Position = position
+	case model.OnboardingPhoto:
+		if err := replacePhoto(c, employee); err != nil {
+			var rejection *photoRejection
+			if errors.As(err, &rejection) {
+				renderOnboarding(c, employee, rejection.status, rejection.message)
+				return
+			}
+			c.String(http.StatusInternalServerError, "Error saving photo: %v", err)
+			return
+		}
+	case model.OnboardingBio:
+		bio := c.PostForm("biotext")
+		if model.BioTooLong(bio) {
+			renderOnboarding(c, employee, http.StatusBadRequest, fmt.Sprintf("Your bio cannot be longer than %d characters.", model.MaxBioLength))
+			return
+		}
+		employee.Bio = bio
+	case model.OnboardingPrompts:
+		if err := answerOnboardingPrompts(c, employee); err != nil {
+			c.String(http.StatusInternalServerError, "Error answering reflections: %v", err)
+			return
+		}
+	}
+	advanceOnboarding(c, employee)
+}
+
+// skipOnboardingStepHandler moves the authenticated user past their
+// current onboarding step without changing their profile.
+func skipOnboardingStepHandler(c *gin.Context) {
+	employee, ok := onboardingEmployee(c)
+	if !ok {
+		return
+	}
+	advanceOnboarding(c, employee)
+}
+
+// finishOnboardingLaterHandler stops sending the authenticated user to
+// onboarding until they choose to resume it.
+func finishOnboardingLaterHandler(c *gin.Context) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	if employee.Onboarding() {
+		employee.OnboardingSkipped = true
+		if err := repository.SaveEmployee(c.Request.Context(), employee); err != nil {
+			c.String(http.StatusInternalServerError, "Error saving employee: %v", err)
+			return
+		}
+	}
+	auth.Redirect(c, "/")
+}
+
+// onboardingEmployee returns the authenticated user if the step in the
+// path is the one they are on. Otherwise, as happens when a form is
+// submitted twice, it shows them the step they are on instead.
+func onboardingEmployee(c *gin.Context) (*model.Employee, bool) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return nil, false
+	}
+	if !employee.Onboarding() {
+		auth.Redirect(c, "/employee/"+employee.Email)
+		return nil, false
+	}
+	if c.Param("step") != employee.OnboardingStep {
+		renderOnboarding(c, employee, http.StatusOK, "")
+		return nil, false
+	}
+	return employee, true
+}
+
+// answerOnboardingPrompts saves the answers to the employee's unanswered
+// prompts, which are posted as "reflection-<ID>" form values.
+func answerOnboardingPrompts(c *gin.Context, employee *model.Employee) error {
+	for i := range employee.Reflections {
+		reflection := &employee.Reflections[i]
+		if reflection.Value != "" {
+			continue
+		}
+		value := strings.TrimSpace(c.PostForm("reflection-" + strconv.FormatUint(uint64(reflection.ID), 10)))
+		if value == "" {
+			continue
+		}
+		if err := repository.AnswerReflection(c.Request.Context(), employee.Email, reflection.ID, value); err != nil {
+			return err
+		}
+		reflection.Value = value
+	}
+	return nil
+}
+
+// advanceOnboarding moves the employee to their next onboarding step and
+// shows it, or shows them their profile once they have finished.
+func advanceOnboarding(c *gin.Context, employee *model.Employee) {
+	employee.AdvanceOnboarding()
+	if err := repository.SaveEmployee(c.Request.Context(), employee); err != nil {
+		c.String(http.StatusInternalServerError, "Error saving employee: %v", err)
+		return
+	}
+	if !employee.Onboarding() {
+		auth.Redirect(c, "/employee/"+employee.Email)
+		return
+	}
+	renderOnboarding(c, employee, http.StatusOK, "")
+}
+
+func renderOnboarding(c *gin.Context, employee *model.Employee, status int, message string) {
+	current := slices.Index(model.OnboardingSteps, employee.OnboardingStep)
+	steps := make([]onboardingStepView, len(model.OnboardingSteps))
+	for i, step := range model.OnboardingSteps {
+		steps[i] = onboardingStepView{
+			Number:  i + 1,
+			Label:   onboardingStepLabels[step],
+			Done:    i < current,
+			Current: i == current,
+		}
+	}
+	renderTemplateWithStatus(c, "onboarding", gin.H{
+		"Employee":        employee,
+		"Step":            employee.OnboardingStep,
+		"Steps":           steps,
+		"LastStep":        current == len(steps)-1,
+		"OnboardingError": message,
+	}, status)
+}
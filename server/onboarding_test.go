@@ -0,0 +1,152 @@
+//go:build !production
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/photos"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// finishOnboarding marks the employee with email as having finished
+// onboarding, for tests of pages they would otherwise be kept from.
+func finishOnboarding(t *testing.T, email string) {
+	employee := reloadEmployee(t, email)
+	employee.OnboardingStep = ""
+	assert.NoError(t, repository.SaveEmployee(t.Context(), employee))
+}
+
+// newOnboardingEmployee logs in as email for the first time, which
+// creates their profile and starts onboarding.
+func newOnboardingEmployee(t *testing.T, router *gin.Engine, email string) {
+	t.Cleanup(func() {
+		repository.DeleteEmployee(context.Background(), email)
+	})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, developmentLoginRequest(email))
+	assert.Equal(t, auth.OnboardingPath, recorder.Header().Get("Location"), "New employees should be sent to onboarding")
+}
+
+func onboardingRequest(t *testing.T, email string, path string, values url.Values) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	createRouter().ServeHTTP(recorder, htmxRequest(t, http.MethodPost, path, email, values))
+	return recorder
+}
+
+func TestOnboarding_RedirectsUntilFinished(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	const email = "onboarding.redirect@objectcomputing.com"
+	newOnboardingEmployee(t, router, email)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/", nil), email))
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, auth.OnboardingPath, recorder.Header().Get("Location"))
+
+	req := httptest.NewRequest(http.MethodGet, "/employee/"+email, nil)
+	req.Header.Set("HX-Request", "true")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, req, email))
+	assert.Equal(t, auth.OnboardingPath, recorder.Header().Get("HX-Redirect"), "HTMX requests should load onboarding as a page")
+
+	recorder = onboardingRequest(t, email, "/position", url.Values{"position": {"Engineer"}})
+	assert.Empty(t, recorder.Header().Get("HX-Redirect"), "Only requests for pages should be sent to onboarding")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, auth.OnboardingPath, nil), email))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `hx-post="/onboarding/name"`)
+
+	finishOnboarding(t, email)
+	assert.Equal(t, http.StatusOK, responseCode(router, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/", nil), email)))
+}
+
+func TestOnboarding_EveryStep(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	photos.ConfigureStorageForTest(t)
+	router := createRouter()
+	const email = "onboarding.steps@objectcomputing.com"
+	newOnboardingEmployee(t, router, email)
+
+	recorder := onboardingRequest(t, email, "/onboarding/name", url.Values{model.FieldName: {"Onnie"}, model.FieldFirstName: {"Onboarding"}, model.FieldLastName: {"Steps"}})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `hx-post="/onboarding/position"`)
+
+	recorder = onboardingRequest(t, email, "/onboarding/position", url.Values{"position": {" "}})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Enter your position")
+	assert.Equal(t, http.StatusOK, onboardingRequest(t, email, "/onboarding/position", url.Values{"position": {"Engineer"}}).Code)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("photo", "me.png")
+	assert.NoError(t, err)
+	part.Write(samplePNG(t, 120, 80))
+	assert.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, "/onboarding/photo", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("HX-Request", "true")
+	assert.Equal(t, http.StatusOK, responseCode(router, auth.AuthenticateRequestForTest(t, req, email)))
+
+	assert.Equal(t, http.StatusOK, onboardingRequest(t, email, "/onboarding/bio", url.Values{"biotext": {"Likes onboarding"}}).Code)
+
+	employee := reloadEmployee(t, email)
+	assert.Equal(t, model.OnboardingPrompts, employee.OnboardingStep)
+	answers := url.Values{}
+	answers.Set("reflection-"+strconv.FormatUint(uint64(employee.Reflections[0].ID), 10), "An answer")
+	recorder = onboardingRequest(t, email, "/onboarding/prompts", answers)
+	assert.Equal(t, "/employee/"+email, recorder.Header().Get("HX-Redirect"), "Finishing should show the employee their profile")
+
+	employee = reloadEmployee(t, email)
+	assert.False(t, employee.Onboarding())
+	assert.Equal(t, "Onnie", employee.Name)
+	assert.Equal(t, "Engineer", employee.Position)
+	assert.NotEmpty(t, employee.Photo)
+	assert.Equal(t, "Likes onboarding", employee.Bio)
+	answered := 0
+	for _, reflection := range employee.Reflections {
+		if reflection.Value != "" {
+			answered++
+		}
+	}
+	assert.Equal(t, 1, answered, "Prompts left empty should stay unanswered")
+}
+
+func TestOnboarding_SkipAndResume(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	const email = "onboarding.later@objectcomputing.com"
+	newOnboardingEmployee(t, router, email)
+
+	recorder := onboardingRequest(t, email, "/onboarding/name/skip", nil)
+	assert.Contains(t, recorder.Body.String(), `hx-post="/onboarding/position"`)
+	recorder = onboardingRequest(t, email, "/onboarding/name", url.Values{model.FieldName: {"Twice"}})
+	assert.Contains(t, recorder.Body.String(), `hx-post="/onboarding/position"`, "A step which was already left should show the current one")
+	assert.NotEqual(t, "Twice", reloadEmployee(t, email).Name)
+
+	recorder = onboardingRequest(t, email, "/onboarding/later", nil)
+	assert.Equal(t, "/", recorder.Header().Get("HX-Redirect"))
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/", nil), email))
+	assert.Equal(t, http.StatusOK, recorder.Code, "Employees who chose to finish later should not be sent back")
+	assert.Contains(t, recorder.Body.String(), "Continue Setting Up")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, auth.OnboardingPath, nil), email))
+	assert.Contains(t, recorder.Body.String(), `hx-post="/onboarding/position"`, "Onboarding should resume where it was left")
+	assert.True(t, reloadEmployee(t, email).NeedsOnboarding())
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// orgChartHandler shows the whole organization or, when the "email"
+// query value names an employee, their chain of command and reports.
+func orgChartHandler(c *gin.Context) {
+	renderOrgChart(c, "org", http.StatusOK, c.Query("email"), gin.H{})
+}
+
+// setManagerHandler makes the employee with the "manager" form value the
+// manager of the one with the "email" form value, or clears their
+// manager when "manager" is blank.
+func setManagerHandler(c *gin.Context) {
+	email := c.PostForm("email")
+	err := repository.SetManager(c.Request.Context(), email, strings.TrimSpace(c.PostForm("manager")))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		renderOrgChart(c, "org-chart", http.StatusBadRequest, email, gin.H{"Error": "There is no employee with that email address."})
+	case errors.Is(err, repository.ErrManagerCycle):
+		renderOrgChart(c, "org-chart", http.StatusBadRequest, email, gin.H{"Error": "That manager already reports to this employee."})
+	case err != nil:
+		renderOrgChart(c, "org-chart", http.StatusBadRequest, email, gin.H{"Error": "The manager could not be changed: " + err.Error()})
+	default:
+		renderOrgChart(c, "org-chart", http.StatusOK, email, gin.H{})
+	}
+}
+
+// renderOrgChart renders an org chart template with the chart of active
+// employees added to data, focused on the employee with email unless it
+// is blank.
+func renderOrgChart(c *gin.Context, templateName string, status int, email string, data gin.H) {
+	employees, err := repository.GetEmployees(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employees: %v", err)
+		return
+	}
+	chart := model.NewOrgChart(employees)
+	data["Chart"] = chart
+	if email != "" {
+		focus := chart.Node(email)
+		if focus == nil {
+			c.String(http.StatusNotFound, "Employee not found")
+			return
+		}
+		data["Focus"] = focus
+		data["Chain"] = focus.ChainOfCommand()
+	}
+	renderTemplateWithStatus(c, templateName, data, status)
+}
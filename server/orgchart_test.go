@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgChart_ChainOfCommandAndReports(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	ceo := &model.Employee{Email: "org.ceo@objectcomputing.com", Name: "Org Ceo"}
+	vp := &model.Employee{Email: "org.vp@objectcomputing.com", Name: "Org Vp", ManagerEmail: ceo.Email}
+	dev := &model.Employee{Email: "org.dev@objectcomputing.com", Name: "Org Dev", ManagerEmail: vp.Email}
+	for _, employee := range []*model.Employee{ceo, vp, dev} {
+		saveEmployee(t, employee)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/org?email="+url.QueryEscape(vp.Email), nil), dev.Email))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, `<a class="app-link" href="/org?email=org.ceo%40objectcomputing.com">Org Ceo</a></li>`, "The chain of command should be shown")
+	assert.Contains(t, body, fmt.Sprintf(`id="org-%d"`, dev.ID), "Direct reports should be shown")
+	assert.NotContains(t, body, "Set Manager", "Only admins should be offered to change managers")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/org", nil), dev.Email))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), fmt.Sprintf(`id="org-%d"`, ceo.ID))
+
+	assert.Equal(t, http.StatusNotFound, responseCode(router, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/org?email=nobody@objectcomputing.com", nil), dev.Email)))
+}
+
+func TestOrgChart_AdminsSetManagers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	admin := &model.Employee{Email: "org.admin@objectcomputing.com", Name: "Org Admin", Admin: true}
+	boss := &model.Employee{Email: "org.boss@objectcomputing.com", Name: "Org Boss"}
+	worker := &model.Employee{Email: "org.worker@objectcomputing.com", Name: "Org Worker", ManagerEmail: boss.Email}
+	for _, employee := range []*model.Employee{admin, boss, worker} {
+		saveEmployee(t, employee)
+	}
+	setManager := func(email string, manager string, as string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/admin/org/manager", as, url.Values{"email": {email}, "manager": {manager}}))
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusForbidden, setManager(boss.Email, admin.Email, worker.Email).Code)
+
+	recorder := setManager(boss.Email, worker.Email, admin.Email)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "That manager already reports to this employee.")
+
+	recorder = setManager(boss.Email, admin.Email, admin.Email)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `<a class="app-link" href="/org?email=org.admin%40objectcomputing.com">Org Admin</a></li>`)
+
+	recorder = setManager(boss.Email, "", admin.Email)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Org Boss has no manager.")
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/photos"
+	"github.com/jeffscottbrown/satchel/repository"
+)
+
+// multipartOverhead allows for the form encoding around an uploaded
+// photo when limiting the size of the request body.
+const multipartOverhead = 64 << 10
+
+// photoRejection is why an uploaded photo could not be used, with the
+// status and message to show the user.
+type photoRejection struct {
+	status  int
+	message string
+}
+
+func (r *photoRejection) Error() string {
+	return r.message
+}
+
+// uploadPhotoHandler replaces the authenticated user's photo with the
+// image in the "photo" form file.
+func uploadPhotoHandler(c *gin.Context) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	if err := replacePhoto(c, employee); err != nil {
+		var rejection *photoRejection
+		if errors.As(err, &rejection) {
+			renderPhotoError(c, rejection.status, rejection.message)
+			return
+		}
+		c.String(http.StatusInternalServerError, "Error saving photo: %v", err)
+		return
+	}
+	renderTemplate(c, "person", gin.H{
+		"Employee":   employee,
+		"IsEditable": true})
+}
+
+// replacePhoto saves the image in the "photo" form file as employee's
+// photo and removes the one it replaces. It returns a *photoRejection
+// when the upload is unsuitable.
+func replacePhoto(c *gin.Context, employee *model.Employee) error {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, photos.MaxUploadBytes()+multipartOverhead)
+	file, _, err := c.Request.FormFile("photo")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return &photoRejection{http.StatusRequestEntityTooLarge, "That photo is too large."}
+		}
+		return &photoRejection{http.StatusBadRequest, "Choose a photo to upload."}
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, photos.MaxUploadBytes()+1))
+	if err != nil {
+		return &photoRejection{http.StatusBadRequest, "The photo could not be read."}
+	}
+	if int64(len(data)) > photos.MaxUploadBytes() {
+		return &photoRejection{http.StatusRequestEntityTooLarge, "That photo is too large."}
+	}
+
+	key, err := photos.Save(c.Request.Context(), data)
+	if errors.Is(err, photos.ErrUnsupportedFormat) || errors.Is(err, photos.ErrTooManyPixels) {
+		return &photoRejection{http.StatusBadRequest, err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	replaced := employee.Photo
+	employee.Photo = key
+	if err := repository.SaveEmployee(c.Request.Context(), employee); err != nil {
+		return err
+	}
+	removePhoto(c, replaced)
+	return nil
+}
+
+// deletePhotoHandler removes the authenticated user's uploaded photo so
+// that their avatar is shown again.
+func deletePhotoHandler(c *gin.Context) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	removed := employee.Photo
+	employee.Photo = ""
+	if err := repository.SaveEmployee(c.Request.Context(), employee); err != nil {
+		c.String(http.StatusInternalServerError, "Error saving employee: %v", err)
+		return
+	}
+	removePhoto(c, removed)
+	renderTemplate(c, "person", gin.H{
+		"Employee":   employee,
+		"IsEditable": true})
+}
+
+// removePhoto deletes a photo which is no longer used. A failure only
+// leaves an orphaned file behind, so it is logged rather than reported.
+func removePhoto(c *gin.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := photos.Remove(c.Request.Context(), key); err != nil {
+		logging.FromContext(c.Request.Context()).WarnContext(c.Request.Context(), "failed to remove replaced photo",
+			slog.String("photo", key), slog.Any("error", err))
+	}
+}
+
+func renderPhotoError(c *gin.Context, status int, message string) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	renderTemplateWithStatus(c, "person", gin.H{
+		"Employee":   employee,
+		"IsEditable": true,
+		"PhotoError": message,
+	}, status)
+}
+
+// photoHandler serves a stored photo. A photo's name changes whenever it
+// is replaced, so browsers may cache it indefinitely.
+func photoHandler(c *gin.Context) {
+	reader, err := photos.Open(c.Request.Context(), c.Param("name"))
+	if errors.Is(err, photos.ErrNotFound) {
+		c.String(http.StatusNotFound, "Photo not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving photo: %v", err)
+		return
+	}
+	defer reader.Close()
+	c.DataFromReader(http.StatusOK, -1, photos.ContentType, reader, map[string]string{
+		"Cache-Control":          "private, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
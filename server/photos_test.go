@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/photos"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func photoRequest(t *testing.T, email string, contents []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("photo", "me.png")
+	assert.NoError(t, err)
+	part.Write(contents)
+	assert.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, "/photo", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("HX-Request", "true")
+	return auth.AuthenticateRequestForTest(t, req, email)
+}
+
+func samplePNG(t *testing.T, width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func photoEmployee(t *testing.T) *model.Employee {
+	employee, err := repository.GetEmployeeByEmail(t.Context(), "photogenic@objectcomputing.com")
+	assert.NoError(t, err)
+	return employee
+}
+
+func TestPhoto_UploadAndServe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	photos.ConfigureStorageForTest(t)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "photogenic@objectcomputing.com", Name: "Photogenic", ImageName: "/static/images/avatar.png"})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, photoRequest(t, "photogenic@objectcomputing.com", samplePNG(t, 300, 200)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	employee := photoEmployee(t)
+	assert.NotEmpty(t, employee.Photo)
+	assert.Contains(t, recorder.Body.String(), employee.PhotoURL(photos.Full.Name))
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t,
+		httptest.NewRequest(http.MethodGet, employee.PhotoURL(photos.Thumbnail.Name), nil), "photogenic@objectcomputing.com"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "image/jpeg", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Header().Get("Cache-Control"), "immutable")
+	thumbnail, _, err := image.DecodeConfig(recorder.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, photos.Thumbnail.Pixels, thumbnail.Width)
+	assert.Equal(t, photos.Thumbnail.Pixels, thumbnail.Height)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t,
+		httptest.NewRequest(http.MethodGet, "/", nil), "photogenic@objectcomputing.com"))
+	assert.Contains(t, recorder.Body.String(), employee.PhotoURL(photos.Thumbnail.Name), "The directory should show thumbnails")
+}
+
+func TestPhoto_ReplacingRemovesThePreviousPhoto(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	photos.ConfigureStorageForTest(t)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "photogenic@objectcomputing.com", Name: "Photogenic"})
+	assert.Equal(t, http.StatusOK, responseCode(router, photoRequest(t, "photogenic@objectcomputing.com", samplePNG(t, 50, 50))))
+	first := photoEmployee(t).PhotoURL(photos.Full.Name)
+
+	assert.Equal(t, http.StatusOK, responseCode(router, photoRequest(t, "photogenic@objectcomputing.com", samplePNG(t, 60, 60))))
+
+	assert.NotEqual(t, first, photoEmployee(t).PhotoURL(photos.Full.Name))
+	assert.Equal(t, http.StatusNotFound, responseCode(router, auth.AuthenticateRequestForTest(t,
+		httptest.NewRequest(http.MethodGet, first, nil), "photogenic@objectcomputing.com")))
+
+	req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodDelete, "/photo", nil), "photogenic@objectcomputing.com")
+	assert.Equal(t, http.StatusOK, responseCode(router, req))
+	assert.Empty(t, photoEmployee(t).Photo)
+}
+
+func TestPhoto_RejectsUnsuitableUploads(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	photos.ConfigureStorageForTest(t)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "photogenic@objectcomputing.com", Name: "Photogenic"})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, photoRequest(t, "photogenic@objectcomputing.com", []byte("<svg onload=alert(1)></svg>")))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "photos must be JPEG, PNG, GIF or WebP images")
+
+	tooLarge := make([]byte, photos.MaxUploadBytes()+1)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, responseCode(router, photoRequest(t, "photogenic@objectcomputing.com", tooLarge)))
+
+	assert.Empty(t, photoEmployee(t).Photo)
+}
+
+func TestPhoto_UnknownNamesAreNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	photos.ConfigureStorageForTest(t)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "photogenic@objectcomputing.com", Name: "Photogenic"})
+
+	for _, name := range []string{"0123456789abcdef0123456789abcdef-full.jpg", "..%2F..%2Fgo.mod", "secret.txt"} {
+		req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/photos/"+name, nil), "photogenic@objectcomputing.com")
+		assert.Equal(t, http.StatusNotFound, responseCode(router, req), name)
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// answerReflectionHandler answers one of the prompts the authenticated
+// user was given with the "value" form value.
+func answerReflectionHandler(c *gin.Context) {
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
+	id, err := strconv.ParseUint(c.Param("reflectionId"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid reflection ID")
+		return
+	}
+	value := strings.TrimSpace(c.PostForm("value"))
+	if value == "" {
+		c.String(http.StatusBadRequest, "Answer cannot be empty")
+		return
+	}
+	if err := repository.AnswerReflection(c.Request.Context(), authenticatedUser, uint(id), value); err != nil {
+		c.String(http.StatusBadRequest, "Error answering reflection: %v", err)
+		return
+	}
+	user, _ := repository.GetEmployeeByEmail(c.Request.Context(), authenticatedUser)
+	renderTemplate(c, "person", gin.H{
+		"Employee":   user,
+		"IsEditable": true})
+}
+
+// promptSuggestionHandler offers the authenticated user an active prompt
+// they have not answered yet, so that prompts added to the bank reach
+// existing employees. The "skip" query value names a prompt which was
+// just offered and should not be offered again straight away.
+func promptSuggestionHandler(c *gin.Context) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	prompts, err := repository.GetActivePrompts(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving prompts: %v", err)
+		return
+	}
+	skip := c.Query("skip")
+	var unanswered []model.Prompt
+	for _, prompt := range prompts {
+		if !employee.HasPrompt(prompt) && strconv.FormatUint(uint64(prompt.ID), 10) != skip {
+			unanswered = append(unanswered, prompt)
+		}
+	}
+	data := gin.H{}
+	if len(unanswered) > 0 {
+		data["Prompt"] = unanswered[rand.IntN(len(unanswered))]
+		data["More"] = len(unanswered) > 1 || skip != ""
+	}
+	renderTemplate(c, "prompt-suggestion", data)
+}
+
+// answerPromptHandler adds the authenticated user's answer, the "value"
+// form value, to a prompt they were offered.
+func answerPromptHandler(c *gin.Context) {
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
+	prompt, ok := promptFromPath(c)
+	if !ok {
+		return
+	}
+	value := strings.TrimSpace(c.PostForm("value"))
+	if !prompt.Active || value == "" {
+		c.String(http.StatusBadRequest, "Answer an active prompt")
+		return
+	}
+	if err := repository.AnswerPrompt(c.Request.Context(), authenticatedUser, *prompt, value); err != nil {
+		c.String(http.StatusBadRequest, "Error answering prompt: %v", err)
+		return
+	}
+	user, _ := repository.GetEmployeeByEmail(c.Request.Context(), authenticatedUser)
+	renderTemplate(c, "person", gin.H{
+		"Employee":   user,
+		"IsEditable": true})
+}
+
+func promptFromPath(c *gin.Context) (*model.Prompt, bool) {
+	id, err := strconv.ParseUint(c.Param("promptId"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid prompt ID")
+		return nil, false
+	}
+	prompt, err := repository.GetPrompt(c.Request.Context(), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "Prompt not found")
+		return nil, false
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving prompt: %v", err)
+		return nil, false
+	}
+	return prompt, true
+}
+
+func promptsPageHandler(c *gin.Context) {
+	renderPrompts(c, "prompts", http.StatusOK, gin.H{})
+}
+
+// createPromptHandler adds the "question" form value to the bank in the
+// "category" form value. New prompts are active.
+func createPromptHandler(c *gin.Context) {
+	prompt := &model.Prompt{Active: true, Curated: c.PostForm("curated") != ""}
+	if !readPromptForm(c, prompt) {
+		return
+	}
+	if err := repository.SavePrompt(c.Request.Context(), prompt); err != nil {
+		renderPrompts(c, "prompt-list", http.StatusBadRequest, gin.H{"Error": "The prompt could not be saved. Is the question already in the bank?"})
+		return
+	}
+	renderPrompts(c, "prompt-list", http.StatusOK, gin.H{})
+}
+
+// updatePromptHandler changes a prompt's question and category and
+// whether it is active and curated.
+func updatePromptHandler(c *gin.Context) {
+	prompt, ok := promptFromPath(c)
+	if !ok {
+		return
+	}
+	if !readPromptForm(c, prompt) {
+		return
+	}
+	prompt.Active = c.PostForm("active") != ""
+	prompt.Curated = c.PostForm("curated") != ""
+	if err := repository.SavePrompt(c.Request.Context(), prompt); err != nil {
+		renderPrompts(c, "prompt-list", http.StatusBadRequest, gin.H{"Error": "The prompt could not be saved. Is the question already in the bank?"})
+		return
+	}
+	renderPrompts(c, "prompt-list", http.StatusOK, gin.H{})
+}
+
+func readPromptForm(c *gin.Context, prompt *model.Prompt) bool {
+	question := strings.TrimSpace(c.PostForm("question"))
+	if question == "" {
+		renderPrompts(c, "prompt-list", http.StatusBadRequest, gin.H{"Error": "A prompt needs a question."})
+		return false
+	}
+	prompt.Question = question
+	prompt.Category = strings.TrimSpace(c.PostForm("category"))
+	return true
+}
+
+// renderPrompts renders a prompts template with every prompt in the bank
+// added to data.
+func renderPrompts(c *gin.Context, templateName string, status int, data gin.H) {
+	prompts, err := repository.GetPrompts(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving prompts: %v", err)
+		return
+	}
+	data["Prompts"] = prompts
+	renderTemplateWithStatus(c, templateName, data, status)
+}
@@ -0,0 +1,132 @@
+//go:build !production
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// findPrompt returns the prompt in the bank with question, if there is one.
+func findPrompt(t *testing.T, question string) *model.Prompt {
+	prompts, err := repository.GetPrompts(t.Context())
+	assert.NoError(t, err)
+	for i := range prompts {
+		if prompts[i].Question == question {
+			return &prompts[i]
+		}
+	}
+	return nil
+}
+
+func TestPrompts_NewProfilesStartWithPrompts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	const email = "prompted.user@objectcomputing.com"
+	t.Cleanup(func() {
+		repository.DeleteEmployee(context.Background(), email)
+	})
+
+	saveEmployee(t, &model.Employee{Email: "someone.else@objectcomputing.com", Name: "Someone Else"})
+	router.ServeHTTP(httptest.NewRecorder(), developmentLoginRequest(email))
+	finishOnboarding(t, email)
+
+	employee := reloadEmployee(t, email)
+	assert.Len(t, employee.Reflections, 4)
+	for _, reflection := range employee.Reflections {
+		assert.NotNil(t, reflection.PromptID)
+		assert.Empty(t, reflection.Value)
+		assert.NotContains(t, reflection.Key, "Temporary Thing")
+	}
+
+	path := "/reflection/" + strconv.FormatUint(uint64(employee.Reflections[0].ID), 10)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPut, path, email, url.Values{"value": {"Grateful Dead"}}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Grateful Dead")
+	assert.Equal(t, http.StatusBadRequest, responseCode(router, htmxRequest(t, http.MethodPut, path, email, url.Values{"value": {" "}})))
+	assert.Equal(t, http.StatusBadRequest, responseCode(router, htmxRequest(t, http.MethodPut, path, "someone.else@objectcomputing.com", url.Values{"value": {"Mine now"}})),
+		"Users should not be able to answer the prompts of others")
+}
+
+func TestPrompts_ExistingEmployeesAreOfferedNewPrompts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "veteran@objectcomputing.com", Name: "Veteran"})
+	employee := reloadEmployee(t, "veteran@objectcomputing.com")
+	prompts, err := repository.GetActivePrompts(t.Context())
+	assert.NoError(t, err)
+	for i := 1; i < len(prompts); i++ {
+		employee.AddPrompt(prompts[i])
+	}
+	assert.NoError(t, repository.SaveEmployee(t.Context(), employee))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/prompts/suggestion", nil), "veteran@objectcomputing.com"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "/prompts/"+strconv.FormatUint(uint64(prompts[0].ID), 10)+"/answer",
+		"The only unanswered prompt should be offered")
+
+	path := "/prompts/" + strconv.FormatUint(uint64(prompts[0].ID), 10) + "/answer"
+	assert.Equal(t, http.StatusOK, responseCode(router, htmxRequest(t, http.MethodPost, path, "veteran@objectcomputing.com", url.Values{"value": {"An answer"}})))
+	assert.Len(t, reloadEmployee(t, "veteran@objectcomputing.com").Reflections, len(prompts))
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/prompts/suggestion", nil), "veteran@objectcomputing.com"))
+	assert.NotContains(t, recorder.Body.String(), "suggested-prompt", "Nothing should be offered once every prompt is answered")
+}
+
+func TestPrompts_AdminsManageTheBank(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "admin@objectcomputing.com", Name: "Admin", Admin: true})
+	saveEmployee(t, &model.Employee{Email: "regular@objectcomputing.com", Name: "Regular"})
+	const question = "Favorite board game"
+	t.Cleanup(func() {
+		prompts, _ := repository.GetPrompts(context.Background())
+		for i := range prompts {
+			if prompts[i].Question == question {
+				prompts[i].Active = false
+				repository.SavePrompt(context.Background(), &prompts[i])
+			}
+		}
+	})
+
+	assert.Equal(t, http.StatusForbidden, responseCode(router, htmxRequest(t, http.MethodPost, "/admin/prompts", "regular@objectcomputing.com", url.Values{"question": {question}})))
+	assert.Nil(t, findPrompt(t, question))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/admin/prompts", "admin@objectcomputing.com", url.Values{"question": {question}, "category": {"Games"}}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), question)
+	prompt := findPrompt(t, question)
+	assert.True(t, prompt.Active)
+	assert.Equal(t, "Games", prompt.Category)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/admin/prompts", "admin@objectcomputing.com", url.Values{"question": {question}}))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "Questions should be unique")
+	assert.Contains(t, recorder.Body.String(), "already in the bank")
+
+	path := "/admin/prompts/" + strconv.FormatUint(uint64(prompt.ID), 10)
+	assert.Equal(t, http.StatusOK, responseCode(router, htmxRequest(t, http.MethodPost, path, "admin@objectcomputing.com", url.Values{"question": {question}, "category": {"Hobbies"}})))
+	prompt = findPrompt(t, question)
+	assert.False(t, prompt.Active)
+	assert.Equal(t, "Hobbies", prompt.Category)
+	active, err := repository.GetActivePrompts(t.Context())
+	assert.NoError(t, err)
+	for _, p := range active {
+		assert.NotEqual(t, question, p.Question, "Inactive prompts should not be offered")
+	}
+	assert.Equal(t, http.StatusNotFound, responseCode(router, htmxRequest(t, http.MethodPost, "/admin/prompts/999999", "admin@objectcomputing.com", url.Values{"question": {"Gone"}})))
+}
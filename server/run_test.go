@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe_DrainsInFlightRequestsOnShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	requestStarted := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requestStarted)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("finished"))
+	})
+
+	settings := config.Default().HTTP
+	settings.Address = address
+	settings.ShutdownTimeout = 5 * time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveResult := make(chan error, 1)
+	go func() {
+		serveResult <- serve(ctx, newHTTPServer(handler, settings), settings)
+	}()
+
+	responseBody := make(chan string, 1)
+	go func() {
+		var response *http.Response
+		var getErr error
+		for range 50 {
+			response, getErr = http.Get("http://" + address + "/")
+			if getErr == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if getErr != nil {
+			responseBody <- getErr.Error()
+			return
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		responseBody <- string(body)
+	}()
+
+	<-requestStarted
+	cancel()
+
+	assert.Equal(t, "finished", <-responseBody, "In-flight request should complete during shutdown")
+	assert.NoError(t, <-serveResult)
+}
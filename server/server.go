@@ -1,18 +1,30 @@
 package server
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"io/fs"
+	"log/slog"
+	"os/signal"
 	"strconv"
+	"syscall"
 
 	"github.com/jeffscottbrown/satchel/repository"
-	"github.com/markbates/goth/gothic"
 
 	"html/template"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/completeness"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/markdown"
+	"github.com/jeffscottbrown/satchel/metrics"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/scim"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 //go:embed assets/**
@@ -21,60 +33,192 @@ var embeddedAssets embed.FS
 //go:embed html/*.html
 var embeddedHTMLFiles embed.FS
 
-func Run() {
-	router := createRouter()
+// Run serves the application until SIGINT or SIGTERM is received, at
+// which point in-flight requests are given up to
+// settings.ShutdownTimeout to complete before Run returns.
+func Run(settings config.HTTP) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	router.Run()
+	publicURL = settings.PublicURL
+
+	return serve(ctx, newHTTPServer(createRouter(), settings), settings)
+}
+
+func newHTTPServer(handler http.Handler, settings config.HTTP) *http.Server {
+	return &http.Server{
+		Addr:              settings.ListenAddress(),
+		Handler:           handler,
+		ReadTimeout:       settings.ReadTimeout,
+		ReadHeaderTimeout: settings.ReadHeaderTimeout,
+		WriteTimeout:      settings.WriteTimeout,
+		IdleTimeout:       settings.IdleTimeout,
+		MaxHeaderBytes:    settings.MaxHeaderBytes,
+	}
+}
+
+func serve(ctx context.Context, httpServer *http.Server, settings config.HTTP) error {
+	serverErrors := make(chan error, 1)
+	go func() {
+		slog.Info("Starting HTTP server", slog.String("address", httpServer.Addr), slog.Bool("tls", settings.TLSEnabled()))
+		if settings.TLSEnabled() {
+			serverErrors <- httpServer.ListenAndServeTLS(settings.TLSCertFile, settings.TLSKeyFile)
+		} else {
+			serverErrors <- httpServer.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serverErrors:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received, draining in-flight requests", slog.Duration("timeout", settings.ShutdownTimeout))
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), settings.ShutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		slog.Info("HTTP server stopped")
+		return nil
+	}
 }
 
 func createRouter() *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+	// Recovery is registered inside logging so that a request which panics
+	// still has its 500 written to the access log.
+	router.Use(otelgin.Middleware("satchel"), logging.Middleware, gin.Recovery(), auth.BearerToken, auth.LoadSession, auth.IdentifyUser, auth.GuardImpersonation, auth.GuardOnboarding, metrics.Middleware)
 	configureRoutes(router)
 	return router
 }
 
 func init() {
-	tmpl = template.Must(template.New("").ParseFS(embeddedHTMLFiles, "html/*.html"))
+	tmpl = template.Must(template.New("").Funcs(template.FuncMap{
+		"identityFields": identityFields,
+		"completeness":   completeness.Score,
+		"markdown":       markdown.Render,
+		"proficiency":    model.ProficiencyName,
+	}).ParseFS(embeddedHTMLFiles, "html/*.html"))
 }
 
 func configureRoutes(router *gin.Engine) {
 	staticFiles, _ := fs.Sub(embeddedAssets, "assets")
 	router.StaticFS("/static", http.FS(staticFiles))
+	router.GET("/metrics", metrics.Authenticate, gin.WrapH(metrics.Handler()))
 
 	router.GET("/", rootHandler)
 	router.GET("/employee/:employeeEmail", auth.AuthRequired, employeeHandler)
+	router.GET("/employee/:employeeEmail/vcard", auth.AuthRequired, vCardHandler)
+	router.GET("/employee/:employeeEmail/skills", auth.AuthRequired, employeeSkillsHandler)
+	router.POST("/employee/:employeeEmail/skills/:skillId/endorse", auth.AuthRequired, endorseHandler)
+	router.DELETE("/employee/:employeeEmail/skills/:skillId/endorse", auth.AuthRequired, withdrawEndorsementHandler)
+	router.GET("/export", auth.AuthRequired, exportHandler)
+	router.GET("/org", auth.AuthRequired, orgChartHandler)
 	router.POST("/bio", auth.AuthRequired, bioHandler)
+	router.POST("/bio/preview", auth.AuthRequired, previewBioHandler)
 	router.POST("/position", auth.AuthRequired, positionHandler)
+	router.POST("/name", auth.AuthRequired, nameHandler)
+	router.POST("/name/reset/:field", auth.AuthRequired, resetIdentityFieldHandler)
 	router.POST("/reflection", auth.AuthRequired, addReflectionHandler)
 	router.DELETE("/reflection/:reflectionId", auth.AuthRequired, deleteReflectionHandler)
+	router.PUT("/reflection/:reflectionId", auth.AuthRequired, answerReflectionHandler)
+	router.GET("/prompts/suggestion", auth.AuthRequired, promptSuggestionHandler)
+	router.POST("/prompts/:promptId/answer", auth.AuthRequired, answerPromptHandler)
+	router.POST("/photo", auth.AuthRequired, uploadPhotoHandler)
+	router.DELETE("/photo", auth.AuthRequired, deletePhotoHandler)
+	router.GET("/photos/:name", auth.AuthRequired, photoHandler)
+	router.GET("/avatars/:employeeId/:size", auth.AuthRequired, avatarHandler)
 	router.GET("/forbidden", forbiddenHandler)
+
+	skills := router.Group("/skills", auth.AuthRequired)
+	skills.GET("", skillSearchHandler)
+	skills.POST("", setSkillHandler)
+	skills.DELETE("/:skillId", removeSkillHandler)
+
+	teams := router.Group("/teams", auth.AuthRequired)
+	teams.GET("", teamsHandler)
+	teams.GET("/:teamId", teamHandler)
+	teams.POST("/:teamId/join", joinTeamHandler)
+	teams.POST("/:teamId/leave", leaveTeamHandler)
+	teams.POST("", auth.SessionRequired, auth.AdminRequired, createTeamHandler)
+	teams.POST("/:teamId/members", auth.SessionRequired, auth.AdminRequired, addTeamMemberHandler)
+	teams.DELETE("/:teamId/members/:employeeEmail", auth.SessionRequired, auth.AdminRequired, removeTeamMemberHandler)
+
+	onboarding := router.Group(auth.OnboardingPath, auth.AuthRequired, auth.SessionRequired)
+	onboarding.GET("", onboardingHandler)
+	onboarding.POST("/later", finishOnboardingLaterHandler)
+	onboarding.POST("/:step", onboardingStepHandler)
+	onboarding.POST("/:step/skip", skipOnboardingStepHandler)
+
+	tokens := router.Group("/tokens", auth.AuthRequired, auth.SessionRequired, auth.RealUserRequired)
+	tokens.GET("", tokensHandler)
+	tokens.POST("", createTokenHandler)
+	tokens.DELETE("/:tokenId", revokeTokenHandler)
+
+	sessions := router.Group("/sessions", auth.AuthRequired, auth.SessionRequired, auth.RealUserRequired)
+	sessions.GET("", sessionsHandler)
+	sessions.DELETE("/:sessionId", revokeSessionHandler)
+	sessions.POST("/revoke-others", revokeOtherSessionsHandler)
+
+	admin := router.Group("/admin", auth.AuthRequired, auth.SessionRequired, auth.AdminRequired)
+	admin.GET("/import", importPageHandler)
+	admin.POST("/import", importUploadHandler)
+	admin.POST("/sessions/revoke", revokeAllSessionsHandler)
+	admin.GET("/prompts", promptsPageHandler)
+	admin.POST("/prompts", createPromptHandler)
+	admin.POST("/prompts/:promptId", updatePromptHandler)
+	admin.GET("/completeness", completenessPageHandler)
+	admin.POST("/completeness/remind", remindHandler)
+	admin.POST("/org/manager", setManagerHandler)
+	admin.GET("/skills", skillsPageHandler)
+	admin.POST("/skills", createSkillHandler)
+	admin.POST("/skills/:skillId", updateSkillHandler)
+
 	auth.ConfigureAuthorizationHandlers(router)
+	scim.ConfigureRoutes(router)
 }
 
 func positionHandler(c *gin.Context) {
-	authenticatedUser, _ := gothic.GetFromSession("authenticatedUser", c.Request)
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
 	newPosition := c.PostForm("position")
 	if newPosition == "" {
 		c.String(http.StatusBadRequest, "Position cannot be empty")
 		return
 	}
-	repository.SavePosition(authenticatedUser, newPosition)
-	user, _ := repository.GetEmployeeByEmail(authenticatedUser)
+	repository.SavePosition(c.Request.Context(), authenticatedUser, newPosition)
+	user, _ := repository.GetEmployeeByEmail(c.Request.Context(), authenticatedUser)
 	renderTemplate(c, "person", gin.H{
 		"Employee":   user,
 		"IsEditable": true})
 }
 
 func bioHandler(c *gin.Context) {
-	authenticatedUser, _ := gothic.GetFromSession("authenticatedUser", c.Request)
-	repository.SaveBio(authenticatedUser, c.PostForm("biotext"))
-	user, _ := repository.GetEmployeeByEmail(authenticatedUser)
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
+	if err := repository.SaveBio(c.Request.Context(), authenticatedUser, c.PostForm("biotext")); errors.Is(err, repository.ErrBioTooLong) {
+		c.String(http.StatusBadRequest, "Bio cannot be longer than %d characters", model.MaxBioLength)
+		return
+	}
+	user, _ := repository.GetEmployeeByEmail(c.Request.Context(), authenticatedUser)
 	renderTemplate(c, "person", gin.H{
 		"Employee":   user,
 		"IsEditable": true})
 }
+
+// previewBioHandler renders a bio as it would be shown, without saving it.
+func previewBioHandler(c *gin.Context) {
+	bio := c.PostForm("biotext")
+	if model.BioTooLong(bio) {
+		c.String(http.StatusBadRequest, "Bio cannot be longer than %d characters", model.MaxBioLength)
+		return
+	}
+	renderTemplate(c, "bio-preview", gin.H{"Employee": &model.Employee{Bio: bio}})
+}
+
 func deleteReflectionHandler(c *gin.Context) {
-	authenticatedUser, _ := gothic.GetFromSession("authenticatedUser", c.Request)
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
 
 	reflectionId := c.Param("reflectionId")
 
@@ -83,20 +227,20 @@ func deleteReflectionHandler(c *gin.Context) {
 		c.String(http.StatusBadRequest, "Invalid reflection ID")
 		return
 	}
-	repository.DeleteReflection(authenticatedUser, uint(id))
+	repository.DeleteReflection(c.Request.Context(), authenticatedUser, uint(id))
 
-	user, _ := repository.GetEmployeeByEmail(authenticatedUser)
+	user, _ := repository.GetEmployeeByEmail(c.Request.Context(), authenticatedUser)
 	renderTemplate(c, "person", gin.H{
 		"Employee":   user,
 		"IsEditable": true})
 }
 
 func addReflectionHandler(c *gin.Context) {
-	authenticatedUser, _ := gothic.GetFromSession("authenticatedUser", c.Request)
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
 	newReflectioName := c.PostForm("new-reflection-name")
 	newReflectionValue := c.PostForm("new-reflection-value")
-	repository.AddReflection(authenticatedUser, newReflectioName, newReflectionValue)
-	user, _ := repository.GetEmployeeByEmail(authenticatedUser)
+	repository.AddReflection(c.Request.Context(), authenticatedUser, newReflectioName, newReflectionValue)
+	user, _ := repository.GetEmployeeByEmail(c.Request.Context(), authenticatedUser)
 	renderTemplate(c, "person", gin.H{
 		"Employee":   user,
 		"IsEditable": true})
@@ -107,26 +251,52 @@ func forbiddenHandler(c *gin.Context) {
 }
 
 func rootHandler(c *gin.Context) {
-	employees, err := repository.GetEmployees()
+	employees, err := repository.GetEmployees(c.Request.Context())
 	if err != nil {
 		c.String(http.StatusInternalServerError, "Error retrieving employees: %v", err)
 		return
 	}
-	user, _ := gothic.GetFromSession("authenticatedUser", c.Request)
-	renderTemplate(c, "main", gin.H{
+	teams, err := repository.GetTeams(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving teams: %v", err)
+		return
+	}
+	user, _ := auth.AuthenticatedUser(c.Request)
+	data := gin.H{
 		"Employees":         employees,
 		"AuthenticatedUser": user,
-	})
+		"Teams":             teams,
+	}
+	// The "team" query value narrows the directory to a team's members.
+	var selectedTeam *model.Team
+	if id, err := strconv.ParseUint(c.Query("team"), 10, 64); err == nil {
+		for i := range teams {
+			if teams[i].ID == uint(id) {
+				selectedTeam = &teams[i]
+			}
+		}
+	}
+	if selectedTeam != nil {
+		var members []*model.Employee
+		for i := range employees {
+			if selectedTeam.Membership(employees[i].ID) != nil {
+				members = append(members, &employees[i])
+			}
+		}
+		data["Employees"] = members
+		data["SelectedTeam"] = selectedTeam
+	}
+	renderTemplate(c, "main", data)
 }
 
 func employeeHandler(c *gin.Context) {
 	employeeEmail := c.Param("employeeEmail")
-	employee, err := repository.GetEmployeeByEmail(employeeEmail)
+	employee, err := repository.GetEmployeeByEmail(c.Request.Context(), employeeEmail)
 	if err != nil {
 		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
 		return
 	}
-	authenticatedUser, _ := gothic.GetFromSession("authenticatedUser", c.Request)
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
 
 	isEditable := authenticatedUser != "" && authenticatedUser == employee.Email
 
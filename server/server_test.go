@@ -1,13 +1,20 @@
 package server
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/metrics"
 	"github.com/jeffscottbrown/satchel/model"
 	"github.com/jeffscottbrown/satchel/repository"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +35,51 @@ func TestRootEndpoint(t *testing.T) {
 	assert.Equal(t, http.StatusOK, recorder.Code, "Expected status code 200")
 
 	assert.Contains(t, recorder.Body.String(), "<title>Satchel</title>", "Page title should be 'Satchel'")
+	assert.NotEmpty(t, recorder.Header().Get("X-Request-ID"), "Every response should carry a request ID")
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Metrics should require the bearer token")
+
+	metrics.Configure(config.Metrics{Token: "scrape-token"})
+	t.Cleanup(func() { metrics.Configure(config.Metrics{}) })
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	request.Header.Set("Authorization", "Bearer scrape-token")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `satchel_http_request_duration_seconds_count{method="GET",route="/",status="200"}`)
+	assert.Contains(t, recorder.Body.String(), "satchel_employees")
+	assert.Contains(t, recorder.Body.String(), `go_sql_open_connections{db_name="satchel"}`)
+}
+
+func TestPanicsAreLogged(t *testing.T) {
+	var output bytes.Buffer
+	original := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(original) })
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&output, nil)))
+
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	router.GET("/panics", func(c *gin.Context) {
+		panic("something went wrong")
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panics", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, output.String(), `"msg":"request completed"`, "A request which panics should still be logged")
+	assert.Contains(t, output.String(), `"status":500`)
+	assert.Contains(t, output.String(), recorder.Header().Get("X-Request-ID"))
 }
 
 func TestRootHandler_GetEmployeeesError(t *testing.T) {
@@ -37,6 +89,7 @@ func TestRootHandler_GetEmployeeesError(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil)
 	rootHandler(c)
 
 	assert.Equal(t, http.StatusInternalServerError, c.Writer.Status(), "Expected status code 500")
@@ -44,7 +97,7 @@ func TestRootHandler_GetEmployeeesError(t *testing.T) {
 }
 
 func TestEmployeeHandler(t *testing.T) {
-	repository.SaveEmployee(&model.Employee{
+	repository.SaveEmployee(t.Context(), &model.Employee{
 		// Name:      "Henry David Thoreau",
 		Email: "henry@thewods.org",
 		Reflections: []model.Reflection{
@@ -109,28 +162,204 @@ type errorThrowingEmployeeRepository struct {
 }
 
 // DeleteEmployee implements repository.EmployeeRepository.
-func (m *errorThrowingEmployeeRepository) DeleteEmployee(email string) error {
+func (m *errorThrowingEmployeeRepository) DeleteEmployee(ctx context.Context, email string) error {
 	panic("unimplemented")
 }
 
 // DeleteReflection implements repository.EmployeeRepository.
-func (m *errorThrowingEmployeeRepository) DeleteReflection(reflectionId uint) error {
+func (m *errorThrowingEmployeeRepository) DeleteReflection(ctx context.Context, reflectionId uint) error {
 	panic("unimplemented")
 }
 
 // SaveEmployee implements repository.EmployeeRepository.
-func (m *errorThrowingEmployeeRepository) SaveEmployee(employee *model.Employee) error {
+func (m *errorThrowingEmployeeRepository) SaveEmployee(ctx context.Context, employee *model.Employee) error {
+	panic("unimplemented")
+}
+
+// SaveAvatar implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveAvatar(ctx context.Context, employeeID uint, avatar string, source string) error {
+	panic("unimplemented")
+}
+
+// SaveRemindedAt implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveRemindedAt(ctx context.Context, employeeID uint, remindedAt time.Time) error {
+	panic("unimplemented")
+}
+
+// CountEmployees implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) CountEmployees(ctx context.Context) (int64, error) {
+	panic("unimplemented")
+}
+
+// CountEmployeesWithoutBio implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) CountEmployeesWithoutBio(ctx context.Context) (int64, error) {
+	panic("unimplemented")
+}
+
+// GetEmployeesWithReflections implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetEmployeesWithReflections(ctx context.Context) ([]model.Employee, error) {
+	panic("unimplemented")
+}
+
+// GetAllEmployees implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetAllEmployees(ctx context.Context) ([]model.Employee, error) {
+	panic("unimplemented")
+}
+
+// GetEmployeeByID implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetEmployeeByID(ctx context.Context, id uint) (*model.Employee, error) {
+	panic("unimplemented")
+}
+
+// SaveAPIToken implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveAPIToken(ctx context.Context, token *model.APIToken) error {
+	panic("unimplemented")
+}
+
+// GetAPITokens implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetAPITokens(ctx context.Context, employeeID uint) ([]model.APIToken, error) {
+	panic("unimplemented")
+}
+
+// GetAPITokenByHash implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetAPITokenByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	panic("unimplemented")
+}
+
+// DeleteAPIToken implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) DeleteAPIToken(ctx context.Context, employeeID uint, id uint) error {
+	panic("unimplemented")
+}
+
+// SaveSession implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveSession(ctx context.Context, session *model.Session) error {
+	panic("unimplemented")
+}
+
+// GetSessionByKeyHash implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetSessionByKeyHash(ctx context.Context, keyHash string) (*model.Session, error) {
+	panic("unimplemented")
+}
+
+// GetSessions implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetSessions(ctx context.Context, email string) ([]model.Session, error) {
+	panic("unimplemented")
+}
+
+// DeleteSession implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) DeleteSession(ctx context.Context, email string, id uint) error {
+	panic("unimplemented")
+}
+
+// DeleteSessions implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) DeleteSessions(ctx context.Context, email string) (int64, error) {
+	panic("unimplemented")
+}
+
+// SaveReflection implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveReflection(ctx context.Context, reflection *model.Reflection) error {
+	panic("unimplemented")
+}
+
+// SavePrompt implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SavePrompt(ctx context.Context, prompt *model.Prompt) error {
+	panic("unimplemented")
+}
+
+// GetPrompts implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetPrompts(ctx context.Context) ([]model.Prompt, error) {
+	panic("unimplemented")
+}
+
+// GetActivePrompts implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetActivePrompts(ctx context.Context) ([]model.Prompt, error) {
+	panic("unimplemented")
+}
+
+// GetPrompt implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetPrompt(ctx context.Context, id uint) (*model.Prompt, error) {
+	panic("unimplemented")
+}
+
+// SaveTeam implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveTeam(ctx context.Context, team *model.Team) error {
+	panic("unimplemented")
+}
+
+// GetTeams implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetTeams(ctx context.Context) ([]model.Team, error) {
+	panic("unimplemented")
+}
+
+// GetTeam implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetTeam(ctx context.Context, id uint) (*model.Team, error) {
+	panic("unimplemented")
+}
+
+// SaveTeamMembership implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveTeamMembership(ctx context.Context, membership *model.TeamMembership) error {
+	panic("unimplemented")
+}
+
+// DeleteTeamMembership implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) DeleteTeamMembership(ctx context.Context, teamID uint, employeeID uint) error {
+	panic("unimplemented")
+}
+
+// SaveSkill implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveSkill(ctx context.Context, skill *model.Skill) error {
+	panic("unimplemented")
+}
+
+// GetSkills implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetSkills(ctx context.Context) ([]model.Skill, error) {
+	panic("unimplemented")
+}
+
+// GetSkill implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetSkill(ctx context.Context, id uint) (*model.Skill, error) {
+	panic("unimplemented")
+}
+
+// GetEmployeeSkills implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) GetEmployeeSkills(ctx context.Context, employeeID uint) ([]model.EmployeeSkill, error) {
+	panic("unimplemented")
+}
+
+// FindEmployeeSkills implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) FindEmployeeSkills(ctx context.Context, skillID uint, minProficiency int) ([]model.EmployeeSkill, error) {
+	panic("unimplemented")
+}
+
+// SaveEmployeeSkill implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveEmployeeSkill(ctx context.Context, employeeSkill *model.EmployeeSkill) error {
+	panic("unimplemented")
+}
+
+// DeleteEmployeeSkill implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) DeleteEmployeeSkill(ctx context.Context, employeeID uint, skillID uint) error {
+	panic("unimplemented")
+}
+
+// SaveEndorsement implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) SaveEndorsement(ctx context.Context, endorsement *model.Endorsement) error {
+	panic("unimplemented")
+}
+
+// DeleteEndorsement implements repository.EmployeeRepository.
+func (m *errorThrowingEmployeeRepository) DeleteEndorsement(ctx context.Context, employeeSkillID uint, endorserID uint) error {
 	panic("unimplemented")
 }
 
-func (m *errorThrowingEmployeeRepository) GetEmployees() ([]model.Employee, error) {
+func (m *errorThrowingEmployeeRepository) GetEmployees(ctx context.Context) ([]model.Employee, error) {
 	return nil, errors.New("An error occurred retrieving employees")
 }
 
-func (m *errorThrowingEmployeeRepository) GetEmployeeByEmail(email string) (model.Employee, error) {
+func (m *errorThrowingEmployeeRepository) GetEmployeeByEmail(ctx context.Context, email string) (model.Employee, error) {
 	return model.Employee{}, errors.New("An error occurred retrieving employee by email")
 }
 
 func TestMain(m *testing.M) {
+	auth.Configure(config.Default().Auth)
 	repository.RunTestsWithTestContainer(m)
 }
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/logging"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+type sessionView struct {
+	model.Session
+	Device  string
+	Current bool
+}
+
+func sessionsHandler(c *gin.Context) {
+	renderSessions(c, "sessions")
+}
+
+// revokeSessionHandler ends one of the user's other sessions. The
+// current session is ended by logging out.
+func revokeSessionHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("sessionId"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+	if uint(id) == auth.CurrentSessionID(c.Request) {
+		c.String(http.StatusBadRequest, "Log out to end the current session")
+		return
+	}
+	email, _ := auth.AuthenticatedUser(c.Request)
+	err = repository.DeleteSession(c.Request.Context(), email, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "Session not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error revoking session: %v", err)
+		return
+	}
+	renderSessions(c, "session-list")
+}
+
+// revokeOtherSessionsHandler logs the user out everywhere except the
+// browser making the request.
+func revokeOtherSessionsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	email, _ := auth.AuthenticatedUser(c.Request)
+	sessions, err := repository.GetSessions(ctx, email)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving sessions: %v", err)
+		return
+	}
+	current := auth.CurrentSessionID(c.Request)
+	for i := range sessions {
+		if sessions[i].ID == current {
+			continue
+		}
+		if err := repository.DeleteSession(ctx, email, sessions[i].ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.String(http.StatusInternalServerError, "Error revoking session: %v", err)
+			return
+		}
+	}
+	renderSessions(c, "session-list")
+}
+
+// revokeAllSessionsHandler lets an admin log the employee named by the
+// "email" form value out everywhere, for example when they leave.
+func revokeAllSessionsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	email := strings.ToLower(strings.TrimSpace(c.PostForm("email")))
+	if email == "" {
+		c.String(http.StatusBadRequest, "Email cannot be empty")
+		return
+	}
+	count, err := repository.DeleteSessions(ctx, email)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error revoking sessions: %v", err)
+		return
+	}
+	admin, _ := auth.AuthenticatedUser(c.Request)
+	logging.FromContext(ctx).WarnContext(ctx, "Employee logged out everywhere by an admin",
+		slog.String("admin", admin), slog.String("email", email), slog.Int64("sessions", count))
+	c.Redirect(http.StatusSeeOther, "/employee/"+url.PathEscape(email))
+}
+
+func renderSessions(c *gin.Context, templateName string) {
+	email, _ := auth.AuthenticatedUser(c.Request)
+	sessions, err := repository.GetSessions(c.Request.Context(), email)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving sessions: %v", err)
+		return
+	}
+	current := auth.CurrentSessionID(c.Request)
+	views := make([]sessionView, len(sessions))
+	for i := range sessions {
+		views[i] = sessionView{Session: sessions[i], Device: describeDevice(sessions[i].UserAgent), Current: sessions[i].ID == current}
+	}
+	renderTemplate(c, templateName, gin.H{"Sessions": views})
+}
+
+// describeDevice summarises a user agent as a browser and operating
+// system, such as "Firefox on Windows", for people to recognise.
+func describeDevice(userAgent string) string {
+	browsers := []struct{ marker, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	}
+	systems := []struct{ marker, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+	browser, system := "", ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.marker) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.marker) {
+			system = s.name
+			break
+		}
+	}
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	return "Unknown device"
+}
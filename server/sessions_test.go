@@ -0,0 +1,189 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// responseCode sends req through router and returns the status code.
+func responseCode(router *gin.Engine, req *http.Request) int {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestSessions_RevokeAnotherBrowser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "jane.doe@objectcomputing.com", Name: "Jane Doe"})
+	laptop := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/sessions", nil), "jane.doe@objectcomputing.com")
+	phone := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/jane.doe@objectcomputing.com", nil), "jane.doe@objectcomputing.com")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, laptop)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "This browser")
+
+	sessions, err := repository.GetSessions(t.Context(), "jane.doe@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	// Sessions last seen at the same time are listed newest first.
+	other := sessions[0]
+	revoke := withCookiesOf(httptest.NewRequest(http.MethodDelete, "/sessions/"+strconv.FormatUint(uint64(other.ID), 10), nil), laptop)
+	assert.Equal(t, http.StatusOK, responseCode(router, revoke))
+
+	assert.Equal(t, http.StatusUnauthorized, responseCode(router, phone), "A revoked session should be logged out")
+	assert.Equal(t, http.StatusOK, responseCode(router, withCookiesOf(httptest.NewRequest(http.MethodGet, "/sessions", nil), laptop)))
+}
+
+func TestSessions_AdminCanLogOutEveryone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "admin@objectcomputing.com", Name: "Admin", Admin: true})
+	saveEmployee(t, &model.Employee{Email: "leaver@objectcomputing.com", Name: "Leaver"})
+	leaver := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/leaver@objectcomputing.com", nil), "leaver@objectcomputing.com")
+	form := url.Values{"email": {"leaver@objectcomputing.com"}}
+
+	assert.Equal(t, http.StatusForbidden, responseCode(router, auth.AuthenticateRequestForTest(t, formRequest(http.MethodPost, "/admin/sessions/revoke", form), "leaver@objectcomputing.com")))
+	assert.Equal(t, http.StatusSeeOther, responseCode(router, auth.AuthenticateRequestForTest(t, formRequest(http.MethodPost, "/admin/sessions/revoke", form), "admin@objectcomputing.com")))
+
+	assert.Equal(t, http.StatusUnauthorized, responseCode(router, leaver))
+}
+
+func TestSessions_LogoutEndsSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "jane.doe@objectcomputing.com", Name: "Jane Doe"})
+	req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/auth/logout", nil), "jane.doe@objectcomputing.com")
+
+	responseCode(router, req)
+
+	sessions, err := repository.GetSessions(t.Context(), "jane.doe@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestSessions_DeactivationEndsSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "leaver@objectcomputing.com", Name: "Leaver"})
+	req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/leaver@objectcomputing.com", nil), "leaver@objectcomputing.com")
+
+	assert.NoError(t, repository.DeactivateEmployee(t.Context(), "leaver@objectcomputing.com"))
+
+	assert.Equal(t, http.StatusUnauthorized, responseCode(router, req))
+}
+
+func withCookiesOf(req *http.Request, from *http.Request) *http.Request {
+	for _, cookie := range from.Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestDescribeDevice(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		expected  string
+	}{
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36", "Chrome on macOS"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0", "Edge on Windows"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1", "Safari on iOS"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0", "Firefox on Linux"},
+		{"curl/8.7.1", "curl"},
+		{"", "Unknown device"},
+	}
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			assert.Equal(t, test.expected, describeDevice(test.userAgent))
+		})
+	}
+}
+
+// configureAuth applies change to the default authentication settings
+// for the rest of the test.
+func configureAuth(t *testing.T, change func(*config.Auth)) {
+	settings := config.Default().Auth
+	change(&settings)
+	auth.Configure(settings)
+	t.Cleanup(func() {
+		auth.Configure(config.Default().Auth)
+	})
+}
+
+// ageSession moves the times of email's only session into the past.
+func ageSession(t *testing.T, email string, created time.Duration, lastSeen time.Duration) {
+	sessions, err := repository.GetSessions(t.Context(), email)
+	assert.NoError(t, err)
+	session := sessions[0]
+	session.CreatedAt = time.Now().Add(-created)
+	session.LastSeenAt = time.Now().Add(-lastSeen)
+	assert.NoError(t, repository.SaveSession(t.Context(), &session))
+}
+
+func TestSessions_Revalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*testing.T)
+	}{
+		{"idle timeout", func(t *testing.T) {
+			ageSession(t, "jane.doe@objectcomputing.com", 25*time.Hour, 25*time.Hour)
+		}},
+		{"absolute timeout", func(t *testing.T) {
+			ageSession(t, "jane.doe@objectcomputing.com", 8*24*time.Hour, time.Second)
+		}},
+		{"domain no longer allowed", func(t *testing.T) {
+			configureAuth(t, func(settings *config.Auth) { settings.AllowedDomains = []string{"example.com"} })
+		}},
+		{"deactivated without ending sessions", func(t *testing.T) {
+			configureAuth(t, func(settings *config.Auth) { settings.StatusCacheTTL = 0 })
+			now := time.Now()
+			employee, _ := repository.GetEmployeeByEmail(t.Context(), "jane.doe@objectcomputing.com")
+			employee.DeactivatedAt = &now
+			assert.NoError(t, repository.SaveEmployee(t.Context(), employee))
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := createRouter()
+			saveEmployee(t, &model.Employee{Email: "jane.doe@objectcomputing.com", Name: "Jane Doe"})
+			req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/jane.doe@objectcomputing.com", nil), "jane.doe@objectcomputing.com")
+
+			test.change(t)
+
+			assert.Equal(t, http.StatusUnauthorized, responseCode(router, req))
+			sessions, err := repository.GetSessions(t.Context(), "jane.doe@objectcomputing.com")
+			assert.NoError(t, err)
+			assert.Empty(t, sessions, "A session which fails revalidation should be ended")
+		})
+	}
+}
+
+func TestSessions_StatusIsCachedBriefly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	configureAuth(t, func(settings *config.Auth) { settings.StatusCacheTTL = time.Hour })
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "jane.doe@objectcomputing.com", Name: "Jane Doe"})
+	req := auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/employee/jane.doe@objectcomputing.com", nil), "jane.doe@objectcomputing.com")
+	assert.Equal(t, http.StatusOK, responseCode(router, req))
+
+	now := time.Now()
+	employee, _ := repository.GetEmployeeByEmail(t.Context(), "jane.doe@objectcomputing.com")
+	employee.DeactivatedAt = &now
+	assert.NoError(t, repository.SaveEmployee(t.Context(), employee))
+
+	assert.Equal(t, http.StatusOK, responseCode(router, req), "The status should be read from the cache until it expires")
+}
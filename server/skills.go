@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// employeeSkillsHandler shows an employee's skills, which they may edit
+// and their colleagues may endorse.
+func employeeSkillsHandler(c *gin.Context) {
+	renderEmployeeSkills(c, http.StatusOK, c.Param("employeeEmail"), gin.H{})
+}
+
+// setSkillHandler records the authenticated user's "proficiency" and
+// "years" of experience with the catalog skill named by the "skill" form
+// value.
+func setSkillHandler(c *gin.Context) {
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
+	skill, err := repository.FindSkill(c.Request.Context(), c.PostForm("skill"))
+	if err != nil {
+		renderEmployeeSkills(c, http.StatusBadRequest, authenticatedUser, gin.H{"Error": "Choose a skill from the catalog."})
+		return
+	}
+	proficiency, err := strconv.Atoi(c.PostForm("proficiency"))
+	if err != nil {
+		renderEmployeeSkills(c, http.StatusBadRequest, authenticatedUser, gin.H{"Error": "Choose how proficient you are."})
+		return
+	}
+	years, err := strconv.Atoi(c.DefaultPostForm("years", "0"))
+	if err != nil {
+		renderEmployeeSkills(c, http.StatusBadRequest, authenticatedUser, gin.H{"Error": "Years of experience must be a whole number."})
+		return
+	}
+	if err := repository.SetEmployeeSkill(c.Request.Context(), authenticatedUser, skill.ID, proficiency, years); err != nil {
+		renderEmployeeSkills(c, http.StatusBadRequest, authenticatedUser, gin.H{"Error": "The skill could not be saved: " + err.Error()})
+		return
+	}
+	renderEmployeeSkills(c, http.StatusOK, authenticatedUser, gin.H{})
+}
+
+func removeSkillHandler(c *gin.Context) {
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
+	id, ok := skillIDFromPath(c)
+	if !ok {
+		return
+	}
+	if err := repository.RemoveEmployeeSkill(c.Request.Context(), authenticatedUser, id); err != nil {
+		c.String(http.StatusInternalServerError, "Error removing skill: %v", err)
+		return
+	}
+	renderEmployeeSkills(c, http.StatusOK, authenticatedUser, gin.H{})
+}
+
+// endorseHandler records the authenticated user vouching for a skill of
+// the employee whose profile they are viewing.
+func endorseHandler(c *gin.Context) {
+	changeEndorsement(c, repository.Endorse)
+}
+
+func withdrawEndorsementHandler(c *gin.Context) {
+	changeEndorsement(c, repository.WithdrawEndorsement)
+}
+
+func changeEndorsement(c *gin.Context, change func(ctx context.Context, endorserEmail string, email string, skillID uint) error) {
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
+	email := c.Param("employeeEmail")
+	id, ok := skillIDFromPath(c)
+	if !ok {
+		return
+	}
+	err := change(c.Request.Context(), authenticatedUser, email, id)
+	switch {
+	case errors.Is(err, repository.ErrSelfEndorsement):
+		c.String(http.StatusBadRequest, "You cannot endorse your own skills")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.String(http.StatusNotFound, "Skill not found")
+	case err != nil:
+		c.String(http.StatusInternalServerError, "Error changing endorsement: %v", err)
+	default:
+		renderEmployeeSkills(c, http.StatusOK, email, gin.H{})
+	}
+}
+
+// skillSearchHandler finds the people with the skill named by the
+// "skill" query value, or one of its synonyms, at the "level" query
+// value or above.
+func skillSearchHandler(c *gin.Context) {
+	data := gin.H{"Query": strings.TrimSpace(c.Query("skill")), "Level": model.MinProficiency}
+	if level, err := strconv.Atoi(c.Query("level")); err == nil && model.ProficiencyName(level) != "" {
+		data["Level"] = level
+	}
+	if data["Query"] != "" {
+		skill, err := repository.FindSkill(c.Request.Context(), data["Query"].(string))
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			data["Error"] = "No skill in the catalog goes by that name."
+		case err != nil:
+			c.String(http.StatusInternalServerError, "Error retrieving skills: %v", err)
+			return
+		default:
+			people, err := repository.FindPeopleWithSkill(c.Request.Context(), skill.ID, data["Level"].(int))
+			if err != nil {
+				c.String(http.StatusInternalServerError, "Error finding people: %v", err)
+				return
+			}
+			data["Skill"] = skill
+			data["People"] = people
+		}
+	}
+	renderSkills(c, "skill-search", http.StatusOK, data)
+}
+
+func skillsPageHandler(c *gin.Context) {
+	renderSkills(c, "skills", http.StatusOK, gin.H{})
+}
+
+// createSkillHandler adds the skill named by the "name" form value, with
+// the comma separated "synonyms", to the catalog.
+func createSkillHandler(c *gin.Context) {
+	skill := &model.Skill{}
+	if !readSkillForm(c, skill) {
+		return
+	}
+	if err := repository.SaveSkill(c.Request.Context(), skill); err != nil {
+		renderSkills(c, "skill-list", http.StatusBadRequest, gin.H{"Error": "The skill could not be saved. Is it already in the catalog?"})
+		return
+	}
+	renderSkills(c, "skill-list", http.StatusOK, gin.H{})
+}
+
+func updateSkillHandler(c *gin.Context) {
+	id, ok := skillIDFromPath(c)
+	if !ok {
+		return
+	}
+	skill, err := repository.GetSkill(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "Skill not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving skill: %v", err)
+		return
+	}
+	if !readSkillForm(c, skill) {
+		return
+	}
+	if err := repository.SaveSkill(c.Request.Context(), skill); err != nil {
+		renderSkills(c, "skill-list", http.StatusBadRequest, gin.H{"Error": "The skill could not be saved. Is it already in the catalog?"})
+		return
+	}
+	renderSkills(c, "skill-list", http.StatusOK, gin.H{})
+}
+
+// readSkillForm copies the skill's name and synonyms from the form,
+// refusing a name or synonym which is already the name or a synonym of
+// another skill.
+func readSkillForm(c *gin.Context, skill *model.Skill) bool {
+	name := strings.TrimSpace(c.PostForm("name"))
+	if name == "" {
+		renderSkills(c, "skill-list", http.StatusBadRequest, gin.H{"Error": "A skill needs a name."})
+		return false
+	}
+	synonyms := (&model.Skill{Synonyms: c.PostForm("synonyms")}).SynonymList()
+	catalog, err := repository.GetSkills(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving skills: %v", err)
+		return false
+	}
+	for _, alias := range append([]string{name}, synonyms...) {
+		for i := range catalog {
+			if catalog[i].ID != skill.ID && catalog[i].Matches(alias) {
+				renderSkills(c, "skill-list", http.StatusBadRequest, gin.H{"Error": alias + " is already in the catalog as " + catalog[i].Name + "."})
+				return false
+			}
+		}
+	}
+	skill.Name = name
+	skill.Synonyms = strings.Join(synonyms, ", ")
+	return true
+}
+
+func skillIDFromPath(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("skillId"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid skill ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// renderEmployeeSkills renders the employee-skills template for the
+// employee with email. The authenticated user may edit their own skills
+// and endorse other people's.
+func renderEmployeeSkills(c *gin.Context, status int, email string, data gin.H) {
+	employee, err := repository.GetEmployeeByEmail(c.Request.Context(), email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	skills, err := repository.GetEmployeeSkills(c.Request.Context(), email)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving skills: %v", err)
+		return
+	}
+	data["Employee"] = employee
+	data["Skills"] = skills
+	if viewer, err := authenticatedEmployee(c); err == nil {
+		data["ViewerID"] = viewer.ID
+		data["IsEditable"] = viewer.ID == employee.ID
+	}
+	renderSkills(c, "employee-skills", status, data)
+}
+
+// renderSkills renders a skills template with the catalog and the
+// proficiency levels added to data.
+func renderSkills(c *gin.Context, templateName string, status int, data gin.H) {
+	skills, err := repository.GetSkills(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving skills: %v", err)
+		return
+	}
+	data["Catalog"] = skills
+	data["Levels"] = model.ProficiencyLevels()
+	renderTemplateWithStatus(c, templateName, data, status)
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkills_RecordEndorseAndFind(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	expert := &model.Employee{Email: "skill.expert@objectcomputing.com", Name: "Skill Expert"}
+	colleague := &model.Employee{Email: "skill.colleague@objectcomputing.com", Name: "Skill Colleague"}
+	saveEmployee(t, expert)
+	saveEmployee(t, colleague)
+	kubernetes, err := repository.FindSkill(t.Context(), "Kubernetes")
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/skills", expert.Email, url.Values{"skill": {"k8s"}, "proficiency": {"5"}, "years": {"7"}}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), fmt.Sprintf(`id="employee-skill-%d"`, kubernetes.ID), "Skills should be chosen by their synonyms")
+	assert.Contains(t, recorder.Body.String(), "Expert (5/5)")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/skills", expert.Email, url.Values{"skill": {"Basket Weaving"}, "proficiency": {"5"}}))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Choose a skill from the catalog.")
+
+	endorsePath := fmt.Sprintf("/employee/%s/skills/%d/endorse", expert.Email, kubernetes.ID)
+	assert.Equal(t, http.StatusBadRequest, responseCode(router, htmxRequest(t, http.MethodPost, endorsePath, expert.Email, nil)), "Employees should not endorse themselves")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, endorsePath, colleague.Email, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "1 endorsement")
+	assert.Contains(t, recorder.Body.String(), "Withdraw Endorsement")
+	assert.NotContains(t, recorder.Body.String(), "Save Skill", "Only the employee should edit their skills")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/skills?skill=K8S&level=4", nil), colleague.Email))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `href="/employee/skill.expert@objectcomputing.com">Skill Expert</a>`)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/skills?skill=Basket+Weaving", nil), colleague.Email))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "No skill in the catalog goes by that name.")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodDelete, fmt.Sprintf("/skills/%d", kubernetes.ID), expert.Email, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "No skills recorded yet.")
+}
+
+func TestSkills_AdminsCurateTheCatalog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	admin := &model.Employee{Email: "skill.admin@objectcomputing.com", Name: "Skill Admin", Admin: true}
+	regular := &model.Employee{Email: "skill.regular@objectcomputing.com", Name: "Skill Regular"}
+	saveEmployee(t, admin)
+	saveEmployee(t, regular)
+
+	form := url.Values{"name": {"Rust"}, "synonyms": {"rustlang, , rs"}}
+	assert.Equal(t, http.StatusForbidden, responseCode(router, htmxRequest(t, http.MethodPost, "/admin/skills", regular.Email, form)))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/admin/skills", admin.Email, form))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	rust, err := repository.FindSkill(t.Context(), "rustlang")
+	assert.NoError(t, err)
+	assert.Equal(t, "rustlang, rs", rust.Synonyms)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/admin/skills", admin.Email, url.Values{"name": {"golang"}}))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "golang is already in the catalog as Go.")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, fmt.Sprintf("/admin/skills/%d", rust.ID), admin.Email, url.Values{"name": {"Rust"}, "synonyms": {"rustlang, k8s"}}))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "k8s is already in the catalog as Kubernetes.")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/admin/skills", admin.Email, url.Values{"name": {"Crab"}, "synonyms": {"RUST"}}))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "RUST is already in the catalog as Rust.")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, fmt.Sprintf("/admin/skills/%d", rust.ID), admin.Email, url.Values{"name": {"Rust"}, "synonyms": {"rustlang"}}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	_, err = repository.FindSkill(t.Context(), "rs")
+	assert.Error(t, err, "Removed synonyms should no longer find the skill")
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+func teamsHandler(c *gin.Context) {
+	renderTeams(c, "teams", http.StatusOK, gin.H{})
+}
+
+// createTeamHandler adds a team named by the "name" form value. The
+// "open" form value makes it one any employee may join.
+func createTeamHandler(c *gin.Context) {
+	name := strings.TrimSpace(c.PostForm("name"))
+	if name == "" {
+		renderTeams(c, "team-list", http.StatusBadRequest, gin.H{"Error": "A team needs a name."})
+		return
+	}
+	team := &model.Team{
+		Name:        name,
+		Description: strings.TrimSpace(c.PostForm("description")),
+		Open:        c.PostForm("open") != "",
+	}
+	if err := repository.SaveTeam(c.Request.Context(), team); err != nil {
+		renderTeams(c, "team-list", http.StatusBadRequest, gin.H{"Error": "The team could not be saved. Is there already a team with that name?"})
+		return
+	}
+	renderTeams(c, "team-list", http.StatusOK, gin.H{})
+}
+
+func teamHandler(c *gin.Context) {
+	team, ok := teamFromPath(c)
+	if !ok {
+		return
+	}
+	renderTeam(c, "team", http.StatusOK, team, gin.H{})
+}
+
+// joinTeamHandler adds the authenticated user to an open team as a
+// member.
+func joinTeamHandler(c *gin.Context) {
+	team, ok := teamFromPath(c)
+	if !ok {
+		return
+	}
+	if !team.Open {
+		c.String(http.StatusForbidden, "Only admins can add members to this team")
+		return
+	}
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
+	employee, err := repository.GetEmployeeByEmail(c.Request.Context(), authenticatedUser)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	if team.Membership(employee.ID) == nil {
+		if err := repository.AddTeamMember(c.Request.Context(), team.ID, authenticatedUser, model.TeamMember); err != nil {
+			c.String(http.StatusInternalServerError, "Error joining team: %v", err)
+			return
+		}
+	}
+	reloadTeam(c, team.ID, gin.H{})
+}
+
+// leaveTeamHandler takes the authenticated user off a team.
+func leaveTeamHandler(c *gin.Context) {
+	team, ok := teamFromPath(c)
+	if !ok {
+		return
+	}
+	authenticatedUser, _ := auth.AuthenticatedUser(c.Request)
+	if err := repository.RemoveTeamMember(c.Request.Context(), team.ID, authenticatedUser); err != nil {
+		c.String(http.StatusInternalServerError, "Error leaving team: %v", err)
+		return
+	}
+	reloadTeam(c, team.ID, gin.H{})
+}
+
+// addTeamMemberHandler puts the employee with the "email" form value on
+// a team in the "role" form value, or changes their role.
+func addTeamMemberHandler(c *gin.Context) {
+	team, ok := teamFromPath(c)
+	if !ok {
+		return
+	}
+	email := strings.TrimSpace(c.PostForm("email"))
+	role := c.DefaultPostForm("role", model.TeamMember)
+	err := repository.AddTeamMember(c.Request.Context(), team.ID, email, role)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		renderTeam(c, "team-members", http.StatusBadRequest, team, gin.H{"Error": "There is no employee with that email address."})
+		return
+	}
+	if err != nil {
+		renderTeam(c, "team-members", http.StatusBadRequest, team, gin.H{"Error": "The member could not be added: " + err.Error()})
+		return
+	}
+	reloadTeam(c, team.ID, gin.H{})
+}
+
+func removeTeamMemberHandler(c *gin.Context) {
+	team, ok := teamFromPath(c)
+	if !ok {
+		return
+	}
+	if err := repository.RemoveTeamMember(c.Request.Context(), team.ID, c.Param("employeeEmail")); err != nil {
+		c.String(http.StatusInternalServerError, "Error removing team member: %v", err)
+		return
+	}
+	reloadTeam(c, team.ID, gin.H{})
+}
+
+func teamFromPath(c *gin.Context) (*model.Team, bool) {
+	id, err := strconv.ParseUint(c.Param("teamId"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid team ID")
+		return nil, false
+	}
+	team, err := repository.GetTeam(c.Request.Context(), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "Team not found")
+		return nil, false
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving team: %v", err)
+		return nil, false
+	}
+	return team, true
+}
+
+// reloadTeam renders the team-members template for the team as it is
+// after a change to its members.
+func reloadTeam(c *gin.Context, id uint, data gin.H) {
+	team, err := repository.GetTeam(c.Request.Context(), id)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving team: %v", err)
+		return
+	}
+	renderTeam(c, "team-members", http.StatusOK, team, data)
+}
+
+// renderTeam renders a team template with the team, its members and
+// whether the authenticated user is one of them added to data.
+func renderTeam(c *gin.Context, templateName string, status int, team *model.Team, data gin.H) {
+	data["Team"] = team
+	data["Members"] = team.Members()
+	data["Roles"] = []string{model.TeamMember, model.TeamLead}
+	if employee, err := authenticatedEmployee(c); err == nil {
+		data["IsMember"] = team.Membership(employee.ID) != nil
+	}
+	renderTemplateWithStatus(c, templateName, data, status)
+}
+
+// renderTeams renders a teams template with every team added to data.
+func renderTeams(c *gin.Context, templateName string, status int, data gin.H) {
+	teams, err := repository.GetTeams(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving teams: %v", err)
+		return
+	}
+	data["Teams"] = teams
+	renderTemplateWithStatus(c, templateName, data, status)
+}
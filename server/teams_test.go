@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeams_AdminsManageMembers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	admin := &model.Employee{Email: "team.admin@objectcomputing.com", Name: "Team Admin", Admin: true}
+	regular := &model.Employee{Email: "team.regular@objectcomputing.com", Name: "Team Regular"}
+	saveEmployee(t, admin)
+	saveEmployee(t, regular)
+
+	form := url.Values{"name": {"Closed Team"}, "description": {"Admins only"}}
+	assert.Equal(t, http.StatusForbidden, responseCode(router, htmxRequest(t, http.MethodPost, "/teams", regular.Email, form)))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, "/teams", admin.Email, form))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Closed Team")
+	team := findTeam(t, "Closed Team")
+	t.Cleanup(func() {
+		repository.DeleteTeamForTest(t, team.ID)
+	})
+	teamPath := fmt.Sprintf("/teams/%d", team.ID)
+
+	assert.Equal(t, http.StatusForbidden, responseCode(router, htmxRequest(t, http.MethodPost, teamPath+"/join", regular.Email, nil)), "Closed teams cannot be joined")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, teamPath+"/members", admin.Email, url.Values{"email": {regular.Email}, "role": {model.TeamLead}}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), fmt.Sprintf(`id="team-member-%d"`, regular.ID))
+	assert.Contains(t, recorder.Body.String(), "Lead")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, teamPath+"/members", admin.Email, url.Values{"email": {"nobody@objectcomputing.com"}}))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "There is no employee with that email address.")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/?team="+fmt.Sprint(team.ID), nil), admin.Email))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Team Regular")
+	assert.NotContains(t, recorder.Body.String(), "/employee/team.admin@objectcomputing.com", "The directory should only list the team's members")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodDelete, teamPath+"/members/"+regular.Email, admin.Email, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "This team has no members yet.")
+}
+
+func TestTeams_JoiningOpenTeams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	joiner := &model.Employee{Email: "joiner@objectcomputing.com", Name: "Joiner"}
+	saveEmployee(t, joiner)
+	team := &model.Team{Name: "Open Team", Open: true}
+	assert.NoError(t, repository.SaveTeam(t.Context(), team))
+	t.Cleanup(func() {
+		repository.DeleteTeamForTest(t, team.ID)
+	})
+	teamPath := fmt.Sprintf("/teams/%d", team.ID)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, teamPath, nil), joiner.Email))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Join Team")
+	assert.NotContains(t, recorder.Body.String(), "Add Member", "Only admins should be offered to add members")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, teamPath+"/join", joiner.Email, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), fmt.Sprintf(`id="team-member-%d"`, joiner.ID))
+	assert.Contains(t, recorder.Body.String(), "Leave Team")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, htmxRequest(t, http.MethodPost, teamPath+"/leave", joiner.Email, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Join Team")
+
+	assert.Equal(t, http.StatusNotFound, responseCode(router, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodGet, "/teams/999999", nil), joiner.Email)))
+}
+
+func findTeam(t *testing.T, name string) *model.Team {
+	teams, err := repository.GetTeams(t.Context())
+	assert.NoError(t, err)
+	for i := range teams {
+		if teams[i].Name == name {
+			return &teams[i]
+		}
+	}
+	t.Fatalf("no team named %q", name)
+	return nil
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"gorm.io/gorm"
+)
+
+// tokensHandler renders the authenticated user's API tokens, which are
+// loaded into their profile page.
+func tokensHandler(c *gin.Context) {
+	renderTokens(c, http.StatusOK, gin.H{})
+}
+
+// createTokenHandler creates a token named by the "name" form value with
+// the "scope" form value as its scope. The secret is rendered once and
+// cannot be retrieved again.
+func createTokenHandler(c *gin.Context) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	secret, token, err := auth.NewAPIToken(c.Request.Context(), employee, c.PostForm("name"), c.PostForm("scope"))
+	if err != nil {
+		renderTokens(c, http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+	renderTokens(c, http.StatusOK, gin.H{"NewToken": token, "Secret": secret})
+}
+
+func revokeTokenHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("tokenId"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	err = repository.DeleteAPIToken(c.Request.Context(), employee.ID, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "Token not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error revoking token: %v", err)
+		return
+	}
+	renderTokens(c, http.StatusOK, gin.H{})
+}
+
+func authenticatedEmployee(c *gin.Context) (*model.Employee, error) {
+	email, err := auth.AuthenticatedUser(c.Request)
+	if err != nil {
+		return nil, err
+	}
+	return repository.GetEmployeeByEmail(c.Request.Context(), email)
+}
+
+// renderTokens renders the tokens template with the user's tokens added
+// to data.
+func renderTokens(c *gin.Context, status int, data gin.H) {
+	employee, err := authenticatedEmployee(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving employee: %v", err)
+		return
+	}
+	tokens, err := repository.GetAPITokens(c.Request.Context(), employee.ID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error retrieving tokens: %v", err)
+		return
+	}
+	data["Tokens"] = tokens
+	data["ReadScope"] = model.TokenScopeRead
+	data["ReadWriteScope"] = model.TokenScopeReadWrite
+	renderTemplateWithStatus(c, "tokens", data, status)
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffscottbrown/satchel/auth"
+	"github.com/jeffscottbrown/satchel/model"
+	"github.com/jeffscottbrown/satchel/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var secretPattern = regexp.MustCompile(`satchel_[A-Za-z0-9_-]+`)
+
+// createToken creates a token through the profile page and returns the
+// secret shown to the user.
+func createToken(t *testing.T, router *gin.Engine, email string, name string, scope string) string {
+	form := url.Values{"name": {name}, "scope": {scope}}
+	req := httptest.NewRequest(http.MethodPost, "/tokens", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, req, email))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return secretPattern.FindString(recorder.Body.String())
+}
+
+func addReflectionRequest(secret string) *http.Request {
+	form := url.Values{"new-reflection-name": {"Editor"}, "new-reflection-value": {"vim"}}
+	req := httptest.NewRequest(http.MethodPost, "/reflection", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+secret)
+	return req
+}
+
+func TestAPIToken_ScriptsReflections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "scripter@objectcomputing.com", Name: "Scripter"})
+
+	secret := createToken(t, router, "scripter@objectcomputing.com", "Reflection Script", model.TokenScopeReadWrite)
+	assert.NotEmpty(t, secret)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, addReflectionRequest(secret))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	employee, err := repository.GetEmployeeByEmail(t.Context(), "scripter@objectcomputing.com")
+	assert.NoError(t, err)
+	assert.Len(t, employee.Reflections, 1)
+	tokens, err := repository.GetAPITokens(t.Context(), employee.ID)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+	assert.NotNil(t, tokens[0].LastUsedAt, "Using a token should record when it was last used")
+	assert.NotEqual(t, secret, tokens[0].Hash, "Only a hash of the token should be stored")
+}
+
+func TestAPIToken_ReadOnlyTokensCannotMakeChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "reader@objectcomputing.com", Name: "Reader"})
+	secret := createToken(t, router, "reader@objectcomputing.com", "Dashboard", model.TokenScopeRead)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/employee/reader@objectcomputing.com", nil)
+	req.Header.Set("Authorization", "Bearer "+secret)
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, addReflectionRequest(secret))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestAPIToken_CannotManageTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "scripter@objectcomputing.com", Name: "Scripter"})
+	secret := createToken(t, router, "scripter@objectcomputing.com", "Script", model.TokenScopeReadWrite)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/tokens", nil)
+	req.Header.Set("Authorization", "Bearer "+secret)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestAPIToken_Revoke(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+	saveEmployee(t, &model.Employee{Email: "scripter@objectcomputing.com", Name: "Scripter"})
+	saveEmployee(t, &model.Employee{Email: "someone.else@objectcomputing.com", Name: "Someone Else"})
+	secret := createToken(t, router, "scripter@objectcomputing.com", "Script", model.TokenScopeReadWrite)
+	employee, _ := repository.GetEmployeeByEmail(t.Context(), "scripter@objectcomputing.com")
+	tokens, _ := repository.GetAPITokens(t.Context(), employee.ID)
+	path := "/tokens/" + strconv.FormatUint(uint64(tokens[0].ID), 10)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodDelete, path, nil), "someone.else@objectcomputing.com"))
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "Users should not be able to revoke tokens of others")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, auth.AuthenticateRequestForTest(t, httptest.NewRequest(http.MethodDelete, path, nil), "scripter@objectcomputing.com"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, addReflectionRequest(secret))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestAPIToken_UnknownToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := createRouter()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, addReflectionRequest("satchel_not-a-real-token"))
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), "Bearer")
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Setup installs the global TracerProvider and propagator described by
+// settings. The returned function flushes and stops the exporter and
+// should be called during shutdown. When the exporter is "none" spans
+// are still created, so trace IDs continue to appear in logs, but they
+// are not exported anywhere.
+func Setup(ctx context.Context, settings config.Tracing) (func(context.Context) error, error) {
+	exporter, closeOutput, err := newExporter(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(settings.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(settings.ServiceName))),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeOutput != nil {
+			if closeErr := closeOutput.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, settings config.Tracing) (sdktrace.SpanExporter, io.Closer, error) {
+	switch settings.Exporter {
+	case "", "none":
+		return nil, nil, nil
+	case "stdout":
+		if settings.File == "" {
+			exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+			return exporter, nil, err
+		}
+		file, err := os.OpenFile(settings.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		return exporter, file, err
+	case "otlp":
+		if settings.OTLPProtocol == "http/protobuf" {
+			var options []otlptracehttp.Option
+			if settings.OTLPEndpoint != "" {
+				options = append(options, otlptracehttp.WithEndpointURL(settings.OTLPEndpoint))
+			}
+			if settings.OTLPInsecure {
+				options = append(options, otlptracehttp.WithInsecure())
+			}
+			exporter, err := otlptracehttp.New(ctx, options...)
+			return exporter, nil, err
+		}
+		var options []otlptracegrpc.Option
+		if settings.OTLPEndpoint != "" {
+			options = append(options, otlptracegrpc.WithEndpointURL(settings.OTLPEndpoint))
+		}
+		if settings.OTLPInsecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, options...)
+		return exporter, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter %q", settings.Exporter)
+	}
+}
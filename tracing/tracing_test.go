@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeffscottbrown/satchel/config"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup_StdoutExporterWritesToFile(t *testing.T) {
+	settings := config.Default().Tracing
+	settings.Exporter = "stdout"
+	settings.File = filepath.Join(t.TempDir(), "traces.json")
+
+	shutdown, err := Setup(context.Background(), settings)
+	assert.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+
+	assert.NoError(t, shutdown(context.Background()))
+
+	contents, err := os.ReadFile(settings.File)
+	assert.NoError(t, err)
+	assert.Contains(t, string(contents), `"Name":"test-span"`)
+	assert.Contains(t, string(contents), `"Value":"satchel"`, "Spans should carry the service name")
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	settings := config.Default().Tracing
+	settings.Exporter = "carrier-pigeon"
+
+	_, err := Setup(context.Background(), settings)
+	assert.ErrorContains(t, err, `unknown tracing exporter "carrier-pigeon"`)
+}
+
+func TestLogHandler_AddsTraceIDs(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.Default().Tracing)
+	assert.NoError(t, err)
+	defer shutdown(context.Background())
+
+	var output bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewTextHandler(&output, nil))).With("component", "test")
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "logging")
+	logger.InfoContext(ctx, "inside span")
+	span.End()
+
+	assert.Contains(t, output.String(), "trace_id="+span.SpanContext().TraceID().String())
+	assert.Contains(t, output.String(), "span_id="+span.SpanContext().SpanID().String())
+	assert.Contains(t, output.String(), "component=test")
+
+	output.Reset()
+	logger.Info("outside span")
+	assert.NotContains(t, output.String(), "trace_id")
+}
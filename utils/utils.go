@@ -1,18 +1,13 @@
 package utils
 
 import (
-	"log/slog"
-	"os"
-
-	"github.com/jeffscottbrown/gogoogle/secrets"
+	"github.com/jeffscottbrown/satchel/secrets"
 )
 
+// RetrieveSecretValue returns the named secret from the first provider
+// in the configured chain which holds it, or an empty string when none
+// does. See secrets.ChainFromEnvironment for how the chain is configured.
 func RetrieveSecretValue(secretName string) string {
-	clientSecret, err := secrets.RetrieveSecret(secretName)
-	if err != nil {
-		slog.Warn("Falling back to OS environment variable", slog.String("secretName", secretName), slog.Any("error", err))
-		clientSecret = os.Getenv(secretName)
-	}
-
-	return clientSecret
+	value, _ := secrets.Default().Lookup(secretName)
+	return value
 }